@@ -0,0 +1,121 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// minHedgeSamples is the number of latencies an adaptive hedger must have recorded before it starts hedging.
+const minHedgeSamples = 10
+
+// AdaptiveHedger cuts the tail latency of an operation by starting additional parallel attempts, but only when the
+// current attempt takes longer than a percentile of the latencies of recent successful attempts. Unlike hedging after
+// a fixed delay, it adapts to the latency of the operation, so that only the slowest attempts are hedged, and the load
+// grows by a fraction instead of doubling.
+//
+// The first successful attempt wins, and the contexts of the others are cancelled. Until enough latencies were
+// recorded, no additional attempts are started.
+//
+// AdaptiveHedger is experimental and may change in backwards incompatible ways.
+// It is safe for concurrent use.
+type AdaptiveHedger struct {
+	percentile  float64
+	maxParallel int
+
+	mu        sync.Mutex
+	latencies durationRing // The last recorded latencies of successful attempts.
+}
+
+// NewAdaptiveHedger returns a new adaptive hedger that starts another attempt whenever the attempts in flight take
+// longer than the given percentile, between 0 and 1, of the given number of most recent latencies, until the given
+// maximum number of parallel attempts is reached.
+func NewAdaptiveHedger(percentile float64, maxParallel, numSamples int) *AdaptiveHedger {
+	if maxParallel < 1 {
+		maxParallel = 1
+	}
+	if numSamples < minHedgeSamples {
+		numSamples = minHedgeSamples
+	}
+	return &AdaptiveHedger{
+		percentile:  percentile,
+		maxParallel: maxParallel,
+		latencies:   newDurationRing(numSamples),
+	}
+}
+
+// HedgeDelay returns the time after which another attempt is started, and false if not enough latencies were recorded
+// to hedge yet.
+func (h *AdaptiveHedger) HedgeDelay() (time.Duration, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.latencies.len() < minHedgeSamples {
+		return 0, false
+	}
+	return h.latencies.percentile(h.percentile), true
+}
+
+// Do calls the given callback, and calls it again in parallel whenever the attempts in flight take longer than the
+// hedge delay, until one of them succeeds or the maximum number of parallel attempts is reached. Every attempt
+// receives a child of the given context, which is cancelled once Do returns.
+//
+// Do returns nil as soon as an attempt succeeds. If all started attempts fail, it returns their errors, joined using
+// errors.Join, without waiting for the hedge delay to start another one: failures are for a retrier to handle, e.g.
+// by calling Do in a retry loop. If the context is done, it returns the cause of the context.
+func (h *AdaptiveHedger) Do(ctx context.Context, cb func(ctx context.Context) error) error {
+	attemptCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		err     error
+		latency time.Duration
+	}
+	results := make(chan result, h.maxParallel) // Buffered, so that losing attempts don't block.
+	start := func() {
+		go func() {
+			startTime := time.Now()
+			err := cb(attemptCtx)
+			results <- result{err: err, latency: time.Since(startTime)}
+		}()
+	}
+
+	start()
+	numStarted := 1
+	var hedge <-chan time.Time
+	delay, ok := h.HedgeDelay()
+	if ok && h.maxParallel > 1 {
+		ticker := time.NewTicker(max(delay, 1))
+		defer ticker.Stop()
+		hedge = ticker.C
+	}
+	var errs []error
+	for {
+		select {
+		case <-hedge:
+			start()
+			numStarted++
+			if numStarted == h.maxParallel {
+				hedge = nil
+			}
+		case res := <-results:
+			if res.err == nil {
+				h.record(res.latency)
+				return nil
+			}
+			errs = append(errs, res.err)
+			if len(errs) == numStarted {
+				return errors.Join(errs...)
+			}
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+	}
+}
+
+// record records the latency of a successful attempt.
+func (h *AdaptiveHedger) record(latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.latencies.record(latency)
+}
@@ -0,0 +1,114 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_AdaptiveHedger(t *testing.T) {
+	Convey("*AdaptiveHedger", t, func() {
+		ctx := context.Background()
+		hedger := NewAdaptiveHedger(0.9, 3, 10)
+		warmUp := func(latency time.Duration) {
+			for i := 0; i < 10; i++ {
+				hedger.record(latency)
+			}
+		}
+
+		Convey("HedgeDelay() returns false until enough latencies were recorded", func() {
+			_, ok := hedger.HedgeDelay()
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("HedgeDelay() returns the percentile of the recorded latencies", func() {
+			for i := 1; i <= 10; i++ {
+				hedger.record(time.Duration(i) * time.Millisecond)
+			}
+			delay, ok := hedger.HedgeDelay()
+			So(ok, ShouldBeTrue)
+			So(delay, ShouldEqual, 9*time.Millisecond)
+
+			// Only the most recent latencies count.
+			warmUp(time.Second)
+			delay, _ = hedger.HedgeDelay()
+			So(delay, ShouldEqual, time.Second)
+		})
+
+		Convey("Do() does not hedge before enough latencies were recorded", func() {
+			var numCalled atomic.Int32
+			err := hedger.Do(ctx, func(ctx context.Context) error {
+				numCalled.Add(1)
+				time.Sleep(20 * time.Millisecond)
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(numCalled.Load(), ShouldEqual, 1)
+		})
+
+		Convey("Do() does not hedge attempts faster than the hedge delay", func() {
+			warmUp(time.Second)
+			var numCalled atomic.Int32
+			err := hedger.Do(ctx, func(ctx context.Context) error {
+				numCalled.Add(1)
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(numCalled.Load(), ShouldEqual, 1)
+		})
+
+		Convey("Do() hedges slow attempts, up to the maximum, and cancels the losers", func() {
+			warmUp(5 * time.Millisecond)
+			var numCalled atomic.Int32
+			var numCancelled atomic.Int32
+			err := hedger.Do(ctx, func(ctx context.Context) error {
+				if numCalled.Add(1) < 3 {
+					<-ctx.Done()
+					numCancelled.Add(1)
+					return ctx.Err()
+				}
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(numCalled.Load(), ShouldEqual, 3)
+			So(func() bool { // The losers are cancelled after Do returns.
+				deadline := time.Now().Add(time.Second)
+				for numCancelled.Load() < 2 && time.Now().Before(deadline) {
+					time.Sleep(time.Millisecond)
+				}
+				return numCancelled.Load() == 2
+			}(), ShouldBeTrue)
+		})
+
+		Convey("Do() returns the errors of all attempts if they all fail", func() {
+			warmUp(time.Millisecond)
+			errA, errB := errors.New("a"), errors.New("b")
+			var numCalled atomic.Int32
+			err := hedger.Do(ctx, func(ctx context.Context) error {
+				if numCalled.Add(1) == 1 {
+					time.Sleep(10 * time.Millisecond)
+					return errA
+				}
+				return errB
+			})
+			So(err, ShouldWrap, errA)
+			So(err, ShouldWrap, errB)
+		})
+
+		Convey("Do() returns the cause of the context if it is done", func() {
+			ctx, cancel := context.WithCancelCause(ctx)
+			cause := errors.New("cause")
+			cancel(cause)
+			err := hedger.Do(ctx, func(ctx context.Context) error {
+				<-ctx.Done()
+				time.Sleep(10 * time.Millisecond)
+				return nil
+			})
+			So(err, ShouldEqual, cause)
+		})
+	})
+}
@@ -0,0 +1,137 @@
+package retry
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// AdaptiveBackOffRetrier retries a given callback, backing off on failure like BackOffRetrier, but learns from past
+// outages how long the operation it retries usually takes to recover, and biases its initial delay towards that.
+// Dependencies that historically recover slowly will thus get longer initial delays.
+//
+// The recovery time of a retry loop is the time between its first failed attempt and the start of the attempt that
+// succeeded, and is only recorded if the loop eventually succeeds. The initial delay is the mean of the last recorded
+// recovery times, but never less than the configured initial delay.
+//
+// AdaptiveBackOffRetrier is experimental and may change in backwards incompatible ways.
+// It is safe for concurrent use.
+type AdaptiveBackOffRetrier struct {
+	initialDelay       time.Duration
+	backOffCoefficient float64
+	cfg                config
+
+	mu         sync.Mutex
+	recoveries durationRing // The last recorded recovery times.
+}
+
+// NewAdaptiveBackOffRetrier returns a new adaptive back off retrier that bases its initial delay on the given number
+// of most recent recovery times. It takes the same options as NewBackOffRetrier.
+func NewAdaptiveBackOffRetrier(initialDelay time.Duration, backOffCoefficient float64, numSamples int, opts ...Option) *AdaptiveBackOffRetrier {
+	return &AdaptiveBackOffRetrier{
+		initialDelay:       initialDelay,
+		backOffCoefficient: backOffCoefficient,
+		cfg:                newConfig(opts),
+		recoveries:         newDurationRing(numSamples),
+	}
+}
+
+// Retry retries the given callback at max the given number of times.
+// It stops as soon as a `nil` error is returned.
+func (r *AdaptiveBackOffRetrier) Retry(numTimes int, cb func() error) error {
+	return r.RetryCtx(context.Background(), numTimes, cb)
+}
+
+// RetryCtx retries the given callback at max the given number of times.
+// It stops as soon as a `nil` error is returned.
+func (r *AdaptiveBackOffRetrier) RetryCtx(ctx context.Context, numTimes int, cb func() error) error {
+	return r.retry(ctx, numTimes, false, func(func()) error {
+		return cb()
+	})
+}
+
+// RetryForever retries the given callback until a `nil` error is returned or the given context is done.
+func (r *AdaptiveBackOffRetrier) RetryForever(ctx context.Context, cb func() error) error {
+	return r.RetryCtx(ctx, Forever, cb)
+}
+
+// RetryWithStop retries the given callback at max the given number of times.
+// It stops only when `stop` is called.
+func (r *AdaptiveBackOffRetrier) RetryWithStop(numTimes int, cb func(stop func()) error) error {
+	return r.RetryWithStopCtx(context.Background(), numTimes, cb)
+}
+
+// RetryWithStopCtx retries the given callback at max the given number of times.
+// It stops only when `stop` is called.
+func (r *AdaptiveBackOffRetrier) RetryWithStopCtx(ctx context.Context, numTimes int, cb func(stop func()) error) error {
+	return r.retry(ctx, numTimes, true, cb)
+}
+
+// RetryWithAbort retries the given callback at max the given number of times.
+// It stops as soon as a `nil` error is returned, or when `abort` is called, in which case the error passed to `abort`
+// is returned.
+func (r *AdaptiveBackOffRetrier) RetryWithAbort(numTimes int, cb func(abort func(err error)) error) error {
+	return r.RetryWithAbortCtx(context.Background(), numTimes, cb)
+}
+
+// RetryWithAbortCtx retries the given callback at max the given number of times.
+// It stops as soon as a `nil` error is returned, or when `abort` is called, in which case the error passed to `abort`
+// is returned.
+func (r *AdaptiveBackOffRetrier) RetryWithAbortCtx(ctx context.Context, numTimes int, cb func(abort func(err error)) error) error {
+	return r.retry(ctx, numTimes, false, withAbort(cb))
+}
+
+// retry retries the given callback at max the given number of times, recording the recovery time if it recovers.
+func (r *AdaptiveBackOffRetrier) retry(ctx context.Context, numTimes int, withStop bool, cb func(stop func()) error) error {
+	clock := r.cfg.getClock()
+	initialDelay := r.InitialDelay()
+	strategy := BackoffFunc(func(_ int, prev time.Duration) time.Duration {
+		delay := exponentialDelay(initialDelay, r.backOffCoefficient, prev)
+		if r.cfg.maxDelay > 0 && delay > r.cfg.maxDelay {
+			return r.cfg.maxDelay
+		}
+		return delay
+	})
+	var firstFailure, recovered time.Time
+	err := retryLoop(ctx, &r.cfg, numTimes, withStop, strategy, func(stop func()) error {
+		start := clock.Now()
+		err := cb(stop)
+		switch {
+		case err != nil && firstFailure.IsZero():
+			firstFailure = clock.Now()
+		case err == nil && !firstFailure.IsZero() && recovered.IsZero():
+			recovered = start
+		}
+		return err
+	})
+	if err == nil && !recovered.IsZero() {
+		r.record(recovered.Sub(firstFailure))
+	}
+	return err
+}
+
+// InitialDelay returns the initial delay that the next retry loop will use.
+func (r *AdaptiveBackOffRetrier) InitialDelay() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return max(r.recoveries.mean(), r.initialDelay)
+}
+
+// record records the given recovery time.
+func (r *AdaptiveBackOffRetrier) record(recovery time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recoveries.record(recovery)
+}
+
+// Stats returns the cumulative statistics of the retrier since it was created or its statistics were last reset.
+// It is safe for concurrent use.
+func (r *AdaptiveBackOffRetrier) Stats() RetrierStats {
+	return r.cfg.stats.get()
+}
+
+// ResetStats resets the statistics of the retrier to zero, except for the number of retry loops that are currently
+// retrying.
+func (r *AdaptiveBackOffRetrier) ResetStats() {
+	r.cfg.stats.reset()
+}
@@ -0,0 +1,128 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_AdaptiveBackOffRetrier_RetryCtx(t *testing.T) {
+	Convey("*AdaptiveBackOffRetrier.RetryCtx()", t, func() {
+		retrier := NewAdaptiveBackOffRetrier(time.Millisecond, 2, 2)
+		var numCalled int
+
+		Convey("If nothing was recorded yet, uses the configured initial delay", func() {
+			So(retrier.InitialDelay(), ShouldEqual, time.Millisecond)
+		})
+
+		Convey("If nil is returned right away, does not record a recovery", func() {
+			err := retrier.RetryCtx(context.Background(), 10, func() error {
+				numCalled++
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(numCalled, ShouldEqual, 1)
+			So(retrier.InitialDelay(), ShouldEqual, time.Millisecond)
+		})
+
+		Convey("If the operation recovers, biases the initial delay towards the recovery time", func() {
+			err := retrier.RetryCtx(context.Background(), 10, func() error {
+				numCalled++
+				if numCalled == 3 {
+					return nil
+				}
+				time.Sleep(5 * time.Millisecond)
+				return errors.New("foo")
+			})
+			So(err, ShouldBeNil)
+			So(numCalled, ShouldEqual, 3)
+			So(retrier.InitialDelay(), ShouldBeGreaterThan, 5*time.Millisecond)
+		})
+
+		Convey("Measures the recovery time until the start of the attempt that succeeded", func() {
+			retrier := NewAdaptiveBackOffRetrier(10*time.Millisecond, 2, 2)
+			err := retrier.RetryCtx(context.Background(), 10, func() error {
+				numCalled++
+				if numCalled == 1 {
+					return errors.New("foo")
+				}
+				time.Sleep(50 * time.Millisecond)
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(retrier.recoveries.len(), ShouldEqual, 1)
+			So(retrier.recoveries.mean(), ShouldBeBetween, 5*time.Millisecond, 50*time.Millisecond)
+		})
+
+		Convey("If the operation does not recover, does not record a recovery", func() {
+			err := retrier.RetryCtx(context.Background(), 2, func() error {
+				numCalled++
+				time.Sleep(5 * time.Millisecond)
+				return errors.New("foo")
+			})
+			So(err, ShouldNotBeNil)
+			So(retrier.InitialDelay(), ShouldEqual, time.Millisecond)
+		})
+
+		Convey("Applies the given options, and keeps statistics", func() {
+			var delays []time.Duration
+			retrier := NewAdaptiveBackOffRetrier(time.Millisecond, 10, 2, WithMaxDelay(2*time.Millisecond), WithOnRetry(func(event RetryEvent) {
+				delays = append(delays, event.NextDelay)
+			}))
+			err := retrier.RetryCtx(context.Background(), 2, func() error {
+				numCalled++
+				return errors.New("foo")
+			})
+			So(err, ShouldNotBeNil)
+			So(delays, ShouldResemble, []time.Duration{time.Millisecond, 2 * time.Millisecond})
+			So(retrier.Stats().Attempts, ShouldEqual, 3)
+			So(retrier.Stats().Failures, ShouldEqual, 1)
+			retrier.ResetStats()
+			So(retrier.Stats(), ShouldResemble, RetrierStats{})
+		})
+
+		Convey("Only takes the most recent recovery times into account", func() {
+			retrier.record(time.Second)
+			retrier.record(time.Second)
+			So(retrier.InitialDelay(), ShouldEqual, time.Second)
+			retrier.record(0)
+			So(retrier.InitialDelay(), ShouldEqual, time.Second/2)
+			retrier.record(0)
+			So(retrier.InitialDelay(), ShouldEqual, time.Millisecond) // Never less than the configured initial delay.
+		})
+	})
+}
+
+func Test_AdaptiveBackOffRetrier_RetryWithAbort(t *testing.T) {
+	Convey("*AdaptiveBackOffRetrier.RetryWithAbort()", t, func() {
+		retrier := NewAdaptiveBackOffRetrier(0, 2, 2)
+		expectedErr := errors.New("foo")
+		var numCalled int
+		err := retrier.RetryWithAbort(5, func(abort func(err error)) error {
+			numCalled++
+			abort(expectedErr)
+			return nil
+		})
+		So(err, ShouldEqual, expectedErr)
+		So(numCalled, ShouldEqual, 1)
+	})
+}
+
+func Test_AdaptiveBackOffRetrier_RetryForever(t *testing.T) {
+	Convey("*AdaptiveBackOffRetrier.RetryForever()", t, func() {
+		retrier := NewAdaptiveBackOffRetrier(0, 2, 2)
+		var numCalled int
+		err := retrier.RetryForever(context.Background(), func() error {
+			numCalled++
+			if numCalled < 5 {
+				return errors.New("foo")
+			}
+			return nil
+		})
+		So(err, ShouldBeNil)
+		So(numCalled, ShouldEqual, 5)
+	})
+}
@@ -0,0 +1,44 @@
+package retry
+
+import (
+	"context"
+	"sync"
+)
+
+// RetryAll retries every one of the given functions independently according to the given policy, running at max the
+// given number of them at once, and returns the error of every function, in the order the functions were given in,
+// which is nil for the functions that succeeded. A concurrency below 1 runs all functions at once. Every attempt
+// receives a context carrying the attempt, which can be retrieved using AttemptFromContext.
+//
+// If the context is done, the functions that weren't started yet are not started, and their error is the cause of
+// the context.
+func RetryAll(ctx context.Context, policy Policy, fns []func(ctx context.Context) error, concurrency int) []error {
+	if concurrency < 1 {
+		concurrency = max(len(fns), 1)
+	}
+	errs := make([]error, len(fns))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, fn := range fns {
+		if ctx.Err() == nil {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			for j := i; j < len(fns); j++ {
+				errs[j] = context.Cause(ctx)
+			}
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = policy.Do(ctx, fn)
+		}()
+	}
+	wg.Wait()
+	return errs
+}
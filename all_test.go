@@ -0,0 +1,85 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRetryAll(t *testing.T) {
+	Convey("RetryAll()", t, func() {
+		policy := NewPolicy().InitialDelay(0).MaxAttempts(3).Build()
+		expectedErr := errors.New("foo")
+
+		Convey("Retries every function independently and returns the error of every function", func() {
+			var numA, numB, numC atomic.Int32
+			errs := RetryAll(context.Background(), policy, []func(ctx context.Context) error{
+				func(ctx context.Context) error {
+					numA.Add(1)
+					return nil
+				},
+				func(ctx context.Context) error {
+					numB.Add(1)
+					return expectedErr
+				},
+				func(ctx context.Context) error {
+					if numC.Add(1) < 3 {
+						return expectedErr
+					}
+					return nil
+				},
+			}, 2)
+			So(errs, ShouldHaveLength, 3)
+			So(errs[0], ShouldBeNil)
+			So(errs[1], ShouldWrap, ErrMaxRetriesExceeded)
+			So(errs[1], ShouldWrap, expectedErr)
+			So(errs[2], ShouldBeNil)
+			So([]int32{numA.Load(), numB.Load(), numC.Load()}, ShouldResemble, []int32{1, 3, 3})
+		})
+
+		Convey("Runs at max the given number of functions at once", func() {
+			var running, maxRunning atomic.Int32
+			fns := make([]func(ctx context.Context) error, 10)
+			for i := range fns {
+				fns[i] = func(ctx context.Context) error {
+					n := running.Add(1)
+					defer running.Add(-1)
+					for {
+						prev := maxRunning.Load()
+						if n <= prev || maxRunning.CompareAndSwap(prev, n) {
+							break
+						}
+					}
+					time.Sleep(time.Millisecond)
+					return nil
+				}
+			}
+			errs := RetryAll(context.Background(), policy, fns, 3)
+			So(errs, ShouldResemble, make([]error, 10))
+			So(maxRunning.Load(), ShouldBeBetweenOrEqual, 1, 3)
+		})
+
+		Convey("Does not start functions once the context is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			var numCalled atomic.Int32
+			fn := func(ctx context.Context) error {
+				numCalled.Add(1)
+				cancel()
+				return nil
+			}
+			errs := RetryAll(ctx, policy, []func(ctx context.Context) error{fn, fn, fn}, 1)
+			So(errs[0], ShouldBeNil)
+			So(errs[1], ShouldEqual, context.Canceled)
+			So(errs[2], ShouldEqual, context.Canceled)
+			So(numCalled.Load(), ShouldEqual, 1)
+		})
+
+		Convey("Without functions, returns no errors", func() {
+			So(RetryAll(context.Background(), policy, nil, 0), ShouldBeEmpty)
+		})
+	})
+}
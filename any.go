@@ -0,0 +1,88 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrAllAlternativesFailed is returned by RetryAny when all alternatives failed.
+var ErrAllAlternativesFailed = errors.New("all alternatives failed")
+
+// WithConcurrentAlternatives makes RetryAny run the alternatives according to the policy concurrently, instead of one
+// after the other. It has no effect on retriers.
+func WithConcurrentAlternatives() Option {
+	return func(cfg *config) {
+		cfg.concurrentAlternatives = true
+	}
+}
+
+// RetryAny retries every one of the given alternatives according to the given policy, one after the other, until one
+// of them succeeds. If the policy uses WithConcurrentAlternatives, the alternatives run concurrently instead: as soon
+// as one succeeds, the contexts of the others are cancelled, and RetryAny returns once they returned. Every attempt
+// receives a context carrying the attempt, which can be retrieved using AttemptFromContext.
+//
+// It returns nil as soon as an alternative succeeds. If all alternatives fail, it returns an error matching
+// ErrAllAlternativesFailed and the errors of all alternatives. If the context is done, it returns the cause of the
+// context.
+func RetryAny(ctx context.Context, policy Policy, fns ...func(ctx context.Context) error) error {
+	if newConfig(policy.opts).concurrentAlternatives {
+		return retryAnyConcurrently(ctx, policy, fns)
+	}
+	i, errs, err := tryInOrder(ctx, len(fns), func(i int) error {
+		return policy.Do(ctx, fns[i])
+	}, nil)
+	if err != nil || i >= 0 {
+		return err
+	}
+	return allAlternativesFailed(errs)
+}
+
+// retryAnyConcurrently is RetryAny for alternatives that run concurrently.
+func retryAnyConcurrently(ctx context.Context, policy Policy, fns []func(ctx context.Context) error) error {
+	var wg sync.WaitGroup
+	defer wg.Wait() // Deferred before cancel, so that it runs after it: no alternative outlives RetryAny.
+	altCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		index int
+		err   error
+	}
+	results := make(chan result, len(fns)) // Buffered, so that losing alternatives don't block.
+	for i, fn := range fns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- result{index: i, err: policy.Do(altCtx, fn)}
+		}()
+	}
+	errs := make([]error, len(fns))
+	for range fns {
+		select {
+		case res := <-results:
+			if res.err == nil {
+				return nil
+			}
+			errs[res.index] = res.err
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+	}
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+	return allAlternativesFailed(errs)
+}
+
+// allAlternativesFailed returns the error for alternatives that failed with the given errors, in order.
+func allAlternativesFailed(errs []error) error {
+	if len(errs) == 0 {
+		return ErrAllAlternativesFailed
+	}
+	for i, err := range errs {
+		errs[i] = fmt.Errorf("alternative %d: %w", i, err)
+	}
+	return fmt.Errorf("%w: %w", ErrAllAlternativesFailed, errors.Join(errs...))
+}
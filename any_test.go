@@ -0,0 +1,96 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRetryAny(t *testing.T) {
+	Convey("RetryAny()", t, func() {
+		policy := NewPolicy().InitialDelay(0).MaxAttempts(2).Build()
+		errA, errB := errors.New("a"), errors.New("b")
+		var numA, numB, numC atomic.Int32
+		failingA := func(ctx context.Context) error {
+			numA.Add(1)
+			return errA
+		}
+		failingB := func(ctx context.Context) error {
+			numB.Add(1)
+			return errB
+		}
+		succeeding := func(ctx context.Context) error {
+			numC.Add(1)
+			return nil
+		}
+
+		Convey("Retries the alternatives one after the other until one succeeds", func() {
+			So(RetryAny(context.Background(), policy, failingA, succeeding, failingB), ShouldBeNil)
+			So([]int32{numA.Load(), numC.Load(), numB.Load()}, ShouldResemble, []int32{2, 1, 0})
+		})
+
+		Convey("Returns an error matching ErrAllAlternativesFailed and all errors if all alternatives fail", func() {
+			err := RetryAny(context.Background(), policy, failingA, failingB)
+			So(err, ShouldWrap, ErrAllAlternativesFailed)
+			So(err, ShouldWrap, errA)
+			So(err, ShouldWrap, errB)
+			So(err.Error(), ShouldContainSubstring, "alternative 1: ")
+		})
+
+		Convey("Without alternatives, returns ErrAllAlternativesFailed", func() {
+			So(RetryAny(context.Background(), policy), ShouldEqual, ErrAllAlternativesFailed)
+		})
+
+		Convey("Returns the cause of the context if it is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancelling := func(ctx context.Context) error {
+				cancel()
+				return errA
+			}
+			So(RetryAny(ctx, policy, cancelling, succeeding), ShouldEqual, context.Canceled)
+			So(numC.Load(), ShouldEqual, 0)
+		})
+
+		Convey("With WithConcurrentAlternatives()", func() {
+			policy := policy.With(WithConcurrentAlternatives())
+
+			Convey("Runs the alternatives concurrently and cancels the others once one succeeds", func() {
+				started := make(chan struct{})
+				cancelled := make(chan error, 1)
+				blocking := func(ctx context.Context) error {
+					close(started)
+					<-ctx.Done()
+					cancelled <- ctx.Err()
+					return ctx.Err()
+				}
+				succeedingAfterStart := func(ctx context.Context) error {
+					<-started
+					return nil
+				}
+				So(RetryAny(context.Background(), policy, blocking, succeedingAfterStart), ShouldBeNil)
+				select {
+				case err := <-cancelled:
+					So(err, ShouldEqual, context.Canceled)
+				case <-time.After(time.Second):
+					So("blocking was not cancelled", ShouldBeEmpty)
+				}
+			})
+
+			Convey("Returns the errors in the order of the alternatives if all fail", func() {
+				err := RetryAny(context.Background(), policy, failingA, failingB)
+				So(err, ShouldWrap, ErrAllAlternativesFailed)
+				So(err.Error(), ShouldEqual, "all alternatives failed: alternative 0: max retries exceeded: a\nalternative 1: max retries exceeded: b")
+			})
+
+			Convey("Returns the cause of the context if it is done", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				So(RetryAny(ctx, policy, failingA, failingB), ShouldEqual, context.Canceled)
+			})
+		})
+	})
+}
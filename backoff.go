@@ -0,0 +1,48 @@
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+// Backoff keeps track of the delays of a backoff strategy, for retry loops that are written by hand, like reconnect
+// loops. It is safe for concurrent use.
+type Backoff struct {
+	strategy BackoffStrategy
+
+	mu    sync.Mutex
+	retry int
+	prev  time.Duration
+}
+
+// NewBackoff returns a new backoff that returns the delays of the given strategy.
+func NewBackoff(strategy BackoffStrategy) *Backoff {
+	return &Backoff{strategy: strategy}
+}
+
+// Next returns the delay before the next retry.
+func (b *Backoff) Next() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.retry++
+	b.prev = b.strategy.Delay(b.retry, b.prev)
+	return b.prev
+}
+
+// Reset resets the backoff, so that the next delay is that of the first retry again. Call it after a period of
+// sustained success, so that a later blip doesn't start at the last, possibly huge, delay.
+func (b *Backoff) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.retry = 0
+	b.prev = 0
+}
+
+// WithAutoReset makes the retrier reset its backoff when an attempt fails after the attempts have been succeeding for
+// at least the given duration, so that the delays start from the initial delay again. This is useful for long-lived
+// loops using RetryWithStop, like reconnect loops, in which successful attempts don't end the loop.
+func WithAutoReset(after time.Duration) Option {
+	return func(cfg *config) {
+		cfg.resetAfter = after
+	}
+}
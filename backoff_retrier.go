@@ -2,6 +2,7 @@ package retry
 
 import (
 	"context"
+	"fmt"
 	"math"
 	"time"
 )
@@ -18,106 +19,144 @@ type RetrierCtx func(ctx context.Context, numTimes int, cb func(stop func()) err
 type BackOffRetrier struct {
 	initialDelay       time.Duration
 	backOffCoefficient float64
+	cfg                config
 }
 
 // NewBackOffRetrier returns a new back off retrier.
-func NewBackOffRetrier(initialDelay time.Duration, backOffCoefficient float64) *BackOffRetrier {
-	return &BackOffRetrier{initialDelay: initialDelay, backOffCoefficient: backOffCoefficient}
+// It does not validate its settings. Use Validate or MustNewBackOffRetrier for that.
+func NewBackOffRetrier(initialDelay time.Duration, backOffCoefficient float64, opts ...Option) *BackOffRetrier {
+	return &BackOffRetrier{initialDelay: initialDelay, backOffCoefficient: backOffCoefficient, cfg: newConfig(opts)}
+}
+
+// MustNewBackOffRetrier returns a new back off retrier, like NewBackOffRetrier, but panics if its settings are invalid.
+func MustNewBackOffRetrier(initialDelay time.Duration, backOffCoefficient float64, opts ...Option) *BackOffRetrier {
+	r := NewBackOffRetrier(initialDelay, backOffCoefficient, opts...)
+	if err := r.Validate(); err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Validate returns an error matching ErrInvalidConfig if the settings of the retrier would produce a broken schedule:
+// a negative initial or maximum delay, a coefficient less than 1 (which makes the delays shrink instead of grow), an
+// unknown jitter mode or a randomization factor outside of [0, 1].
+func (r *BackOffRetrier) Validate() error {
+	switch {
+	case r.initialDelay < 0:
+		return fmt.Errorf("%w: initial delay must not be negative, got %s", ErrInvalidConfig, r.initialDelay)
+	case !(r.backOffCoefficient >= 1) || math.IsInf(r.backOffCoefficient, 1):
+		return fmt.Errorf("%w: back off coefficient must be a finite number of at least 1, got %v", ErrInvalidConfig, r.backOffCoefficient)
+	case r.cfg.maxDelay < 0:
+		return fmt.Errorf("%w: max delay must not be negative, got %s", ErrInvalidConfig, r.cfg.maxDelay)
+	case !r.cfg.jitter.valid():
+		return fmt.Errorf("%w: unknown jitter mode %d", ErrInvalidConfig, r.cfg.jitter)
+	case !(r.cfg.randomizationFactor >= 0 && r.cfg.randomizationFactor <= 1):
+		return fmt.Errorf("%w: randomization factor must be between 0 and 1, got %v", ErrInvalidConfig, r.cfg.randomizationFactor)
+	}
+	return nil
+}
+
+// InitialDelay returns the delay before the first retry.
+func (r *BackOffRetrier) InitialDelay() time.Duration {
+	return r.initialDelay
+}
+
+// BackOffCoefficient returns the coefficient the delay is multiplied by after every retry.
+func (r *BackOffRetrier) BackOffCoefficient() float64 {
+	return r.backOffCoefficient
+}
+
+// MaxDelay returns the maximum delay before a retry, or 0 if there is no maximum.
+func (r *BackOffRetrier) MaxDelay() time.Duration {
+	return r.cfg.maxDelay
+}
+
+// Jitter returns the jitter mode.
+func (r *BackOffRetrier) Jitter() JitterMode {
+	return r.cfg.jitter
+}
+
+// Strategy returns the backoff strategy of the retrier, capped at its max delay. Jitter is not included. Use it with
+// NewBackoff to back off the same way in retry loops that are written by hand.
+func (r *BackOffRetrier) Strategy() BackoffStrategy {
+	return BackoffFunc(func(retry int, prev time.Duration) time.Duration {
+		delay := r.nextDelay(retry, prev)
+		if r.cfg.maxDelay > 0 && delay > r.cfg.maxDelay {
+			return r.cfg.maxDelay
+		}
+		return delay
+	})
+}
+
+// RandomizationFactor returns the factor by which delays are randomized in either direction.
+func (r *BackOffRetrier) RandomizationFactor() float64 {
+	return r.cfg.randomizationFactor
 }
 
 // Retry retries the given callback at max the given number of times.
 // It stops as soon as a `nil` error is returned.
 func (r *BackOffRetrier) Retry(numTimes int, cb func() error) error {
-	var sleepDur time.Duration
-	delay := r.initialDelay
-	return Retry(numTimes, func() error {
-		if sleepDur > 0 {
-			time.Sleep(sleepDur)
-		}
-		err := cb()
-		if err != nil {
-			if sleepDur == 0 {
-				// First attempt. Don't multiply yet.
-				sleepDur = delay
-			} else {
-				sleepDur = time.Duration(math.Round(r.backOffCoefficient * float64(delay)))
-			}
-		}
-		return err
-	})
+	return r.RetryCtx(context.Background(), numTimes, cb)
 }
 
 // RetryCtx retries the given callback at max the given number of times.
 // It stops as soon as a `nil` error is returned.
 func (r *BackOffRetrier) RetryCtx(ctx context.Context, numTimes int, cb func() error) error {
-	var sleepDur time.Duration
-	delay := r.initialDelay
-	return Retry(numTimes, func() error {
-		err := ctx.Err()
-		if err != nil {
-			return err
-		}
-		if sleepDur > 0 {
-			time.Sleep(sleepDur)
-		}
-		err = cb()
-		if err != nil {
-			if sleepDur == 0 {
-				// First attempt. Don't multiply yet.
-				sleepDur = delay
-			} else {
-				sleepDur = time.Duration(math.Round(r.backOffCoefficient * float64(delay)))
-			}
-		}
-		return err
+	return r.retry(ctx, numTimes, false, func(func()) error {
+		return cb()
 	})
 }
 
+// RetryForever retries the given callback until a `nil` error is returned or the given context is done.
+func (r *BackOffRetrier) RetryForever(ctx context.Context, cb func() error) error {
+	return r.RetryCtx(ctx, Forever, cb)
+}
+
 // RetryWithStop retries the given callback at max the given number of times.
 // It stops only when `stop` is called.
 func (r *BackOffRetrier) RetryWithStop(numTimes int, cb func(stop func()) error) error {
-	var sleepDur time.Duration
-	delay := r.initialDelay
-	return RetryWithStop(numTimes, func(stop func()) error {
-		if sleepDur > 0 {
-			time.Sleep(sleepDur)
-		}
-		err := cb(stop)
-		if err != nil {
-			if sleepDur == 0 {
-				// First attempt. Don't multiply yet.
-				sleepDur = delay
-			} else {
-				sleepDur = time.Duration(math.Round(r.backOffCoefficient * float64(delay)))
-			}
-		}
-		return err
-	})
+	return r.RetryWithStopCtx(context.Background(), numTimes, cb)
 }
 
 // RetryWithStopCtx retries the given callback at max the given number of times.
 // It stops only when `stop` is called.
 func (r *BackOffRetrier) RetryWithStopCtx(ctx context.Context, numTimes int, cb func(stop func()) error) error {
-	var sleepDur time.Duration
-	delay := r.initialDelay
-	return RetryWithStop(numTimes, func(stop func()) error {
-		err := ctx.Err()
-		if err != nil {
-			stop()
-			return err
-		}
-		if sleepDur > 0 {
-			time.Sleep(sleepDur)
-		}
-		err = cb(stop)
-		if err != nil {
-			if sleepDur == 0 {
-				// First attempt. Don't multiply yet.
-				sleepDur = delay
-			} else {
-				sleepDur = time.Duration(math.Round(r.backOffCoefficient * float64(delay)))
-			}
-		}
-		return err
-	})
+	return r.retry(ctx, numTimes, true, cb)
+}
+
+// RetryWithAbort retries the given callback at max the given number of times.
+// It stops as soon as a `nil` error is returned, or when `abort` is called, in which case the error passed to `abort`
+// is returned.
+func (r *BackOffRetrier) RetryWithAbort(numTimes int, cb func(abort func(err error)) error) error {
+	return r.RetryWithAbortCtx(context.Background(), numTimes, cb)
+}
+
+// RetryWithAbortCtx retries the given callback at max the given number of times.
+// It stops as soon as a `nil` error is returned, or when `abort` is called, in which case the error passed to `abort`
+// is returned.
+func (r *BackOffRetrier) RetryWithAbortCtx(ctx context.Context, numTimes int, cb func(abort func(err error)) error) error {
+	return r.retry(ctx, numTimes, false, withAbort(cb))
+}
+
+// retry retries the given callback at max the given number of times, backing off after every failed attempt.
+// If withStop is true, it stops only when `stop` is called. Otherwise, it stops as soon as a `nil` error is returned.
+func (r *BackOffRetrier) retry(ctx context.Context, numTimes int, withStop bool, cb func(stop func()) error) error {
+	return retryLoop(ctx, &r.cfg, numTimes, withStop, r.Strategy(), cb)
+}
+
+// nextDelay returns the delay to sleep for before the next retry, given the previous delay.
+func (r *BackOffRetrier) nextDelay(_ int, prev time.Duration) time.Duration {
+	return exponentialDelay(r.initialDelay, r.backOffCoefficient, prev)
+}
+
+// Stats returns the cumulative statistics of the retrier since it was created or its statistics were last reset.
+// It is safe for concurrent use.
+func (r *BackOffRetrier) Stats() RetrierStats {
+	return r.cfg.stats.get()
+}
+
+// ResetStats resets the statistics of the retrier to zero, except for the number of retry loops that are currently
+// retrying.
+func (r *BackOffRetrier) ResetStats() {
+	r.cfg.stats.reset()
 }
@@ -66,7 +66,8 @@ func Test_BackoffRetrier_Retry(t *testing.T) {
 				return expectedErr
 			})
 			So(err, ShouldNotBeNil)
-			So(err, ShouldEqual, expectedErr)
+			So(err, ShouldWrap, expectedErr)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
 			So(numCalled, ShouldEqual, 4)
 
 			timeElapsed := time.Now().Sub(startTime)
@@ -120,7 +121,8 @@ func Test_BackoffRetrier_RetryCtx(t *testing.T) {
 				return expectedErr
 			})
 			So(err, ShouldNotBeNil)
-			So(err, ShouldEqual, expectedErr)
+			So(err, ShouldWrap, expectedErr)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
 			So(numCalled, ShouldEqual, 2)
 
 			timeElapsed := time.Now().Sub(startTime)
@@ -179,7 +181,8 @@ func Test_BackoffRetrier_RetryWithStop(t *testing.T) {
 				return expectedErr
 			})
 			So(err, ShouldNotBeNil)
-			So(err, ShouldEqual, expectedErr)
+			So(err, ShouldWrap, expectedErr)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
 			So(numCalled, ShouldEqual, 3)
 
 			timeElapsed := time.Now().Sub(startTime)
@@ -235,7 +238,8 @@ func Test_BackoffRetrier_RetryWithStop(t *testing.T) {
 				return expectedErr
 			})
 			So(err, ShouldNotBeNil)
-			So(err, ShouldEqual, expectedErr)
+			So(err, ShouldWrap, expectedErr)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
 			So(numCalled, ShouldEqual, 2)
 
 			timeElapsed := time.Now().Sub(startTime)
@@ -276,7 +280,8 @@ func Test_BackoffRetrier_RetryWithStopCtx(t *testing.T) {
 				return expectedErr
 			})
 			So(err, ShouldNotBeNil)
-			So(err, ShouldEqual, expectedErr)
+			So(err, ShouldWrap, expectedErr)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
 			So(numCalled, ShouldEqual, 3)
 
 			timeElapsed := time.Now().Sub(startTime)
@@ -332,7 +337,8 @@ func Test_BackoffRetrier_RetryWithStopCtx(t *testing.T) {
 				return expectedErr
 			})
 			So(err, ShouldNotBeNil)
-			So(err, ShouldEqual, expectedErr)
+			So(err, ShouldWrap, expectedErr)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
 			So(numCalled, ShouldEqual, 2)
 
 			timeElapsed := time.Now().Sub(startTime)
@@ -359,3 +365,116 @@ func Test_BackoffRetrier_RetryWithStopCtx(t *testing.T) {
 		})
 	})
 }
+
+func Test_BackoffRetrier_RetryWithAbort(t *testing.T) {
+	Convey("*BackoffRetrier.RetryWithAbort()", t, func() {
+		clock := &fakeClock{now: time.Now()}
+		retrier := NewBackOffRetrier(time.Second, 2, WithClock(clock))
+		var numCalled int
+
+		Convey("Backs off until abort is called, then returns the given error", func() {
+			expectedErr := errors.New("foo")
+			err := retrier.RetryWithAbort(10, func(abort func(err error)) error {
+				numCalled++
+				if numCalled == 3 {
+					abort(expectedErr)
+				}
+				return errors.New("bar")
+			})
+			So(err, ShouldEqual, expectedErr)
+			So(numCalled, ShouldEqual, 3)
+			So(clock.sleeps, ShouldResemble, []time.Duration{time.Second, 2 * time.Second})
+		})
+	})
+}
+
+func Test_BackoffRetrier_Validate(t *testing.T) {
+	Convey("*BackoffRetrier.Validate()", t, func() {
+		Convey("Returns nil for valid settings", func() {
+			So(NewBackOffRetrier(0, 1).Validate(), ShouldBeNil)
+			So(NewBackOffRetrier(time.Second, 2, WithMaxDelay(time.Minute), WithJitter(NoJitter)).Validate(), ShouldBeNil)
+		})
+
+		Convey("Returns ErrInvalidConfig for settings that produce a broken schedule", func() {
+			for _, retrier := range []*BackOffRetrier{
+				NewBackOffRetrier(-time.Second, 2),
+				NewBackOffRetrier(time.Second, 0),
+				NewBackOffRetrier(time.Second, -2),
+				NewBackOffRetrier(time.Second, 0.5),
+				NewBackOffRetrier(time.Second, math.NaN()),
+				NewBackOffRetrier(time.Second, math.Inf(1)),
+				NewBackOffRetrier(time.Second, 2, WithMaxDelay(-time.Second)),
+				NewBackOffRetrier(time.Second, 2, WithJitter(JitterMode(-1))),
+			} {
+				So(retrier.Validate(), ShouldWrap, ErrInvalidConfig)
+			}
+		})
+	})
+}
+
+func Test_MustNewBackOffRetrier(t *testing.T) {
+	Convey("MustNewBackOffRetrier()", t, func() {
+		Convey("Returns the retrier if its settings are valid", func() {
+			retrier := MustNewBackOffRetrier(time.Second, 2, WithMaxDelay(time.Minute))
+			So(retrier.InitialDelay(), ShouldEqual, time.Second)
+			So(retrier.BackOffCoefficient(), ShouldEqual, 2)
+			So(retrier.MaxDelay(), ShouldEqual, time.Minute)
+			So(retrier.Jitter(), ShouldEqual, NoJitter)
+		})
+
+		Convey("Panics if its settings are invalid", func() {
+			So(func() {
+				MustNewBackOffRetrier(time.Second, 0)
+			}, ShouldPanic)
+		})
+	})
+}
+
+func Test_BackoffRetrier_nextDelay(t *testing.T) {
+	Convey("*BackoffRetrier.nextDelay()", t, func() {
+		retrier := NewBackOffRetrier(time.Second, 2, WithMaxDelay(time.Minute))
+
+		Convey("Grows by the coefficient", func() {
+			So(retrier.nextDelay(1, 0), ShouldEqual, time.Second)
+			So(retrier.nextDelay(2, time.Second), ShouldEqual, 2*time.Second)
+			So(retrier.nextDelay(3, 2*time.Second), ShouldEqual, 4*time.Second)
+		})
+
+		Convey("Saturates instead of overflowing", func() {
+			var delay time.Duration
+			for i := 1; i <= 100; i++ {
+				delay = retrier.nextDelay(i, delay)
+				So(delay, ShouldBeGreaterThan, 0)
+			}
+			So(delay, ShouldEqual, time.Duration(math.MaxInt64))
+		})
+	})
+}
+
+func Test_BackoffRetrier_MaxDelay(t *testing.T) {
+	Convey("A back off retrier with a max delay", t, func() {
+		Convey("Plateaus at the max delay", func() {
+			retrier := NewBackOffRetrier(time.Millisecond, 10, WithMaxDelay(5*time.Millisecond))
+			startTime := time.Now()
+			err := retrier.Retry(3, func() error {
+				return errors.New("foo")
+			})
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			// 1ms + 5ms + 5ms instead of 1ms + 10ms + 100ms.
+			So(time.Since(startTime), ShouldBeBetween, 11*time.Millisecond, 50*time.Millisecond)
+		})
+
+		Convey("Plateaus at the max delay however many retries are made", func() {
+			retrier := NewBackOffRetrier(time.Nanosecond, 2, WithMaxDelay(time.Microsecond))
+			var numCalled int
+			startTime := time.Now()
+			err := retrier.Retry(200, func() error {
+				numCalled++
+				return errors.New("foo")
+			})
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(numCalled, ShouldEqual, 201)
+			So(time.Since(startTime), ShouldBeLessThan, time.Second)
+		})
+	})
+}
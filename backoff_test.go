@@ -0,0 +1,74 @@
+package retry
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Backoff(t *testing.T) {
+	Convey("*Backoff", t, func() {
+		backoff := NewBackoff(NewBackOffRetrier(time.Second, 2, WithMaxDelay(3*time.Second)).Strategy())
+
+		Convey("Next() returns the delays of the strategy", func() {
+			So(backoff.Next(), ShouldEqual, time.Second)
+			So(backoff.Next(), ShouldEqual, 2*time.Second)
+			So(backoff.Next(), ShouldEqual, 3*time.Second)
+
+			Convey("Reset() starts from the first delay again", func() {
+				backoff.Reset()
+				So(backoff.Next(), ShouldEqual, time.Second)
+			})
+		})
+
+		Convey("Passes the retry number to the strategy", func() {
+			var retries []int
+			backoff := NewBackoff(BackoffFunc(func(retry int, _ time.Duration) time.Duration {
+				retries = append(retries, retry)
+				return 0
+			}))
+			backoff.Next()
+			backoff.Next()
+			backoff.Reset()
+			backoff.Next()
+			So(retries, ShouldResemble, []int{1, 2, 1})
+		})
+	})
+}
+
+func TestWithAutoReset(t *testing.T) {
+	Convey("WithAutoReset()", t, func() {
+		clock := &fakeClock{now: time.Now()}
+		errFoo := errors.New("foo")
+
+		run := func(opts ...Option) {
+			// Fails twice, succeeds for 3 minutes, then fails twice again.
+			outcomes := []error{errFoo, errFoo, nil, nil, nil, errFoo, errFoo, nil}
+			var numCalled int
+			retrier := NewBackOffRetrier(time.Second, 2, append(opts, WithClock(clock))...)
+			_ = retrier.RetryWithStop(len(outcomes)-1, func(stop func()) error {
+				err := outcomes[numCalled]
+				numCalled++
+				if numCalled == len(outcomes) {
+					stop()
+				}
+				if err == nil {
+					clock.now = clock.now.Add(time.Minute)
+				}
+				return err
+			})
+		}
+
+		Convey("Resets the backoff after a period of sustained success", func() {
+			run(WithAutoReset(2 * time.Minute))
+			So(clock.sleeps, ShouldResemble, []time.Duration{time.Second, 2 * time.Second, time.Second, 2 * time.Second})
+		})
+
+		Convey("Does not reset the backoff if the success was too short", func() {
+			run(WithAutoReset(time.Hour))
+			So(clock.sleeps, ShouldResemble, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second})
+		})
+	})
+}
@@ -0,0 +1,94 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// BatchItem describes the progress of an item of a batch.
+type BatchItem[T any] struct {
+	// Item is the item.
+	Item T
+	// Attempts is the number of attempts made for the item.
+	Attempts int
+	// Err is the error of the last attempt for the item, or nil if it succeeded.
+	Err error
+}
+
+// BatchError is the error returned by RetryEach when some items of a batch didn't succeed. It carries the progress of
+// every item, so that callers can persist partial progress, instead of treating the whole batch as failed.
+//
+// It unwraps to the error of the retryer, so errors.Is and errors.As match e.g. ErrMaxRetriesExceeded and the errors
+// of the last attempts of the failing items.
+type BatchError[T any] struct {
+	// Items contains the progress of every item, in the order the items were given in.
+	Items []BatchItem[T]
+
+	err error
+}
+
+// Error implements error.
+func (e *BatchError[T]) Error() string {
+	return fmt.Sprintf("%d of %d items failed: %s", len(e.Failed()), len(e.Items), e.err)
+}
+
+// Unwrap returns the error of the retryer.
+func (e *BatchError[T]) Unwrap() error {
+	return e.err
+}
+
+// Succeeded returns the items that succeeded, in order.
+func (e *BatchError[T]) Succeeded() []T {
+	var items []T
+	for _, item := range e.Items {
+		if item.Err == nil && item.Attempts > 0 {
+			items = append(items, item.Item)
+		}
+	}
+	return items
+}
+
+// Failed returns the items that are still failing, including the ones that were never attempted, in order.
+func (e *BatchError[T]) Failed() []T {
+	var items []T
+	for _, item := range e.Items {
+		if item.Err != nil || item.Attempts == 0 {
+			items = append(items, item.Item)
+		}
+	}
+	return items
+}
+
+// RetryEach calls the given callback for every item of a batch, and retries the items that failed at max the given
+// number of times using the given retryer. Every attempt of the retry loop only retries the items that are still
+// failing, in order, so items that succeeded are never repeated.
+//
+// It returns nil if all items succeeded. Otherwise, it returns a *BatchError carrying which items succeeded, which are
+// still failing and the number of attempts per item, wrapping the error of the retryer. The error of an attempt of the
+// retry loop is the errors of the items that failed in it, joined using errors.Join.
+func RetryEach[T any](ctx context.Context, r Retryer, numTimes int, items []T, cb func(ctx context.Context, item T) error) error {
+	progress := make([]BatchItem[T], len(items))
+	for i, item := range items {
+		progress[i].Item = item
+	}
+	err := r.RetryCtx(ctx, numTimes, func() error {
+		var errs []error
+		for i := range progress {
+			item := &progress[i]
+			if item.Attempts > 0 && item.Err == nil {
+				continue
+			}
+			item.Attempts++
+			item.Err = cb(ctx, item.Item)
+			if item.Err != nil {
+				errs = append(errs, item.Err)
+			}
+		}
+		return errors.Join(errs...)
+	})
+	if err == nil {
+		return nil
+	}
+	return &BatchError[T]{Items: progress, err: err}
+}
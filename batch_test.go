@@ -0,0 +1,70 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRetryEach(t *testing.T) {
+	Convey("RetryEach()", t, func() {
+		ctx := context.Background()
+		retrier := NewNoDelayRetrier()
+		items := []string{"a", "b", "c"}
+		expectedErr := errors.New("foo")
+		var calls []string
+
+		Convey("Only retries the items that are still failing", func() {
+			numFailures := map[string]int{"a": 0, "b": 2, "c": 1}
+			err := RetryEach(ctx, retrier, 5, items, func(_ context.Context, item string) error {
+				calls = append(calls, item)
+				if numFailures[item] > 0 {
+					numFailures[item]--
+					return expectedErr
+				}
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(calls, ShouldResemble, []string{"a", "b", "c", "b", "c", "b"})
+		})
+
+		Convey("Returns the progress of every item if some items keep failing", func() {
+			errB := errors.New("b failed")
+			err := RetryEach(ctx, retrier, 1, items, func(_ context.Context, item string) error {
+				if item == "b" {
+					return errB
+				}
+				return nil
+			})
+
+			var batchErr *BatchError[string]
+			So(errors.As(err, &batchErr), ShouldBeTrue)
+			So(batchErr.Items, ShouldResemble, []BatchItem[string]{
+				{Item: "a", Attempts: 1},
+				{Item: "b", Attempts: 2, Err: errB},
+				{Item: "c", Attempts: 1},
+			})
+			So(batchErr.Succeeded(), ShouldResemble, []string{"a", "c"})
+			So(batchErr.Failed(), ShouldResemble, []string{"b"})
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(err, ShouldWrap, errB)
+			So(err.Error(), ShouldEqual, "1 of 3 items failed: max retries exceeded: b failed")
+		})
+
+		Convey("Counts items that were never attempted as failing", func() {
+			ctx, cancel := context.WithCancel(ctx)
+			cancel()
+			err := RetryEach(ctx, retrier, 1, items, func(context.Context, string) error {
+				return nil
+			})
+
+			var batchErr *BatchError[string]
+			So(errors.As(err, &batchErr), ShouldBeTrue)
+			So(batchErr.Succeeded(), ShouldBeEmpty)
+			So(batchErr.Failed(), ShouldResemble, items)
+			So(err, ShouldWrap, context.Canceled)
+		})
+	})
+}
@@ -0,0 +1,230 @@
+// Package breaker provides a circuit breaker that wraps a retrier, so that repeated exhaustions of its retries trip
+// the breaker and subsequent calls fail fast instead of hammering a dependency that is down.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/minitauros/go-retry"
+)
+
+// ErrOpen is returned by calls that are rejected because the breaker is open.
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the state of a breaker.
+type State int
+
+const (
+	// Closed is the state in which calls are let through.
+	Closed State = iota
+	// Open is the state in which calls fail fast with ErrOpen, until the cool-down has passed.
+	Open
+	// HalfOpen is the state in which a limited number of trial calls are let through, to find out whether the
+	// dependency recovered.
+	HalfOpen
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Option configures a breaker.
+type Option func(*Breaker)
+
+// WithClock makes the breaker tell the time using the given clock, e.g. to test cool-downs in virtual time.
+func WithClock(clock retry.Clock) Option {
+	return func(b *Breaker) {
+		b.clock = clock
+	}
+}
+
+// WithHalfOpenCalls sets the number of trial calls the breaker lets through at once when half-open. Defaults to 1.
+func WithHalfOpenCalls(n int) Option {
+	return func(b *Breaker) {
+		b.halfOpenCalls = n
+	}
+}
+
+// WithIsFailure makes the breaker count the calls for which the given function returns true as failures, instead of
+// the calls whose retries were exhausted, i.e. that returned an error matching *retry.Error.
+func WithIsFailure(isFailure func(err error) bool) Option {
+	return func(b *Breaker) {
+		b.isFailure = isFailure
+	}
+}
+
+// WithOnStateChange makes the breaker call the given hook whenever its state changes, e.g. to log or alert on it.
+func WithOnStateChange(hook func(from, to State)) Option {
+	return func(b *Breaker) {
+		b.onStateChange = hook
+	}
+}
+
+// Breaker is a circuit breaker. It starts closed, and opens once the given number of calls failed in a row. While
+// open, calls fail fast with ErrOpen. After the cool-down, it becomes half-open and lets trial calls through: if one
+// succeeds, it closes again, and if one fails, it opens again. A Breaker is safe for concurrent use.
+type Breaker struct {
+	failureThreshold int
+	coolDown         time.Duration
+	halfOpenCalls    int
+	clock            retry.Clock
+	isFailure        func(err error) bool
+	onStateChange    func(from, to State)
+
+	mu          sync.Mutex
+	state       State
+	generation  int // Incremented on every state change, so that calls started in an earlier state are ignored.
+	numFailures int // The number of calls that failed in a row while closed.
+	numTrials   int // The number of trial calls in progress while half-open.
+	openedAt    time.Time
+}
+
+// New returns a new breaker that opens after the given number of calls failed in a row, and becomes half-open after
+// the given cool-down.
+func New(failureThreshold int, coolDown time.Duration, opts ...Option) *Breaker {
+	b := &Breaker{
+		failureThreshold: failureThreshold,
+		coolDown:         coolDown,
+		halfOpenCalls:    1,
+		isFailure:        isExhausted,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// State returns the current state of the breaker.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	from := b.state
+	b.coolDownPassed()
+	to := b.state
+	b.mu.Unlock()
+	b.notify(from, to)
+	return to
+}
+
+// Do calls the given callback, unless the breaker is open, in which case it returns ErrOpen right away. The outcome of
+// the call is recorded. Calls that end because the given context is done are not counted.
+func (b *Breaker) Do(ctx context.Context, cb func(ctx context.Context) error) error {
+	generation, err := b.allow()
+	if err != nil {
+		return err
+	}
+	err = cb(ctx)
+	b.record(generation, err, ctx.Err() != nil)
+	return err
+}
+
+// Wrap returns a retrier that retries using the given retrier, guarded by the breaker.
+func (b *Breaker) Wrap(r retry.Retryer) *Retryer {
+	return &Retryer{breaker: b, retryer: r}
+}
+
+// allow returns the generation of the state in which a call is allowed, or ErrOpen if it isn't.
+func (b *Breaker) allow() (int, error) {
+	b.mu.Lock()
+	from := b.state
+	b.coolDownPassed()
+	to := b.state
+	generation := b.generation
+	var err error
+	switch b.state {
+	case Open:
+		err = ErrOpen
+	case HalfOpen:
+		if b.numTrials >= b.halfOpenCalls {
+			err = ErrOpen
+		} else {
+			b.numTrials++
+		}
+	}
+	b.mu.Unlock()
+	b.notify(from, to)
+	return generation, err
+}
+
+// record records the outcome of a call that was allowed in the given generation. If ignored is true, the call
+// neither counts as a success nor as a failure.
+func (b *Breaker) record(generation int, err error, ignored bool) {
+	b.mu.Lock()
+	from := b.state
+	if generation == b.generation {
+		failed := err != nil && b.isFailure(err)
+		switch {
+		case b.state == HalfOpen && ignored:
+			b.numTrials--
+		case ignored:
+		case b.state == HalfOpen && failed:
+			b.open()
+		case b.state == HalfOpen:
+			b.setState(Closed)
+		case failed:
+			b.numFailures++
+			if b.numFailures >= b.failureThreshold {
+				b.open()
+			}
+		default:
+			b.numFailures = 0
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+	b.notify(from, to)
+}
+
+// coolDownPassed makes an open breaker half-open if the cool-down has passed. It must be called with the lock held.
+func (b *Breaker) coolDownPassed() {
+	if b.state == Open && b.now().Sub(b.openedAt) >= b.coolDown {
+		b.setState(HalfOpen)
+	}
+}
+
+// open opens the breaker. It must be called with the lock held.
+func (b *Breaker) open() {
+	b.setState(Open)
+	b.openedAt = b.now()
+}
+
+// setState changes the state of the breaker. It must be called with the lock held.
+func (b *Breaker) setState(state State) {
+	b.state = state
+	b.generation++
+	b.numFailures = 0
+	b.numTrials = 0
+}
+
+// now returns the current time of the clock of the breaker, or of the real clock if it has none.
+func (b *Breaker) now() time.Time {
+	if b.clock == nil {
+		return time.Now()
+	}
+	return b.clock.Now()
+}
+
+// notify calls the state change hook if the state changed.
+func (b *Breaker) notify(from, to State) {
+	if from != to && b.onStateChange != nil {
+		b.onStateChange(from, to)
+	}
+}
+
+// isExhausted reports whether the retries of a call were exhausted.
+func isExhausted(err error) bool {
+	var retryErr *retry.Error
+	return errors.As(err, &retryErr)
+}
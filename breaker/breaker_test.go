@@ -0,0 +1,141 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/minitauros/go-retry"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fakeClock is a clock of which the time only moves when it is advanced.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Sleep(context.Context, time.Duration) error {
+	return nil
+}
+
+func TestState(t *testing.T) {
+	Convey("State.String()", t, func() {
+		So(Closed.String(), ShouldEqual, "closed")
+		So(Open.String(), ShouldEqual, "open")
+		So(HalfOpen.String(), ShouldEqual, "half-open")
+		So(State(10).String(), ShouldEqual, "unknown")
+	})
+}
+
+func TestBreaker(t *testing.T) {
+	Convey("Breaker", t, func() {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		var changes []string
+		b := New(2, time.Minute, WithClock(clock), WithOnStateChange(func(from, to State) {
+			changes = append(changes, from.String()+" -> "+to.String())
+		}))
+		exhausted := &retry.Error{Errors: []error{errors.New("foo")}}
+		var numCalled int
+		call := func(err error) error {
+			return b.Do(context.Background(), func(ctx context.Context) error {
+				numCalled++
+				return err
+			})
+		}
+
+		Convey("Starts closed", func() {
+			So(b.State(), ShouldEqual, Closed)
+		})
+
+		Convey("Opens after the given number of exhaustions in a row", func() {
+			So(call(exhausted), ShouldEqual, exhausted)
+			So(call(nil), ShouldBeNil)
+			So(call(exhausted), ShouldEqual, exhausted)
+			So(b.State(), ShouldEqual, Closed)
+			So(call(exhausted), ShouldEqual, exhausted)
+			So(b.State(), ShouldEqual, Open)
+			So(changes, ShouldResemble, []string{"closed -> open"})
+
+			Convey("Fails fast while open", func() {
+				So(call(nil), ShouldEqual, ErrOpen)
+				So(numCalled, ShouldEqual, 4)
+			})
+
+			Convey("Becomes half-open after the cool-down", func() {
+				clock.now = clock.now.Add(time.Minute)
+				So(b.State(), ShouldEqual, HalfOpen)
+
+				Convey("Closes again if a trial call succeeds", func() {
+					So(call(nil), ShouldBeNil)
+					So(b.State(), ShouldEqual, Closed)
+					So(changes, ShouldResemble, []string{"closed -> open", "open -> half-open", "half-open -> closed"})
+				})
+
+				Convey("Opens again if a trial call fails", func() {
+					So(call(exhausted), ShouldEqual, exhausted)
+					So(b.State(), ShouldEqual, Open)
+					So(call(nil), ShouldEqual, ErrOpen)
+				})
+			})
+		})
+
+		Convey("Does not count other errors as failures", func() {
+			otherErr := errors.New("not found")
+			So(call(exhausted), ShouldEqual, exhausted)
+			So(call(otherErr), ShouldEqual, otherErr)
+			So(call(exhausted), ShouldEqual, exhausted)
+			So(b.State(), ShouldEqual, Closed)
+		})
+
+		Convey("Does not count calls that end because the context is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			for i := 0; i < 3; i++ {
+				_ = b.Do(ctx, func(ctx context.Context) error {
+					return &retry.Error{}
+				})
+			}
+			So(b.State(), ShouldEqual, Closed)
+		})
+
+		Convey("Lets only the given number of trial calls through at once when half-open", func() {
+			b := New(1, time.Minute, WithClock(clock), WithHalfOpenCalls(2))
+			_ = b.Do(context.Background(), func(ctx context.Context) error { return exhausted })
+			clock.now = clock.now.Add(time.Minute)
+
+			var errs []error
+			err := b.Do(context.Background(), func(ctx context.Context) error {
+				errs = append(errs, b.Do(ctx, func(ctx context.Context) error {
+					errs = append(errs, b.Do(ctx, func(ctx context.Context) error { return nil }))
+					return nil
+				}))
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(errs, ShouldResemble, []error{ErrOpen, nil})
+		})
+
+		Convey("Ignores the outcome of calls started in an earlier state", func() {
+			b := New(1, time.Minute, WithClock(clock))
+			err := b.Do(context.Background(), func(ctx context.Context) error {
+				_ = b.Do(ctx, func(ctx context.Context) error { return exhausted })
+				clock.now = clock.now.Add(time.Minute)
+				So(b.State(), ShouldEqual, HalfOpen)
+				return exhausted
+			})
+			So(err, ShouldEqual, exhausted)
+			So(b.State(), ShouldEqual, HalfOpen)
+		})
+
+		Convey("WithIsFailure() decides which errors count as failures", func() {
+			b := New(1, time.Minute, WithIsFailure(func(err error) bool { return err != nil }))
+			_ = b.Do(context.Background(), func(ctx context.Context) error { return errors.New("foo") })
+			So(b.State(), ShouldEqual, Open)
+		})
+	})
+}
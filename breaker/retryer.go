@@ -0,0 +1,50 @@
+package breaker
+
+import (
+	"context"
+
+	"github.com/minitauros/go-retry"
+)
+
+var _ retry.Retryer = (*Retryer)(nil)
+
+// Retryer is a retrier guarded by a breaker. Every retry loop counts as a single call of the breaker: the breaker
+// trips once the given number of retry loops in a row exhausted their retries, after which retry loops fail fast with
+// ErrOpen without making any attempt.
+type Retryer struct {
+	breaker *Breaker
+	retryer retry.Retryer
+}
+
+// Retry retries the given callback at max the given number of times, unless the breaker is open.
+// It stops as soon as a `nil` error is returned.
+func (r *Retryer) Retry(numTimes int, cb func() error) error {
+	return r.RetryCtx(context.Background(), numTimes, cb)
+}
+
+// RetryCtx retries the given callback at max the given number of times, unless the breaker is open.
+// It stops as soon as a `nil` error is returned.
+func (r *Retryer) RetryCtx(ctx context.Context, numTimes int, cb func() error) error {
+	return r.breaker.Do(ctx, func(ctx context.Context) error {
+		return r.retryer.RetryCtx(ctx, numTimes, cb)
+	})
+}
+
+// RetryWithStop retries the given callback at max the given number of times, unless the breaker is open.
+// It stops only when `stop` is called.
+func (r *Retryer) RetryWithStop(numTimes int, cb func(stop func()) error) error {
+	return r.RetryWithStopCtx(context.Background(), numTimes, cb)
+}
+
+// RetryWithStopCtx retries the given callback at max the given number of times, unless the breaker is open.
+// It stops only when `stop` is called.
+func (r *Retryer) RetryWithStopCtx(ctx context.Context, numTimes int, cb func(stop func()) error) error {
+	return r.breaker.Do(ctx, func(ctx context.Context) error {
+		return r.retryer.RetryWithStopCtx(ctx, numTimes, cb)
+	})
+}
+
+// Breaker returns the breaker guarding the retrier.
+func (r *Retryer) Breaker() *Breaker {
+	return r.breaker
+}
@@ -0,0 +1,64 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/minitauros/go-retry"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRetryer(t *testing.T) {
+	Convey("Retryer", t, func() {
+		r := New(2, time.Hour).Wrap(retry.NewNoDelayRetrier())
+		expectedErr := errors.New("foo")
+		var numCalled int
+		failing := func() error {
+			numCalled++
+			return expectedErr
+		}
+
+		Convey("Retries using the wrapped retrier", func() {
+			err := r.Retry(2, failing)
+			So(err, ShouldWrap, retry.ErrMaxRetriesExceeded)
+			So(numCalled, ShouldEqual, 3)
+		})
+
+		Convey("Fails fast once its retries were exhausted repeatedly", func() {
+			_ = r.Retry(2, failing)
+			_ = r.RetryCtx(context.Background(), 2, failing)
+			So(r.Breaker().State(), ShouldEqual, Open)
+
+			So(r.Retry(2, failing), ShouldEqual, ErrOpen)
+			So(r.RetryWithStop(2, func(stop func()) error {
+				numCalled++
+				return nil
+			}), ShouldEqual, ErrOpen)
+			So(numCalled, ShouldEqual, 6)
+		})
+
+		Convey("RetryWithStop() stops only when stop is called", func() {
+			err := r.RetryWithStop(5, func(stop func()) error {
+				numCalled++
+				if numCalled == 2 {
+					stop()
+				}
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(numCalled, ShouldEqual, 2)
+		})
+
+		Convey("Does not count aborted retry loops as failures", func() {
+			for i := 0; i < 3; i++ {
+				_ = r.RetryWithStopCtx(context.Background(), 2, func(stop func()) error {
+					stop()
+					return expectedErr
+				})
+			}
+			So(r.Breaker().State(), ShouldEqual, Closed)
+		})
+	})
+}
@@ -0,0 +1,114 @@
+package retry
+
+import (
+	"context"
+	"sync"
+)
+
+// Budget is a token bucket that limits the ratio of retries to first attempts, like the retry throttling of gRPC.
+// Every first attempt deposits a fraction of a token, and every retry withdraws a whole one. When the budget is
+// depleted, retriers using it skip their retries and return the last error right away, so that an outage of a
+// dependency doesn't multiply the load on it by the number of retries.
+//
+// It is safe for concurrent use, and is meant to be shared by all retriers of the same dependency.
+type Budget struct {
+	ratio     float64
+	maxTokens float64
+	store     BudgetStore
+	key       string
+
+	mu     sync.Mutex
+	tokens float64
+}
+
+// NewBudget returns a new budget allowing the given ratio of retries to first attempts, e.g. 0.1 for 1 retry per 10
+// first attempts. The budget holds at max the given number of tokens, and starts full, which allows maxTokens retries
+// in a burst.
+func NewBudget(ratio, maxTokens float64) *Budget {
+	return &Budget{
+		ratio:     ratio,
+		maxTokens: maxTokens,
+		tokens:    maxTokens,
+	}
+}
+
+// BudgetStore stores the tokens of budgets that are shared by the retriers of multiple processes, so that a fleet
+// shares a single retry budget per dependency. Implementations must be safe for concurrent use. Use an external store,
+// like Redis, to share budgets across processes.
+type BudgetStore interface {
+	// Deposit adds the given number of tokens to the budget with the given key, up to the given maximum, and returns
+	// the number of tokens in it. A budget that doesn't exist yet starts full.
+	Deposit(ctx context.Context, key string, tokens, maxTokens float64) (float64, error)
+	// Withdraw withdraws a token from the budget with the given key if it holds at least one, and returns the number
+	// of tokens left and whether a token was withdrawn. A budget that doesn't exist yet starts full.
+	Withdraw(ctx context.Context, key string, maxTokens float64) (float64, bool, error)
+}
+
+// NewSharedBudget returns a new budget like NewBudget, which keeps its tokens in the given store under the given key,
+// so that it's shared by all processes using the same store and key. While the store fails, the budget falls back to
+// tokens of its own, so that an outage of the store neither stops nor unleashes all retries.
+func NewSharedBudget(store BudgetStore, key string, ratio, maxTokens float64) *Budget {
+	b := NewBudget(ratio, maxTokens)
+	b.store = store
+	b.key = key
+	return b
+}
+
+// Tokens returns the number of tokens in the budget. Every whole token allows one retry. For a shared budget, it's
+// the number of tokens the store last reported.
+func (b *Budget) Tokens() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens
+}
+
+// deposit records a first attempt. It does nothing on a nil budget.
+func (b *Budget) deposit(ctx context.Context) {
+	if b == nil {
+		return
+	}
+	if b.store != nil {
+		if tokens, err := b.store.Deposit(ctx, b.key, b.ratio, b.maxTokens); err == nil {
+			b.setTokens(tokens)
+			return
+		}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.tokens+b.ratio, b.maxTokens)
+}
+
+// withdraw records a retry, and reports whether the budget allows it. It always does on a nil budget.
+func (b *Budget) withdraw(ctx context.Context) bool {
+	if b == nil {
+		return true
+	}
+	if b.store != nil {
+		if tokens, ok, err := b.store.Withdraw(ctx, b.key, b.maxTokens); err == nil {
+			b.setTokens(tokens)
+			return ok
+		}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// setTokens sets the number of tokens in the budget, as reported by its store.
+func (b *Budget) setTokens(tokens float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = tokens
+}
+
+// WithBudget makes the retrier deposit into the given budget for every first attempt, and withdraw from it for every
+// retry. When the budget is depleted, the retrier skips its retries and returns the last error as is.
+func WithBudget(budget *Budget) Option {
+	return func(cfg *config) {
+		cfg.retryBudget = budget
+	}
+}
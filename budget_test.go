@@ -0,0 +1,159 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBudget(t *testing.T) {
+	Convey("Budget", t, func() {
+		budget := NewBudget(0.5, 2)
+		expectedErr := errors.New("foo")
+		var numCalled int
+		failing := func() error {
+			numCalled++
+			return expectedErr
+		}
+
+		Convey("Starts full", func() {
+			So(budget.Tokens(), ShouldEqual, 2)
+		})
+
+		Convey("Allows retries while it has tokens", func() {
+			retrier := NewNoDelayRetrier(WithBudget(budget))
+			err := retrier.Retry(1, failing)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(numCalled, ShouldEqual, 2)
+			So(budget.Tokens(), ShouldEqual, 1)
+		})
+
+		Convey("Once depleted, skips retries and returns the last error as is", func() {
+			var reasons []Reason
+			retrier := NewNoDelayRetrier(WithBudget(budget), WithOnError(func(event ErrorEvent) {
+				reasons = append(reasons, event.Reason)
+			}))
+			err := retrier.Retry(5, failing)
+			So(err, ShouldEqual, expectedErr)
+			So(numCalled, ShouldEqual, 3)
+			So(reasons, ShouldResemble, []Reason{ReasonBudgetExhausted})
+		})
+
+		Convey("Is refilled by first attempts, up to its maximum", func() {
+			retrier := NewNoDelayRetrier(WithBudget(budget))
+			_ = retrier.Retry(5, failing)
+			So(budget.Tokens(), ShouldEqual, 0)
+
+			So(retrier.Retry(5, func() error { return nil }), ShouldBeNil)
+			So(budget.Tokens(), ShouldEqual, 0.5)
+			for i := 0; i < 10; i++ {
+				_ = retrier.Retry(5, func() error { return nil })
+			}
+			So(budget.Tokens(), ShouldEqual, 2)
+		})
+
+		Convey("Can be shared by multiple retriers", func() {
+			a := NewNoDelayRetrier(WithBudget(budget))
+			b := NewConstantDelayRetrier(0, WithBudget(budget))
+			_ = a.Retry(1, failing)
+			So(b.Retry(5, failing), ShouldEqual, expectedErr)
+			So(numCalled, ShouldEqual, 4)
+		})
+
+		Convey("Does not withdraw for aborted attempts", func() {
+			retrier := NewNoDelayRetrier(WithBudget(budget), WithStopOnError(expectedErr))
+			So(retrier.Retry(5, failing), ShouldEqual, expectedErr)
+			So(budget.Tokens(), ShouldEqual, 2)
+		})
+
+		Convey("Is safe for concurrent use", func() {
+			retrier := NewNoDelayRetrier(WithBudget(NewBudget(0.1, 10)))
+			var wg sync.WaitGroup
+			for i := 0; i < 50; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_ = retrier.Retry(3, func() error { return expectedErr })
+				}()
+			}
+			wg.Wait()
+		})
+	})
+}
+
+// fakeBudgetStore is a BudgetStore that keeps the tokens of budgets in memory, or fails if err is set.
+type fakeBudgetStore struct {
+	mu     sync.Mutex
+	tokens map[string]float64
+	err    error
+}
+
+func (s *fakeBudgetStore) Deposit(_ context.Context, key string, tokens, maxTokens float64) (float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return 0, s.err
+	}
+	current, ok := s.tokens[key]
+	if !ok {
+		current = maxTokens
+	}
+	s.tokens[key] = min(current+tokens, maxTokens)
+	return s.tokens[key], nil
+}
+
+func (s *fakeBudgetStore) Withdraw(_ context.Context, key string, maxTokens float64) (float64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return 0, false, s.err
+	}
+	current, ok := s.tokens[key]
+	if !ok {
+		current = maxTokens
+	}
+	if current < 1 {
+		return current, false, nil
+	}
+	s.tokens[key] = current - 1
+	return s.tokens[key], true, nil
+}
+
+func TestSharedBudget(t *testing.T) {
+	Convey("NewSharedBudget()", t, func() {
+		store := &fakeBudgetStore{tokens: make(map[string]float64)}
+		expectedErr := errors.New("foo")
+		var numCalled int
+		failing := func() error {
+			numCalled++
+			return expectedErr
+		}
+
+		Convey("Shares its tokens with other budgets of the same store and key", func() {
+			a := NewNoDelayRetrier(WithBudget(NewSharedBudget(store, "db", 0.5, 2)))
+			b := NewNoDelayRetrier(WithBudget(NewSharedBudget(store, "db", 0.5, 2)))
+			_ = a.Retry(1, failing)
+			So(b.Retry(5, failing), ShouldEqual, expectedErr)
+			So(numCalled, ShouldEqual, 4)
+			So(store.tokens["db"], ShouldEqual, 0.5)
+		})
+
+		Convey("Keeps keys apart", func() {
+			_ = NewNoDelayRetrier(WithBudget(NewSharedBudget(store, "db", 0.5, 2))).Retry(5, failing)
+			budget := NewSharedBudget(store, "cache", 0.5, 2)
+			_ = NewNoDelayRetrier(WithBudget(budget)).Retry(1, failing)
+			So(budget.Tokens(), ShouldEqual, 1)
+		})
+
+		Convey("Falls back to tokens of its own while the store fails", func() {
+			store.err = errors.New("store down")
+			budget := NewSharedBudget(store, "db", 0.5, 2)
+			So(NewNoDelayRetrier(WithBudget(budget)).Retry(5, failing), ShouldEqual, expectedErr)
+			So(numCalled, ShouldEqual, 3)
+			So(budget.Tokens(), ShouldEqual, 0)
+		})
+	})
+}
@@ -0,0 +1,76 @@
+package retry
+
+import (
+	"errors"
+	"time"
+)
+
+type actionKind int
+
+const (
+	actionRetry actionKind = iota
+	actionAbort
+	actionRetryAfter
+)
+
+// Action tells a retrier what to do after an attempt failed.
+type Action struct {
+	kind  actionKind
+	delay time.Duration
+}
+
+var (
+	// ActionRetry makes the retrier retry as it normally would.
+	ActionRetry = Action{kind: actionRetry}
+	// ActionAbort makes the retrier stop retrying and return the error of the attempt.
+	ActionAbort = Action{kind: actionAbort}
+)
+
+// RetryAfter makes the retrier retry after sleeping for the given delay, instead of the delay it would normally sleep
+// for. This is useful for honoring delays provided by a server, like the Retry-After header.
+func RetryAfter(delay time.Duration) Action {
+	return Action{kind: actionRetryAfter, delay: delay}
+}
+
+// Classifier decides what a retrier should do after an attempt failed.
+type Classifier interface {
+	// Classify returns the action to take for the given error, which is never `nil`.
+	Classify(err error) Action
+}
+
+// ClassifierFunc is a function that implements Classifier.
+type ClassifierFunc func(err error) Action
+
+// Classify calls f(err).
+func (f ClassifierFunc) Classify(err error) Action {
+	return f(err)
+}
+
+// WithClassifier makes the retrier consult the given classifier after every failed attempt.
+func WithClassifier(classifier Classifier) Option {
+	return func(cfg *config) {
+		cfg.classifier = classifier
+	}
+}
+
+// WithStopOnError makes the retrier stop retrying as soon as an attempt fails with an error matching one of the given
+// targets according to errors.Is, e.g. ErrNotFound, and return that error as is. It is a short form of a classifier
+// aborting on those errors, and is checked before the classifier.
+func WithStopOnError(targets ...error) Option {
+	return func(cfg *config) {
+		cfg.stopOn = append(cfg.stopOn, targets...)
+	}
+}
+
+// classify returns the action to take for the given error.
+func (cfg *config) classify(err error) Action {
+	for _, target := range cfg.stopOn {
+		if errors.Is(err, target) {
+			return ActionAbort
+		}
+	}
+	if cfg.classifier == nil {
+		return ActionRetry
+	}
+	return cfg.classifier.Classify(err)
+}
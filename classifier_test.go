@@ -0,0 +1,127 @@
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithClassifier(t *testing.T) {
+	Convey("WithClassifier()", t, func() {
+		errFatal := errors.New("fatal")
+		errThrottled := errors.New("throttled")
+		classifier := ClassifierFunc(func(err error) Action {
+			switch err {
+			case errFatal:
+				return ActionAbort
+			case errThrottled:
+				return RetryAfter(20 * time.Millisecond)
+			}
+			return ActionRetry
+		})
+		retrier := NewBackOffRetrier(time.Millisecond, 1, WithClassifier(classifier))
+		var numCalled int
+
+		Convey("If the classifier returns ActionRetry, keeps retrying", func() {
+			expectedErr := errors.New("foo")
+			err := retrier.Retry(2, func() error {
+				numCalled++
+				return expectedErr
+			})
+			So(err, ShouldWrap, expectedErr)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(numCalled, ShouldEqual, 3)
+		})
+
+		Convey("If the classifier returns ActionAbort, stops retrying and returns the error", func() {
+			err := retrier.Retry(10, func() error {
+				numCalled++
+				return errFatal
+			})
+			So(err, ShouldEqual, errFatal)
+			So(numCalled, ShouldEqual, 1)
+		})
+
+		Convey("If the classifier returns ActionAbort, also stops a retry loop with stop", func() {
+			err := retrier.RetryWithStop(10, func(stop func()) error {
+				numCalled++
+				return errFatal
+			})
+			So(err, ShouldEqual, errFatal)
+			So(numCalled, ShouldEqual, 1)
+		})
+
+		Convey("If the classifier returns RetryAfter(), sleeps for the given delay instead", func() {
+			startTime := time.Now()
+
+			err := retrier.Retry(10, func() error {
+				numCalled++
+				if numCalled == 2 {
+					return nil
+				}
+				return errThrottled
+			})
+			So(err, ShouldBeNil)
+			So(numCalled, ShouldEqual, 2)
+			So(time.Since(startTime), ShouldBeGreaterThanOrEqualTo, 20*time.Millisecond)
+		})
+	})
+}
+
+func TestWithStopOnError(t *testing.T) {
+	Convey("WithStopOnError()", t, func() {
+		errNotFound := errors.New("not found")
+		errDenied := errors.New("permission denied")
+		retrier := NewNoDelayRetrier(WithStopOnError(errNotFound, errDenied))
+		var numCalled int
+
+		Convey("Stops on errors matching a target, and returns them as is", func() {
+			wrapped := fmt.Errorf("get user: %w", errDenied)
+			err := retrier.Retry(10, func() error {
+				numCalled++
+				if numCalled == 2 {
+					return wrapped
+				}
+				return errors.New("timeout")
+			})
+			So(err, ShouldEqual, wrapped)
+			So(numCalled, ShouldEqual, 2)
+		})
+
+		Convey("Retries other errors", func() {
+			err := retrier.Retry(2, func() error {
+				numCalled++
+				return errors.New("timeout")
+			})
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(numCalled, ShouldEqual, 3)
+		})
+
+		Convey("Stops on errors matching a target on the last attempt too", func() {
+			err := retrier.Retry(2, func() error {
+				numCalled++
+				if numCalled == 3 {
+					return errNotFound
+				}
+				return errors.New("timeout")
+			})
+			So(err, ShouldEqual, errNotFound)
+			So(numCalled, ShouldEqual, 3)
+		})
+
+		Convey("Is checked before the classifier", func() {
+			retrier := NewNoDelayRetrier(WithStopOnError(errNotFound), WithClassifier(ClassifierFunc(func(error) Action {
+				return ActionRetry
+			})))
+			err := retrier.Retry(10, func() error {
+				numCalled++
+				return errNotFound
+			})
+			So(err, ShouldEqual, errNotFound)
+			So(numCalled, ShouldEqual, 1)
+		})
+	})
+}
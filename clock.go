@@ -0,0 +1,44 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Clock tells the time and sleeps. Retriers use the real clock by default. A different clock can be set using
+// WithClock, e.g. to run retry loops in virtual time.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// Sleep sleeps for the given duration, or until the given context is done, in which case it returns the context
+	// error.
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// realClock is the real clock.
+type realClock struct{}
+
+// Now implements Clock.
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Sleep implements Clock.
+func (realClock) Sleep(ctx context.Context, d time.Duration) error {
+	return sleep(ctx, d)
+}
+
+// WithClock makes the retrier use the given clock to tell the time and to sleep between attempts.
+func WithClock(clock Clock) Option {
+	return func(cfg *config) {
+		cfg.clock = clock
+	}
+}
+
+// getClock returns the clock to use.
+func (cfg *config) getClock() Clock {
+	if cfg.clock == nil {
+		return realClock{}
+	}
+	return cfg.clock
+}
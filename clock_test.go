@@ -0,0 +1,53 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fakeClock is a clock that records sleeps, other than those of 0, instead of sleeping.
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Sleep(_ context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+	return nil
+}
+
+func TestWithClock(t *testing.T) {
+	Convey("WithClock()", t, func() {
+		Convey("Makes the retrier tell the time and sleep using the given clock", func() {
+			clock := &fakeClock{now: time.Now()}
+			retrier := NewBackOffRetrier(time.Hour, 2, WithClock(clock))
+			startTime := time.Now()
+			err := retrier.Retry(2, func() error {
+				return errors.New("foo")
+			})
+			So(time.Since(startTime), ShouldBeLessThan, time.Second)
+			So(clock.sleeps, ShouldResemble, []time.Duration{time.Hour, 2 * time.Hour})
+
+			var retryErr *Error
+			So(errors.As(err, &retryErr), ShouldBeTrue)
+			So(retryErr.TotalSlept, ShouldEqual, 3*time.Hour)
+			So(retryErr.TotalElapsed, ShouldEqual, 3*time.Hour)
+		})
+
+		Convey("Defaults to the real clock", func() {
+			So(NewNoDelayRetrier().cfg.getClock(), ShouldResemble, realClock{})
+		})
+	})
+}
@@ -0,0 +1,80 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// ConstantDelayRetrier retries a given callback, sleeping for the same delay after every failed attempt.
+// Unlike RetryWithDelay, it does not sleep after the last attempt.
+type ConstantDelayRetrier struct {
+	delay time.Duration
+	cfg   config
+}
+
+// NewConstantDelayRetrier returns a new retrier that sleeps for the given delay after every failed attempt.
+func NewConstantDelayRetrier(delay time.Duration, opts ...Option) *ConstantDelayRetrier {
+	return &ConstantDelayRetrier{delay: delay, cfg: newConfig(opts)}
+}
+
+// Delay returns the delay after every failed attempt.
+func (r *ConstantDelayRetrier) Delay() time.Duration {
+	return r.delay
+}
+
+// Retry retries the given callback at max the given number of times.
+// It stops as soon as a `nil` error is returned.
+func (r *ConstantDelayRetrier) Retry(numTimes int, cb func() error) error {
+	return r.RetryCtx(context.Background(), numTimes, cb)
+}
+
+// RetryCtx retries the given callback at max the given number of times.
+// It stops as soon as a `nil` error is returned.
+func (r *ConstantDelayRetrier) RetryCtx(ctx context.Context, numTimes int, cb func() error) error {
+	return retryLoop(ctx, &r.cfg, numTimes, false, ConstantBackoff(r.delay), func(func()) error {
+		return cb()
+	})
+}
+
+// RetryForever retries the given callback until a `nil` error is returned or the given context is done.
+func (r *ConstantDelayRetrier) RetryForever(ctx context.Context, cb func() error) error {
+	return r.RetryCtx(ctx, Forever, cb)
+}
+
+// RetryWithStop retries the given callback at max the given number of times.
+// It stops only when `stop` is called.
+func (r *ConstantDelayRetrier) RetryWithStop(numTimes int, cb func(stop func()) error) error {
+	return r.RetryWithStopCtx(context.Background(), numTimes, cb)
+}
+
+// RetryWithStopCtx retries the given callback at max the given number of times.
+// It stops only when `stop` is called.
+func (r *ConstantDelayRetrier) RetryWithStopCtx(ctx context.Context, numTimes int, cb func(stop func()) error) error {
+	return retryLoop(ctx, &r.cfg, numTimes, true, ConstantBackoff(r.delay), cb)
+}
+
+// RetryWithAbort retries the given callback at max the given number of times.
+// It stops as soon as a `nil` error is returned, or when `abort` is called, in which case the error passed to `abort`
+// is returned.
+func (r *ConstantDelayRetrier) RetryWithAbort(numTimes int, cb func(abort func(err error)) error) error {
+	return r.RetryWithAbortCtx(context.Background(), numTimes, cb)
+}
+
+// RetryWithAbortCtx retries the given callback at max the given number of times.
+// It stops as soon as a `nil` error is returned, or when `abort` is called, in which case the error passed to `abort`
+// is returned.
+func (r *ConstantDelayRetrier) RetryWithAbortCtx(ctx context.Context, numTimes int, cb func(abort func(err error)) error) error {
+	return retryLoop(ctx, &r.cfg, numTimes, false, ConstantBackoff(r.delay), withAbort(cb))
+}
+
+// Stats returns the cumulative statistics of the retrier since it was created or its statistics were last reset.
+// It is safe for concurrent use.
+func (r *ConstantDelayRetrier) Stats() RetrierStats {
+	return r.cfg.stats.get()
+}
+
+// ResetStats resets the statistics of the retrier to zero, except for the number of retry loops that are currently
+// retrying.
+func (r *ConstantDelayRetrier) ResetStats() {
+	r.cfg.stats.reset()
+}
@@ -0,0 +1,108 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_ConstantDelayRetrier(t *testing.T) {
+	Convey("*ConstantDelayRetrier", t, func() {
+		delay := 10 * time.Millisecond
+		retrier := NewConstantDelayRetrier(delay)
+		expectedErr := errors.New("foo")
+		var numCalled int
+
+		Convey("Delay() returns the delay", func() {
+			So(retrier.Delay(), ShouldEqual, delay)
+		})
+
+		Convey("Retry() retries errors until nil is returned", func() {
+			err := retrier.Retry(5, func() error {
+				numCalled++
+				if numCalled < 3 {
+					return expectedErr
+				}
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(numCalled, ShouldEqual, 3)
+		})
+
+		Convey("Retry() sleeps for the delay after every failed attempt, but not after the last", func() {
+			start := time.Now()
+			err := retrier.Retry(2, func() error {
+				numCalled++
+				return expectedErr
+			})
+			elapsed := time.Since(start)
+			So(err, ShouldWrap, expectedErr)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(numCalled, ShouldEqual, 3)
+			So(elapsed, ShouldBeGreaterThanOrEqualTo, 2*delay)
+			So(elapsed, ShouldBeLessThan, 3*delay)
+
+			var retryErr *Error
+			So(errors.As(err, &retryErr), ShouldBeTrue)
+			So(retryErr.TotalSlept, ShouldBeGreaterThanOrEqualTo, 2*delay)
+		})
+
+		Convey("RetryCtx() returns the context error if the context is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			err := retrier.RetryCtx(ctx, 5, func() error {
+				numCalled++
+				return nil
+			})
+			So(err, ShouldEqual, context.Canceled)
+			So(numCalled, ShouldEqual, 0)
+		})
+
+		Convey("RetryWithStop() stops only when stop is called", func() {
+			err := retrier.RetryWithStop(5, func(stop func()) error {
+				numCalled++
+				if numCalled == 2 {
+					stop()
+				}
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(numCalled, ShouldEqual, 2)
+		})
+
+		Convey("RetryWithStopCtx() retries until the maximum number of retries is reached", func() {
+			err := retrier.RetryWithStopCtx(context.Background(), 1, func(stop func()) error {
+				numCalled++
+				return expectedErr
+			})
+			So(err, ShouldWrap, expectedErr)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(numCalled, ShouldEqual, 2)
+		})
+
+		Convey("RetryWithAbort() stops when abort is called and returns the given error", func() {
+			err := retrier.RetryWithAbort(5, func(abort func(err error)) error {
+				numCalled++
+				abort(expectedErr)
+				return nil
+			})
+			So(err, ShouldEqual, expectedErr)
+			So(numCalled, ShouldEqual, 1)
+		})
+
+		Convey("Accepts options", func() {
+			retrier := NewConstantDelayRetrier(time.Hour, WithClassifier(ClassifierFunc(func(error) Action {
+				return ActionAbort
+			})))
+			err := retrier.Retry(5, func() error {
+				numCalled++
+				return expectedErr
+			})
+			So(err, ShouldEqual, expectedErr)
+			So(numCalled, ShouldEqual, 1)
+		})
+	})
+}
@@ -0,0 +1,65 @@
+package retry
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ConsumerPause throttles the intake of a pull-based consumer during downstream outages. Once the handler fails a
+// given number of times in a row, it pauses fetching from the source for a delay returned by a backoff strategy,
+// instead of retrying every message individually. Every further failure after a pause pauses again, for the next
+// delay of the strategy, until a message is handled successfully.
+// It is safe for concurrent use, so it can be shared by the workers of a consumer.
+type ConsumerPause struct {
+	threshold int
+	strategy  BackoffStrategy
+	now       func() time.Time
+
+	mu          sync.Mutex
+	numFailures int
+	numPauses   int
+	pause       time.Duration
+	pausedUntil time.Time
+}
+
+// NewConsumerPause returns a new consumer pause that pauses after the given number of consecutive failures, for the
+// delays returned by the given strategy.
+func NewConsumerPause(threshold int, strategy BackoffStrategy) *ConsumerPause {
+	return &ConsumerPause{threshold: threshold, strategy: strategy, now: time.Now}
+}
+
+// Wait blocks while the consumer is paused, or until the given context is done, in which case it returns the context
+// error. Call it before fetching from the source.
+func (p *ConsumerPause) Wait(ctx context.Context) error {
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+	return sleep(ctx, p.PausedUntil().Sub(p.now()))
+}
+
+// Record records the result of handling a message. A `nil` error resets the pause.
+func (p *ConsumerPause) Record(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err == nil {
+		p.numFailures = 0
+		p.numPauses = 0
+		p.pause = 0
+		return
+	}
+	p.numFailures++
+	if p.numFailures < p.threshold && p.numPauses == 0 {
+		return
+	}
+	p.numPauses++
+	p.pause = p.strategy.Delay(p.numPauses, p.pause)
+	p.pausedUntil = p.now().Add(p.pause)
+}
+
+// PausedUntil returns the time until which the consumer is paused. It is in the past if the consumer is not paused.
+func (p *ConsumerPause) PausedUntil() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.pausedUntil
+}
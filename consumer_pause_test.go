@@ -0,0 +1,78 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_ConsumerPause(t *testing.T) {
+	Convey("*ConsumerPause", t, func() {
+		now := time.Now()
+		pause := NewConsumerPause(3, BackoffFunc(func(retry int, prev time.Duration) time.Duration {
+			return time.Duration(retry) * time.Second
+		}))
+		pause.now = func() time.Time {
+			return now
+		}
+		expectedErr := errors.New("foo")
+
+		Convey("Does not pause until the threshold of consecutive failures is reached", func() {
+			pause.Record(expectedErr)
+			pause.Record(expectedErr)
+			So(pause.PausedUntil().After(now), ShouldBeFalse)
+
+			pause.Record(expectedErr)
+			So(pause.PausedUntil(), ShouldEqual, now.Add(time.Second))
+
+			Convey("Every further failure pauses for the next delay of the strategy", func() {
+				pause.Record(expectedErr)
+				So(pause.PausedUntil(), ShouldEqual, now.Add(2*time.Second))
+			})
+
+			Convey("A success resets the pause", func() {
+				pause.Record(nil)
+				pause.Record(expectedErr)
+				pause.Record(expectedErr)
+				So(pause.PausedUntil(), ShouldEqual, now.Add(time.Second))
+				pause.Record(expectedErr)
+				So(pause.PausedUntil(), ShouldEqual, now.Add(time.Second))
+			})
+		})
+
+		Convey("A success resets the number of consecutive failures", func() {
+			pause.Record(expectedErr)
+			pause.Record(expectedErr)
+			pause.Record(nil)
+			pause.Record(expectedErr)
+			So(pause.PausedUntil().After(now), ShouldBeFalse)
+		})
+
+		Convey("Wait()", func() {
+			pause := NewConsumerPause(1, ConstantBackoff(20*time.Millisecond))
+
+			Convey("Returns right away if not paused", func() {
+				start := time.Now()
+				So(pause.Wait(context.Background()), ShouldBeNil)
+				So(time.Since(start), ShouldBeLessThan, 10*time.Millisecond)
+			})
+
+			Convey("Blocks while paused", func() {
+				pause.Record(expectedErr)
+				start := time.Now()
+				So(pause.Wait(context.Background()), ShouldBeNil)
+				So(time.Since(start), ShouldBeGreaterThanOrEqualTo, 15*time.Millisecond)
+			})
+
+			Convey("Returns the context error if the context is done", func() {
+				pause.Record(expectedErr)
+				ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+				defer cancel()
+				So(pause.Wait(ctx), ShouldEqual, context.DeadlineExceeded)
+			})
+		})
+	})
+}
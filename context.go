@@ -0,0 +1,111 @@
+package retry
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/rand/v2"
+)
+
+type noRetryKey struct{}
+
+// ContextNoRetry returns a copy of the given context that makes every retrier it is passed to make exactly one
+// attempt. Use it when an upper layer already retries, to prevent nested retriers from multiplying the number of
+// attempts.
+func ContextNoRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetryKey{}, true)
+}
+
+// isNoRetry reports whether the given context was marked using ContextNoRetry.
+func isNoRetry(ctx context.Context) bool {
+	noRetry, _ := ctx.Value(noRetryKey{}).(bool)
+	return noRetry
+}
+
+type attemptKey struct{}
+
+// Attempt describes the attempt of a retry loop that a context was passed to.
+type Attempt struct {
+	// Number is the number of the attempt, counting from 1.
+	Number int
+	// LastErr is the error of the previous attempt, or nil for the first attempt.
+	LastErr error
+	// ID is a random UUID that is unique to the attempt, for use as e.g. a trace ID or a deduplication key.
+	ID string
+
+	rand *rand.Rand
+}
+
+// Rand returns a source of random numbers of the attempt, seeded from its ID, so that operations don't have to wire
+// their own generators into their callbacks. It must not be used by multiple goroutines at once.
+func (a Attempt) Rand() *rand.Rand {
+	return a.rand
+}
+
+// newAttempt returns a new attempt with the given number and last error, and a random ID.
+func newAttempt(number int, lastErr error) Attempt {
+	id := newUUID()
+	return Attempt{
+		Number:  number,
+		LastErr: lastErr,
+		ID:      uuidString(id),
+		rand:    rand.New(rand.NewPCG(binary.BigEndian.Uint64(id[:8]), binary.BigEndian.Uint64(id[8:]))),
+	}
+}
+
+// newUUID returns a random UUID.
+func newUUID() [16]byte {
+	var id [16]byte
+	_, _ = crand.Read(id[:])  // Never returns an error.
+	id[6] = id[6]&0x0f | 0x40 // Version 4.
+	id[8] = id[8]&0x3f | 0x80 // Variant RFC 9562.
+	return id
+}
+
+// uuidString returns the canonical text form of the given UUID.
+func uuidString(id [16]byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:])
+}
+
+// AttemptFromContext returns the attempt that the given context was passed to by Do, so that deeply nested code, like
+// loggers and HTTP clients, can tag its work with the attempt. It reports false if the context was not passed to an
+// attempt.
+func AttemptFromContext(ctx context.Context) (Attempt, bool) {
+	attempt, ok := ctx.Value(attemptKey{}).(Attempt)
+	return attempt, ok
+}
+
+// Do retries the given callback at max the given number of times using the given retryer. It stops as soon as a
+// `nil` error is returned. Every attempt receives a child context of the given context that carries the attempt,
+// which can be retrieved using AttemptFromContext. The context is cancelled once the maximum elapsed time set using
+// WithMaxElapsedTime passes, unless a clock is set using WithClock, so that long-running attempts can abort
+// cooperatively. Its cause is ErrMaxElapsedTimeExceeded then.
+func Do(ctx context.Context, r Retryer, numTimes int, cb func(ctx context.Context) error) error {
+	loopCtx, signal := withStopSignal(ctx)
+	defer signal.stop(context.Canceled)
+	return r.RetryCtx(loopCtx, numTimes, withAttemptContext(signal.ctx, cb))
+}
+
+// withAttemptContext returns a callback that calls the given callback with a child context of the given context that
+// carries the attempt.
+func withAttemptContext(ctx context.Context, cb func(ctx context.Context) error) func() error {
+	withStop := withAttemptContextStop(ctx, func(ctx context.Context, _ func()) error {
+		return cb(ctx)
+	})
+	return func() error {
+		return withStop(nil)
+	}
+}
+
+// withAttemptContextStop is like withAttemptContext, for callbacks that receive `stop`.
+func withAttemptContextStop(ctx context.Context, cb func(ctx context.Context, stop func()) error) func(stop func()) error {
+	var number int
+	var lastErr error
+	return func(stop func()) error {
+		number++
+		err := cb(context.WithValue(ctx, attemptKey{}, newAttempt(number, lastErr)), stop)
+		lastErr = err
+		return err
+	}
+}
@@ -0,0 +1,178 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestContextNoRetry(t *testing.T) {
+	Convey("ContextNoRetry()", t, func() {
+		ctx := ContextNoRetry(context.Background())
+		var numCalled int
+		cb := func() error {
+			numCalled++
+			return errors.New("foo")
+		}
+
+		Convey("Makes retriers make exactly one attempt", func() {
+			err := RetryCtx(ctx, 10, cb)
+			So(err, ShouldNotBeNil)
+			So(numCalled, ShouldEqual, 1)
+
+			err = NewBackOffRetrier(0, 1).RetryCtx(ctx, Forever, cb)
+			So(err, ShouldNotBeNil)
+			So(numCalled, ShouldEqual, 2)
+		})
+
+		Convey("Also applies to retriers with stop", func() {
+			err := RetryWithStopCtx(ctx, 10, func(stop func()) error {
+				numCalled++
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(numCalled, ShouldEqual, 1)
+		})
+
+		Convey("Is inherited by child contexts", func() {
+			childCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+
+			_ = RetryCtx(childCtx, 10, cb)
+			So(numCalled, ShouldEqual, 1)
+		})
+
+		Convey("Does not affect other contexts", func() {
+			_ = RetryCtx(context.Background(), 2, cb)
+			So(numCalled, ShouldEqual, 3)
+		})
+	})
+}
+
+func TestDeadlineAwareness(t *testing.T) {
+	Convey("Retrying with a context that has a deadline", t, func() {
+		var numCalled int
+
+		Convey("If the next attempt can't finish before the deadline, gives up right away", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			startTime := time.Now()
+
+			expectedErr := errors.New("foo")
+			err := NewBackOffRetrier(30*time.Millisecond, 1).RetryCtx(ctx, 10, func() error {
+				numCalled++
+				time.Sleep(20 * time.Millisecond)
+				return expectedErr
+			})
+			So(err, ShouldWrap, context.DeadlineExceeded)
+			So(err, ShouldWrap, expectedErr)
+			So(numCalled, ShouldEqual, 2) // 20ms + 30ms + 20ms; another 30ms + 20ms would not fit.
+			So(time.Since(startTime), ShouldBeLessThan, 100*time.Millisecond)
+		})
+
+		Convey("If the next attempt can finish before the deadline, retries", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			err := NewBackOffRetrier(time.Millisecond, 1).RetryCtx(ctx, 10, func() error {
+				numCalled++
+				if numCalled == 3 {
+					return nil
+				}
+				return errors.New("foo")
+			})
+			So(err, ShouldBeNil)
+			So(numCalled, ShouldEqual, 3)
+		})
+	})
+}
+
+func TestCancellationDuringSleep(t *testing.T) {
+	Convey("Cancelling the context while sleeping", t, func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+		}()
+		startTime := time.Now()
+		cb := func() error {
+			return errors.New("foo")
+		}
+
+		Convey("Interrupts the sleep of a back off retrier", func() {
+			err := NewBackOffRetrier(time.Hour, 1).RetryCtx(ctx, 1, cb)
+			So(err, ShouldEqual, context.Canceled)
+			So(time.Since(startTime), ShouldBeLessThan, time.Second)
+		})
+
+		Convey("Interrupts the sleep of RetryWithDelayCtx()", func() {
+			err := RetryWithDelayCtx(ctx, 1, time.Hour, cb)
+			So(err, ShouldEqual, context.Canceled)
+			So(time.Since(startTime), ShouldBeLessThan, time.Second)
+		})
+	})
+}
+
+func TestDo(t *testing.T) {
+	Convey("Do()", t, func() {
+		retrier := NewNoDelayRetrier()
+
+		Convey("Passes the attempt in the context of every attempt", func() {
+			expectedErr := errors.New("foo")
+			var attempts []Attempt
+			err := Do(context.Background(), retrier, 5, func(ctx context.Context) error {
+				attempt, ok := AttemptFromContext(ctx)
+				So(ok, ShouldBeTrue)
+				attempts = append(attempts, attempt)
+				if attempt.Number == 3 {
+					return nil
+				}
+				return expectedErr
+			})
+			So(err, ShouldBeNil)
+			So(attempts, ShouldHaveLength, 3)
+			for i, attempt := range attempts {
+				So(attempt.Number, ShouldEqual, i+1)
+			}
+			So(attempts[0].LastErr, ShouldBeNil)
+			So(attempts[1].LastErr, ShouldEqual, expectedErr)
+			So(attempts[2].LastErr, ShouldEqual, expectedErr)
+		})
+
+		Convey("Gives every attempt a unique ID and a source of random numbers", func() {
+			var attempts []Attempt
+			_ = Do(context.Background(), retrier, 2, func(ctx context.Context) error {
+				attempt, _ := AttemptFromContext(ctx)
+				attempts = append(attempts, attempt)
+				return errors.New("foo")
+			})
+			ids := map[string]bool{}
+			for _, attempt := range attempts {
+				So(attempt.ID, ShouldHaveLength, 36)
+				So(attempt.ID[14], ShouldEqual, '4')
+				ids[attempt.ID] = true
+				So(attempt.Rand(), ShouldNotBeNil)
+			}
+			So(ids, ShouldHaveLength, 3)
+			So(attempts[0].Rand().Uint64(), ShouldNotEqual, attempts[1].Rand().Uint64())
+		})
+
+		Convey("Passes a child context of the given context", func() {
+			type key struct{}
+			ctx := context.WithValue(context.Background(), key{}, "bar")
+			err := Do(ctx, retrier, 0, func(ctx context.Context) error {
+				So(ctx.Value(key{}), ShouldEqual, "bar")
+				return nil
+			})
+			So(err, ShouldBeNil)
+		})
+
+		Convey("AttemptFromContext() reports false for contexts not passed to an attempt", func() {
+			_, ok := AttemptFromContext(context.Background())
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,178 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrDependencyDown is returned instead of making an attempt when the dependency of a retrier was marked down in its
+// coordination store, by this or by another process. See WithCoordination.
+var ErrDependencyDown = errors.New("dependency marked down")
+
+// CoordinationStore stores until when dependencies are marked down, so that the retriers of a fleet of processes can
+// back off collectively. Implementations must be safe for concurrent use. Use an external store, like Redis, to
+// coordinate across processes.
+type CoordinationStore interface {
+	// MarkDown marks the given dependency down until the given time. It must not shorten an existing mark.
+	MarkDown(ctx context.Context, dependency string, until time.Time) error
+	// DownUntil returns until when the given dependency is marked down, and false if it never was.
+	DownUntil(ctx context.Context, dependency string) (time.Time, bool, error)
+}
+
+// MemoryCoordinationStore is a CoordinationStore that keeps marks in memory, which coordinates the retriers of a
+// single process.
+type MemoryCoordinationStore struct {
+	mu    sync.Mutex
+	marks map[string]time.Time
+}
+
+// NewMemoryCoordinationStore returns a new in-memory coordination store.
+func NewMemoryCoordinationStore() *MemoryCoordinationStore {
+	return &MemoryCoordinationStore{marks: make(map[string]time.Time)}
+}
+
+// MarkDown implements CoordinationStore.
+func (s *MemoryCoordinationStore) MarkDown(_ context.Context, dependency string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if until.After(s.marks[dependency]) {
+		s.marks[dependency] = until
+	}
+	return nil
+}
+
+// DownUntil implements CoordinationStore.
+func (s *MemoryCoordinationStore) DownUntil(_ context.Context, dependency string) (time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	until, ok := s.marks[dependency]
+	return until, ok, nil
+}
+
+// WithCoordination makes the retrier coordinate with the retriers of other processes through the given store. When a
+// retry loop gives up, it marks the given dependency down for the given cooldown. While the dependency is marked down,
+// retry loops of any retrier sharing the store and the dependency make no more attempts and return ErrDependencyDown,
+// so that a fleet backs off collectively, instead of every instance rediscovering the outage on its own.
+// Errors of the store are returned as is, joined with the error of the retrier when marking the dependency down.
+func WithCoordination(store CoordinationStore, dependency string, cooldown time.Duration) Option {
+	return func(cfg *config) {
+		cfg.coordStore = store
+		cfg.dependency = dependency
+		cfg.cooldown = cooldown
+	}
+}
+
+// checkDependency returns ErrDependencyDown if the dependency is marked down in the coordination store.
+func (cfg *config) checkDependency(ctx context.Context, now time.Time) error {
+	if cfg.coordStore == nil {
+		return nil
+	}
+	until, ok, err := cfg.coordStore.DownUntil(ctx, cfg.dependency)
+	if err != nil {
+		return err
+	}
+	if ok && now.Before(until) {
+		return ErrDependencyDown
+	}
+	return nil
+}
+
+// markDependencyDown marks the dependency down in the coordination store for the cooldown, if configured.
+func (cfg *config) markDependencyDown(ctx context.Context, now time.Time) error {
+	if cfg.coordStore == nil {
+		return nil
+	}
+	return cfg.coordStore.MarkDown(ctx, cfg.dependency, now.Add(cfg.cooldown))
+}
+
+// BackoffState is the backoff state of a key that is shared by the retriers of multiple processes.
+type BackoffState struct {
+	// Failures is the number of consecutive failed attempts that were followed by a backoff.
+	Failures int
+	// Delay is the delay after the last of those attempts, before jitter.
+	Delay time.Duration
+}
+
+// BackoffStore stores backoff states, so that the retriers of a fleet of processes back off from the same dependency
+// together. Implementations must be safe for concurrent use. Use an external store, like Redis, to share backoff
+// states across processes.
+type BackoffStore interface {
+	// Load returns the backoff state of the given key, which is the zero state if there is none.
+	Load(ctx context.Context, key string) (BackoffState, error)
+	// Save saves the backoff state of the given key. It must not lower the number of failures of an existing state.
+	Save(ctx context.Context, key string, state BackoffState) error
+	// Reset deletes the backoff state of the given key.
+	Reset(ctx context.Context, key string) error
+}
+
+// MemoryBackoffStore is a BackoffStore that keeps backoff states in memory, which coordinates the retriers of a single
+// process.
+type MemoryBackoffStore struct {
+	mu     sync.Mutex
+	states map[string]BackoffState
+}
+
+// NewMemoryBackoffStore returns a new in-memory backoff store.
+func NewMemoryBackoffStore() *MemoryBackoffStore {
+	return &MemoryBackoffStore{states: make(map[string]BackoffState)}
+}
+
+// Load implements BackoffStore.
+func (s *MemoryBackoffStore) Load(_ context.Context, key string) (BackoffState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.states[key], nil
+}
+
+// Save implements BackoffStore.
+func (s *MemoryBackoffStore) Save(_ context.Context, key string, state BackoffState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if state.Failures > s.states[key].Failures {
+		s.states[key] = state
+	}
+	return nil
+}
+
+// Reset implements BackoffStore.
+func (s *MemoryBackoffStore) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.states, key)
+	return nil
+}
+
+// WithSharedBackoff makes the retrier share the backoff state of the given key with the retriers of other processes
+// through the given store. A retry loop continues from the shared number of consecutive failures and delay if they are
+// ahead of its own, so that a retry loop starting during an outage backs off as far as the ones that were already
+// retrying, instead of hitting the dependency at the initial delay. A successful attempt resets the shared state.
+// While the store fails, the retrier falls back to its own backoff.
+func WithSharedBackoff(store BackoffStore, key string) Option {
+	return func(cfg *config) {
+		cfg.backoffStore = store
+		cfg.backoffKey = key
+	}
+}
+
+// sharedDelay returns the delay returned by the given strategy before the given retry, given the previous delay,
+// continuing from the shared backoff state if it is ahead.
+func (cfg *config) sharedDelay(ctx context.Context, strategy BackoffStrategy, retry int, prev time.Duration) time.Duration {
+	if cfg.backoffStore == nil {
+		return strategy.Delay(retry, prev)
+	}
+	if state, err := cfg.backoffStore.Load(ctx, cfg.backoffKey); err == nil && state.Failures >= retry {
+		retry, prev = state.Failures+1, state.Delay
+	}
+	delay := strategy.Delay(retry, prev)
+	_ = cfg.backoffStore.Save(ctx, cfg.backoffKey, BackoffState{Failures: retry, Delay: delay})
+	return delay
+}
+
+// resetSharedBackoff resets the shared backoff state, if configured.
+func (cfg *config) resetSharedBackoff(ctx context.Context) {
+	if cfg.backoffStore != nil {
+		_ = cfg.backoffStore.Reset(ctx, cfg.backoffKey)
+	}
+}
@@ -0,0 +1,185 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// failingCoordinationStore is a CoordinationStore that always fails.
+type failingCoordinationStore struct {
+	err error
+}
+
+func (s failingCoordinationStore) MarkDown(context.Context, string, time.Time) error {
+	return s.err
+}
+
+func (s failingCoordinationStore) DownUntil(context.Context, string) (time.Time, bool, error) {
+	return time.Time{}, false, nil
+}
+
+func TestCoordination(t *testing.T) {
+	Convey("WithCoordination()", t, func() {
+		ctx := context.Background()
+		store := NewMemoryCoordinationStore()
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		newRetrier := func() *ConstantDelayRetrier {
+			return NewConstantDelayRetrier(time.Second, WithClock(clock), WithCoordination(store, "db", time.Minute))
+		}
+		expectedErr := errors.New("foo")
+		var numCalled int
+		failing := func() error {
+			numCalled++
+			return expectedErr
+		}
+
+		Convey("Marks the dependency down when a retrier gives up", func() {
+			err := newRetrier().Retry(1, failing)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			until, ok, _ := store.DownUntil(ctx, "db")
+			So(ok, ShouldBeTrue)
+			So(until, ShouldEqual, clock.Now().Add(time.Minute))
+		})
+
+		Convey("Makes no attempts while the dependency is marked down, also for other retriers", func() {
+			_ = newRetrier().Retry(1, failing)
+			numCalled = 0
+
+			err := newRetrier().Retry(1, failing)
+			So(err, ShouldEqual, ErrDependencyDown)
+			So(numCalled, ShouldEqual, 0)
+
+			clock.now = clock.now.Add(time.Minute)
+			_ = newRetrier().Retry(1, failing)
+			So(numCalled, ShouldEqual, 2)
+		})
+
+		Convey("Stops retrying once another retrier marks the dependency down", func() {
+			err := newRetrier().Retry(5, func() error {
+				numCalled++
+				if numCalled == 2 {
+					_ = store.MarkDown(ctx, "db", clock.Now().Add(time.Hour))
+				}
+				return expectedErr
+			})
+			So(err, ShouldEqual, ErrDependencyDown)
+			So(numCalled, ShouldEqual, 2)
+		})
+
+		Convey("Does not mark the dependency down on success or abort", func() {
+			_ = newRetrier().Retry(1, func() error { return nil })
+			_ = newRetrier().RetryWithAbort(1, func(abort func(err error)) error {
+				abort(expectedErr)
+				return nil
+			})
+			_, ok, _ := store.DownUntil(ctx, "db")
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("Keeps dependencies apart", func() {
+			_ = newRetrier().Retry(0, failing)
+			other := NewNoDelayRetrier(WithCoordination(store, "cache", time.Minute))
+			So(other.Retry(0, func() error { return nil }), ShouldBeNil)
+		})
+
+		Convey("Joins errors of the store with the error of the retrier", func() {
+			storeErr := errors.New("store down")
+			retrier := NewNoDelayRetrier(WithCoordination(failingCoordinationStore{err: storeErr}, "db", time.Minute))
+			err := retrier.Retry(0, failing)
+			So(err, ShouldWrap, storeErr)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+		})
+
+		Convey("MemoryCoordinationStore does not shorten marks", func() {
+			_ = store.MarkDown(ctx, "db", time.Unix(100, 0))
+			_ = store.MarkDown(ctx, "db", time.Unix(50, 0))
+			until, _, _ := store.DownUntil(ctx, "db")
+			So(until, ShouldEqual, time.Unix(100, 0))
+		})
+	})
+}
+
+// failingBackoffStore is a BackoffStore that always fails.
+type failingBackoffStore struct {
+	err error
+}
+
+func (s failingBackoffStore) Load(context.Context, string) (BackoffState, error) {
+	return BackoffState{}, s.err
+}
+
+func (s failingBackoffStore) Save(context.Context, string, BackoffState) error {
+	return s.err
+}
+
+func (s failingBackoffStore) Reset(context.Context, string) error {
+	return s.err
+}
+
+func TestSharedBackoff(t *testing.T) {
+	Convey("WithSharedBackoff()", t, func() {
+		ctx := context.Background()
+		store := NewMemoryBackoffStore()
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		newRetrier := func() *BackOffRetrier {
+			return NewBackOffRetrier(time.Second, 2, WithClock(clock), WithSharedBackoff(store, "db"))
+		}
+		expectedErr := errors.New("foo")
+		failing := func() error {
+			return expectedErr
+		}
+
+		Convey("Saves the backoff state", func() {
+			_ = newRetrier().Retry(2, failing)
+			So(clock.sleeps, ShouldResemble, []time.Duration{time.Second, 2 * time.Second})
+			state, _ := store.Load(ctx, "db")
+			So(state, ShouldResemble, BackoffState{Failures: 2, Delay: 2 * time.Second})
+		})
+
+		Convey("Continues from the shared state if it is ahead", func() {
+			_ = newRetrier().Retry(2, failing)
+			clock.sleeps = nil
+			_ = newRetrier().Retry(2, failing)
+			So(clock.sleeps, ShouldResemble, []time.Duration{4 * time.Second, 8 * time.Second})
+			state, _ := store.Load(ctx, "db")
+			So(state.Failures, ShouldEqual, 4)
+		})
+
+		Convey("Resets the shared state on success", func() {
+			_ = newRetrier().Retry(2, failing)
+			So(newRetrier().Retry(2, func() error { return nil }), ShouldBeNil)
+			state, _ := store.Load(ctx, "db")
+			So(state, ShouldResemble, BackoffState{})
+
+			clock.sleeps = nil
+			_ = newRetrier().Retry(1, failing)
+			So(clock.sleeps, ShouldResemble, []time.Duration{time.Second})
+		})
+
+		Convey("Keeps keys apart", func() {
+			_ = newRetrier().Retry(2, failing)
+			clock.sleeps = nil
+			other := NewBackOffRetrier(time.Second, 2, WithClock(clock), WithSharedBackoff(store, "cache"))
+			_ = other.Retry(1, failing)
+			So(clock.sleeps, ShouldResemble, []time.Duration{time.Second})
+		})
+
+		Convey("Falls back to its own backoff while the store fails", func() {
+			retrier := NewBackOffRetrier(time.Second, 2, WithClock(clock), WithSharedBackoff(failingBackoffStore{err: errors.New("store down")}, "db"))
+			err := retrier.Retry(2, failing)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(clock.sleeps, ShouldResemble, []time.Duration{time.Second, 2 * time.Second})
+		})
+
+		Convey("MemoryBackoffStore does not lower the number of failures", func() {
+			_ = store.Save(ctx, "db", BackoffState{Failures: 3, Delay: time.Second})
+			_ = store.Save(ctx, "db", BackoffState{Failures: 2, Delay: time.Minute})
+			state, _ := store.Load(ctx, "db")
+			So(state, ShouldResemble, BackoffState{Failures: 3, Delay: time.Second})
+		})
+	})
+}
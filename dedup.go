@@ -0,0 +1,116 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrAlreadySucceeded is returned instead of making an attempt when a success for the idempotency key of the context
+// was observed within the deduplication window. See WithDeduplication.
+var ErrAlreadySucceeded = errors.New("already succeeded")
+
+// SuccessStore stores when operations, identified by their idempotency keys, last succeeded. Implementations must be
+// safe for concurrent use. Use an external store, like a database, to deduplicate across processes.
+type SuccessStore interface {
+	// RecordSuccess records that the operation with the given key succeeded at the given time.
+	RecordSuccess(ctx context.Context, key string, at time.Time) error
+	// LastSuccess returns when the operation with the given key last succeeded, and false if it never did.
+	LastSuccess(ctx context.Context, key string) (time.Time, bool, error)
+}
+
+// MemorySuccessStore is a SuccessStore that keeps successes in memory, for the given TTL.
+type MemorySuccessStore struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	successes map[string]time.Time
+}
+
+// NewMemorySuccessStore returns a new in-memory success store that forgets successes after the given TTL, which
+// should be at least the deduplication window.
+func NewMemorySuccessStore(ttl time.Duration) *MemorySuccessStore {
+	return &MemorySuccessStore{ttl: ttl, successes: make(map[string]time.Time)}
+}
+
+// RecordSuccess implements SuccessStore.
+func (s *MemorySuccessStore) RecordSuccess(_ context.Context, key string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, t := range s.successes {
+		if at.Sub(t) >= s.ttl {
+			delete(s.successes, k)
+		}
+	}
+	s.successes[key] = at
+	return nil
+}
+
+// LastSuccess implements SuccessStore.
+func (s *MemorySuccessStore) LastSuccess(_ context.Context, key string) (time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	at, ok := s.successes[key]
+	return at, ok, nil
+}
+
+type idempotencyKey struct{}
+
+// ContextWithIdempotencyKey returns a copy of the given context carrying the given idempotency key, which identifies
+// the operation for deduplication. See WithDeduplication.
+func ContextWithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// idempotencyKeyFromContext returns the idempotency key of the given context, if any.
+func idempotencyKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKey{}).(string)
+	return key, ok
+}
+
+// WithDeduplication makes the retrier deduplicate attempts of operations with an idempotency key, set using
+// ContextWithIdempotencyKey. Successes are recorded in the given store. If a success for the key was observed within
+// the given window, including by another retry loop or process sharing the store, no more attempts are made and
+// ErrAlreadySucceeded is returned. This protects fire-and-forget APIs against duplicate side effects when a response is
+// lost but the operation actually succeeded.
+// Errors of the store are returned as is. Since successes end deduplicated operations, don't combine it with
+// RetryWithStop loops that keep going after a success.
+func WithDeduplication(store SuccessStore, window time.Duration) Option {
+	return func(cfg *config) {
+		cfg.successStore = store
+		cfg.dedupWindow = window
+	}
+}
+
+// checkDuplicate returns ErrAlreadySucceeded if a success for the idempotency key of the given context was observed
+// within the deduplication window.
+func (cfg *config) checkDuplicate(ctx context.Context, now time.Time) error {
+	if cfg.successStore == nil {
+		return nil
+	}
+	key, ok := idempotencyKeyFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	at, ok, err := cfg.successStore.LastSuccess(ctx, key)
+	if err != nil {
+		return err
+	}
+	if ok && now.Sub(at) < cfg.dedupWindow {
+		return ErrAlreadySucceeded
+	}
+	return nil
+}
+
+// recordSuccess records a success for the idempotency key of the given context, if any.
+func (cfg *config) recordSuccess(ctx context.Context, at time.Time) error {
+	if cfg.successStore == nil {
+		return nil
+	}
+	key, ok := idempotencyKeyFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return cfg.successStore.RecordSuccess(ctx, key, at)
+}
@@ -0,0 +1,106 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// failingSuccessStore is a success store of which all methods fail.
+type failingSuccessStore struct {
+	err error
+}
+
+func (s failingSuccessStore) RecordSuccess(context.Context, string, time.Time) error {
+	return s.err
+}
+
+func (s failingSuccessStore) LastSuccess(context.Context, string) (time.Time, bool, error) {
+	return time.Time{}, false, s.err
+}
+
+func TestWithDeduplication(t *testing.T) {
+	Convey("WithDeduplication()", t, func() {
+		store := NewMemorySuccessStore(time.Hour)
+		clock := &fakeClock{now: time.Now()}
+		retrier := NewNoDelayRetrier(WithDeduplication(store, time.Minute), WithClock(clock))
+		ctx := ContextWithIdempotencyKey(context.Background(), "order-1")
+		var numCalled int
+		succeed := func() error {
+			numCalled++
+			return nil
+		}
+
+		Convey("Records successes, and suppresses attempts within the window", func() {
+			So(retrier.RetryCtx(ctx, 3, succeed), ShouldBeNil)
+			So(numCalled, ShouldEqual, 1)
+
+			So(retrier.RetryCtx(ctx, 3, succeed), ShouldEqual, ErrAlreadySucceeded)
+			So(numCalled, ShouldEqual, 1)
+
+			Convey("Other keys are not affected", func() {
+				So(retrier.RetryCtx(ContextWithIdempotencyKey(context.Background(), "order-2"), 3, succeed), ShouldBeNil)
+				So(numCalled, ShouldEqual, 2)
+			})
+
+			Convey("After the window, attempts are made again", func() {
+				clock.now = clock.now.Add(time.Minute)
+				So(retrier.RetryCtx(ctx, 3, succeed), ShouldBeNil)
+				So(numCalled, ShouldEqual, 2)
+			})
+		})
+
+		Convey("Suppresses retries once a success is observed elsewhere", func() {
+			err := retrier.RetryCtx(ctx, 3, func() error {
+				numCalled++
+				// The response is lost, but the operation succeeded, which is recorded by e.g. a webhook.
+				_ = store.RecordSuccess(ctx, "order-1", clock.Now())
+				return errors.New("connection reset")
+			})
+			So(err, ShouldEqual, ErrAlreadySucceeded)
+			So(numCalled, ShouldEqual, 1)
+		})
+
+		Convey("Does not deduplicate without an idempotency key", func() {
+			So(retrier.Retry(3, succeed), ShouldBeNil)
+			So(retrier.Retry(3, succeed), ShouldBeNil)
+			So(numCalled, ShouldEqual, 2)
+		})
+
+		Convey("Returns errors of the store", func() {
+			errStore := errors.New("store unavailable")
+			retrier := NewNoDelayRetrier(WithDeduplication(failingSuccessStore{err: errStore}, time.Minute))
+			So(retrier.RetryCtx(ctx, 3, succeed), ShouldEqual, errStore)
+		})
+	})
+}
+
+func Test_MemorySuccessStore(t *testing.T) {
+	Convey("*MemorySuccessStore", t, func() {
+		store := NewMemorySuccessStore(time.Minute)
+		ctx := context.Background()
+		now := time.Now()
+
+		Convey("Returns the last success of a key", func() {
+			_, ok, err := store.LastSuccess(ctx, "foo")
+			So(err, ShouldBeNil)
+			So(ok, ShouldBeFalse)
+
+			So(store.RecordSuccess(ctx, "foo", now), ShouldBeNil)
+			at, ok, err := store.LastSuccess(ctx, "foo")
+			So(err, ShouldBeNil)
+			So(ok, ShouldBeTrue)
+			So(at, ShouldEqual, now)
+		})
+
+		Convey("Forgets successes after the TTL", func() {
+			So(store.RecordSuccess(ctx, "foo", now), ShouldBeNil)
+			So(store.RecordSuccess(ctx, "bar", now.Add(time.Minute)), ShouldBeNil)
+			_, ok, _ := store.LastSuccess(ctx, "foo")
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
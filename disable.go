@@ -0,0 +1,25 @@
+package retry
+
+import (
+	"sync/atomic"
+)
+
+// disabled is whether retrying is disabled process-wide.
+var disabled atomic.Bool
+
+// Disable disables retrying process-wide: from now on, every retry loop makes exactly one attempt and never sleeps,
+// until Enable is called. Use it in tests and local development, so that they aren't slowed down by production
+// backoff schedules.
+func Disable() {
+	disabled.Store(true)
+}
+
+// Enable enables retrying process-wide again after Disable was called.
+func Enable() {
+	disabled.Store(false)
+}
+
+// Disabled reports whether retrying is disabled process-wide using Disable.
+func Disabled() bool {
+	return disabled.Load()
+}
@@ -0,0 +1,56 @@
+package retry
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDisable(t *testing.T) {
+	Convey("Disable()", t, func() {
+		Disable()
+		defer Enable()
+		expectedErr := errors.New("foo")
+		var numCalled int
+
+		Convey("Makes every retry loop make exactly one attempt", func() {
+			So(Disabled(), ShouldBeTrue)
+			err := NewBackOffRetrier(time.Hour, 2).Retry(10, func() error {
+				numCalled++
+				return expectedErr
+			})
+			So(err, ShouldWrap, expectedErr)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(numCalled, ShouldEqual, 1)
+		})
+
+		Convey("Also for loops that retry forever", func() {
+			err := RetryWithStop(Forever, func(stop func()) error {
+				numCalled++
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(numCalled, ShouldEqual, 1)
+		})
+
+		Convey("Does not sleep after the last attempt", func() {
+			start := time.Now()
+			_ = RetryWithDelay(10, time.Hour, func() error {
+				return expectedErr
+			})
+			So(time.Since(start), ShouldBeLessThan, time.Second)
+		})
+
+		Convey("Enable() enables retrying again", func() {
+			Enable()
+			So(Disabled(), ShouldBeFalse)
+			_ = Retry(2, func() error {
+				numCalled++
+				return expectedErr
+			})
+			So(numCalled, ShouldEqual, 3)
+		})
+	})
+}
@@ -0,0 +1,164 @@
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+// Priority is the priority of the operations a retrier retries.
+type Priority int
+
+const (
+	// PriorityNormal operations keep retrying, at a reduced number of retries, when the error budget burns too fast.
+	PriorityNormal Priority = iota
+	// PriorityLow operations no longer retry at all when the error budget burns too fast.
+	PriorityLow
+)
+
+// BudgetMode is the mode an error budget is in.
+type BudgetMode int
+
+const (
+	// BudgetModeNormal means the error budget burns at an acceptable rate and retries are not suppressed.
+	BudgetModeNormal BudgetMode = iota
+	// BudgetModeDegraded means the error budget burns faster than the threshold and retries are suppressed.
+	BudgetModeDegraded
+)
+
+// String implements fmt.Stringer.
+func (m BudgetMode) String() string {
+	if m == BudgetModeDegraded {
+		return "degraded"
+	}
+	return "normal"
+}
+
+// numBudgetBuckets is the number of buckets the window of an error budget is divided into.
+const numBudgetBuckets = 10
+
+// ErrorBudget tracks the rate at which attempts burn through an error budget over a sliding window.
+//
+// The burn rate is the ratio of failed attempts in the window, divided by the allowed ratio of failed attempts. A burn
+// rate of 1 means the budget is used up exactly at the end of the window. When the burn rate exceeds the threshold,
+// the budget goes into degraded mode, in which retriers using it suppress retries depending on their priority.
+//
+// It is safe for concurrent use, and is meant to be shared by all retriers of the same operation or dependency.
+type ErrorBudget struct {
+	allowedErrorRatio  float64
+	burnRateThreshold  float64
+	degradedMaxRetries int
+	bucketDur          time.Duration
+	epoch              time.Time // The creation time, from which bucket indexes are measured using the monotonic clock.
+
+	mu      sync.Mutex
+	buckets [numBudgetBuckets]budgetBucket
+}
+
+type budgetBucket struct {
+	index    int64 // The number of bucket durations between the epoch and the start of the bucket.
+	attempts int
+	failures int
+}
+
+// ErrorBudgetStats contains statistics of an error budget.
+type ErrorBudgetStats struct {
+	// Mode is the mode the budget is currently in.
+	Mode BudgetMode
+	// BurnRate is the current burn rate.
+	BurnRate float64
+	// Attempts is the number of attempts in the current window.
+	Attempts int
+	// Failures is the number of failed attempts in the current window.
+	Failures int
+}
+
+// NewErrorBudget returns a new error budget allowing the given ratio of failed attempts (e.g. 0.01 for 99% success)
+// over the given window. When the burn rate exceeds the given threshold, retriers of normal priority retry at max
+// degradedMaxRetries times, and retriers of low priority don't retry at all.
+func NewErrorBudget(window time.Duration, allowedErrorRatio, burnRateThreshold float64, degradedMaxRetries int) *ErrorBudget {
+	bucketDur := window / numBudgetBuckets
+	if bucketDur <= 0 {
+		bucketDur = 1
+	}
+	return &ErrorBudget{
+		allowedErrorRatio:  allowedErrorRatio,
+		burnRateThreshold:  burnRateThreshold,
+		degradedMaxRetries: degradedMaxRetries,
+		bucketDur:          bucketDur,
+		epoch:              time.Now(),
+	}
+}
+
+// Record records the outcome of an attempt.
+func (b *ErrorBudget) Record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bucket := b.bucket(b.index())
+	bucket.attempts++
+	if failed {
+		bucket.failures++
+	}
+}
+
+// Stats returns the current statistics of the error budget.
+func (b *ErrorBudget) Stats() ErrorBudgetStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var stats ErrorBudgetStats
+	first := b.index() - numBudgetBuckets + 1
+	for _, bucket := range b.buckets {
+		if bucket.index >= first {
+			stats.Attempts += bucket.attempts
+			stats.Failures += bucket.failures
+		}
+	}
+	if stats.Attempts > 0 && b.allowedErrorRatio > 0 {
+		stats.BurnRate = float64(stats.Failures) / float64(stats.Attempts) / b.allowedErrorRatio
+	}
+	if stats.BurnRate > b.burnRateThreshold {
+		stats.Mode = BudgetModeDegraded
+	}
+	return stats
+}
+
+// maxRetries returns the maximum number of times an operation of the given priority may be retried, given the
+// number of times it should be retried if the budget were not degraded. Negative numbers mean retrying forever.
+func (b *ErrorBudget) maxRetries(numTimes int, priority Priority) int {
+	if b.Stats().Mode != BudgetModeDegraded {
+		return numTimes
+	}
+	if priority == PriorityLow {
+		return 0
+	}
+	if numTimes < 0 {
+		return b.degradedMaxRetries
+	}
+	return min(numTimes, b.degradedMaxRetries)
+}
+
+// index returns the index of the current bucket. It is measured using the monotonic clock, so that wall clock jumps
+// don't corrupt the window.
+func (b *ErrorBudget) index() int64 {
+	return int64(time.Since(b.epoch) / b.bucketDur)
+}
+
+// bucket returns the bucket with the given index, resetting it if it belongs to a previous window.
+// b.mu must be held.
+func (b *ErrorBudget) bucket(index int64) *budgetBucket {
+	bucket := &b.buckets[index%numBudgetBuckets]
+	if bucket.index != index {
+		*bucket = budgetBucket{index: index}
+	}
+	return bucket
+}
+
+// WithErrorBudget makes the retrier record the outcome of every attempt in the given error budget, and suppress
+// retries according to the given priority while the budget is degraded.
+func WithErrorBudget(budget *ErrorBudget, priority Priority) Option {
+	return func(cfg *config) {
+		cfg.budget = budget
+		cfg.priority = priority
+	}
+}
@@ -0,0 +1,91 @@
+package retry
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestErrorBudget(t *testing.T) {
+	Convey("ErrorBudget", t, func() {
+		budget := NewErrorBudget(time.Minute, 0.1, 2, 1)
+
+		Convey("Starts in normal mode", func() {
+			stats := budget.Stats()
+			So(stats.Mode, ShouldEqual, BudgetModeNormal)
+			So(stats.BurnRate, ShouldEqual, 0)
+		})
+
+		Convey("Computes the burn rate over the recorded attempts", func() {
+			for i := 0; i < 8; i++ {
+				budget.Record(false)
+			}
+			budget.Record(true)
+			budget.Record(true)
+
+			stats := budget.Stats()
+			So(stats.Attempts, ShouldEqual, 10)
+			So(stats.Failures, ShouldEqual, 2)
+			So(stats.BurnRate, ShouldAlmostEqual, 2)
+			So(stats.Mode, ShouldEqual, BudgetModeNormal)
+
+			budget.Record(true)
+			So(budget.Stats().Mode, ShouldEqual, BudgetModeDegraded)
+		})
+
+		Convey("Forgets attempts outside of the window", func() {
+			budget := NewErrorBudget(100*time.Millisecond, 0.1, 2, 1)
+			budget.Record(true)
+			So(budget.Stats().Failures, ShouldEqual, 1)
+			time.Sleep(120 * time.Millisecond)
+			So(budget.Stats().Failures, ShouldEqual, 0)
+		})
+	})
+}
+
+func TestWithErrorBudget(t *testing.T) {
+	Convey("WithErrorBudget()", t, func() {
+		budget := NewErrorBudget(time.Minute, 0.1, 2, 1)
+		cb := func() error {
+			return errors.New("foo")
+		}
+
+		Convey("Records the outcome of every attempt", func() {
+			budget := NewErrorBudget(time.Minute, 0.1, 100, 1)
+			retrier := NewBackOffRetrier(0, 1, WithErrorBudget(budget, PriorityNormal))
+			_ = retrier.Retry(2, cb)
+			So(budget.Stats().Attempts, ShouldEqual, 3)
+			So(budget.Stats().Failures, ShouldEqual, 3)
+		})
+
+		Convey("If the budget is degraded", func() {
+			for i := 0; i < 10; i++ {
+				budget.Record(true)
+			}
+
+			Convey("Reduces the number of retries of normal priority operations", func() {
+				var numCalled int
+				retrier := NewBackOffRetrier(0, 1, WithErrorBudget(budget, PriorityNormal))
+				err := retrier.Retry(5, func() error {
+					numCalled++
+					return cb()
+				})
+				So(err, ShouldWrap, ErrMaxRetriesExceeded)
+				So(numCalled, ShouldEqual, 2)
+			})
+
+			Convey("Disables retries of low priority operations", func() {
+				var numCalled int
+				retrier := NewBackOffRetrier(0, 1, WithErrorBudget(budget, PriorityLow))
+				err := retrier.Retry(5, func() error {
+					numCalled++
+					return cb()
+				})
+				So(err, ShouldWrap, ErrMaxRetriesExceeded)
+				So(numCalled, ShouldEqual, 1)
+			})
+		})
+	})
+}
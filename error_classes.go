@@ -0,0 +1,49 @@
+package retry
+
+import "errors"
+
+// OtherErrorClass is the class of the errors that don't belong to any other class.
+const OtherErrorClass = "other"
+
+// ErrorClass is a class of errors: the errors matching Target according to errors.Is.
+type ErrorClass struct {
+	// Label is the name of the class, like "timeout".
+	Label string
+	// Target is the error that errors of the class match.
+	Target error
+}
+
+// WithErrorClasses makes the retrier count the failed attempts per error class, as the ErrorClasses of its
+// statistics, so that operators can see what the retries are burned on, like timeouts vs. server errors vs. auth
+// failures. An error counts towards the first class it matches, or towards OtherErrorClass if it matches none.
+func WithErrorClasses(classes ...ErrorClass) Option {
+	return WithErrorLabels(func(err error) string {
+		for _, class := range classes {
+			if errors.Is(err, class.Target) {
+				return class.Label
+			}
+		}
+		return ""
+	})
+}
+
+// WithErrorLabels makes the retrier count the failed attempts per error class, as the ErrorClasses of its
+// statistics, with the class of an error given by the label the given function returns for it. This allows
+// classifying errors by anything, like their HTTP status code. Errors for which it returns "" count towards
+// OtherErrorClass.
+func WithErrorLabels(label func(err error) string) Option {
+	return func(cfg *config) {
+		cfg.errorLabel = label
+	}
+}
+
+// errorClass returns the class of the given error of an attempt, or "" if errors aren't classified.
+func (cfg *config) errorClass(err error) string {
+	if cfg.errorLabel == nil {
+		return ""
+	}
+	if label := cfg.errorLabel(err); label != "" {
+		return label
+	}
+	return OtherErrorClass
+}
@@ -0,0 +1,86 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithErrorClasses(t *testing.T) {
+	Convey("WithErrorClasses()", t, func() {
+		errTimeout := errors.New("timeout")
+		errAuth := errors.New("unauthorized")
+		errs := []error{
+			fmt.Errorf("dial: %w", errTimeout),
+			context.DeadlineExceeded,
+			errAuth,
+			errors.New("foo"),
+			nil,
+		}
+		var numCalled int
+		cb := func() error {
+			numCalled++
+			return errs[numCalled-1]
+		}
+
+		Convey("Counts the failed attempts per error class", func() {
+			retrier := NewNoDelayRetrier(WithErrorClasses(
+				ErrorClass{Label: "timeout", Target: errTimeout},
+				ErrorClass{Label: "timeout", Target: context.DeadlineExceeded},
+				ErrorClass{Label: "auth", Target: errAuth},
+			))
+			So(retrier.Retry(10, cb), ShouldBeNil)
+			So(retrier.Stats().ErrorClasses, ShouldResemble, map[string]int{"timeout": 2, "auth": 1, OtherErrorClass: 1})
+
+			Convey("ResetStats() resets the counts", func() {
+				retrier.ResetStats()
+				So(retrier.Stats().ErrorClasses, ShouldBeNil)
+			})
+		})
+
+		Convey("Counts an error towards the first class it matches", func() {
+			retrier := NewNoDelayRetrier(WithErrorClasses(
+				ErrorClass{Label: "auth", Target: errAuth},
+				ErrorClass{Label: "any auth", Target: errAuth},
+			))
+			_ = retrier.Retry(0, func() error { return errAuth })
+			So(retrier.Stats().ErrorClasses, ShouldResemble, map[string]int{"auth": 1})
+		})
+
+		Convey("Returns a copy of the counts", func() {
+			retrier := NewNoDelayRetrier(WithErrorClasses())
+			_ = retrier.Retry(0, func() error { return errAuth })
+			retrier.Stats().ErrorClasses[OtherErrorClass] = 10
+			So(retrier.Stats().ErrorClasses, ShouldResemble, map[string]int{OtherErrorClass: 1})
+		})
+
+		Convey("Without it, does not count errors", func() {
+			retrier := NewNoDelayRetrier()
+			So(retrier.Retry(10, cb), ShouldBeNil)
+			So(retrier.Stats().ErrorClasses, ShouldBeNil)
+		})
+	})
+
+	Convey("WithErrorLabels()", t, func() {
+		Convey("Counts the failed attempts per label", func() {
+			var numCalled int
+			retrier := NewNoDelayRetrier(WithErrorLabels(func(err error) string {
+				if err.Error() == "503" {
+					return "5xx"
+				}
+				return ""
+			}))
+			_ = retrier.Retry(2, func() error {
+				numCalled++
+				if numCalled == 2 {
+					return errors.New("foo")
+				}
+				return errors.New("503")
+			})
+			So(retrier.Stats().ErrorClasses, ShouldResemble, map[string]int{"5xx": 2, OtherErrorClass: 1})
+		})
+	})
+}
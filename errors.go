@@ -0,0 +1,128 @@
+package retry
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrMaxRetriesExceeded is returned when the maximum number of retries is reached without success.
+// It is always returned as an *Error, which wraps the error of the last attempt. The last error can be retrieved
+// using errors.Unwrap, errors.Is or errors.As.
+var ErrMaxRetriesExceeded = errors.New("max retries exceeded")
+
+// ErrCostBudgetExceeded is returned when the cost budget of a retry loop is used up without success.
+// It is always returned as an *Error, which wraps the error of the last attempt.
+var ErrCostBudgetExceeded = errors.New("cost budget exceeded")
+
+// ErrMaxElapsedTimeExceeded is returned when the maximum elapsed time of a retry loop is reached without success.
+// It is always returned as an *Error, which wraps the error of the last attempt.
+var ErrMaxElapsedTimeExceeded = errors.New("max elapsed time exceeded")
+
+// ErrInvalidConfig is returned when a retrier is configured with invalid settings.
+var ErrInvalidConfig = errors.New("invalid retrier config")
+
+// Error is the error returned when a retrier gives up without success, because the maximum number of retries is
+// reached or because a budget is used up. Use errors.Is to find out which: it matches ErrMaxRetriesExceeded,
+// ErrCostBudgetExceeded, etc.
+type Error struct {
+	// Operation is the name of the operation that was retried, as set using WithName. It is empty if no name was set.
+	Operation string
+	// Reason is why the retrier gave up: ReasonMaxAttempts, ReasonBudgetExhausted or ReasonContextDone.
+	Reason Reason
+	// NumAttempts is the number of attempts that were made.
+	NumAttempts int
+	// TotalElapsed is the time that passed since the first attempt started.
+	TotalElapsed time.Duration
+	// TotalSlept is the time spent sleeping between attempts.
+	TotalSlept time.Duration
+	// Errors contains the errors returned by the attempts that failed, in order.
+	// For retry loops that retry forever, only the most recent errors are kept.
+	Errors []error
+
+	sentinel error // The sentinel error describing why the retrier gave up.
+	joined   bool  // Whether to unwrap to all errors joined, instead of to the last error.
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	msg := e.sentinelErr().Error()
+	if e.Operation != "" {
+		msg = e.Operation + ": " + msg
+	}
+	if err := e.Unwrap(); err != nil {
+		return msg + ": " + err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the error of the last failed attempt, or the errors of all attempts joined using errors.Join if the
+// WithJoinedErrors option was used.
+func (e *Error) Unwrap() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	if e.joined {
+		return errors.Join(e.Errors...)
+	}
+	return e.Errors[len(e.Errors)-1]
+}
+
+// Is reports whether target is the sentinel error describing why the retrier gave up.
+func (e *Error) Is(target error) bool {
+	return target == e.sentinelErr()
+}
+
+// sentinelErr returns the sentinel error describing why the retrier gave up.
+func (e *Error) sentinelErr() error {
+	if e.sentinel == nil {
+		return ErrMaxRetriesExceeded
+	}
+	return e.sentinel
+}
+
+// AttemptRecord describes an attempt.
+type AttemptRecord struct {
+	// Attempt is the number of the attempt, counting from 1.
+	Attempt int
+	// Time is the time the attempt started. It carries a reading of the monotonic clock when the real clock is used, so
+	// subtracting the times of two attempts yields the time between them, even if the wall clock jumped.
+	Time time.Time
+	// Offset is the time between the start of the first attempt and the start of this attempt, measured using the
+	// monotonic clock.
+	Offset time.Duration
+	// Duration is the time the attempt took.
+	Duration time.Duration
+	// Err is the error returned by the attempt.
+	Err error
+	// Fields contains the key/value pairs recorded for the attempt using WithAttemptFields, or nil if none were.
+	Fields map[string]string
+}
+
+// AttemptsError is the error returned instead of an *Error when the WithAttemptHistory option is used. It contains a
+// record of every attempt.
+//
+// It unwraps to the *Error and to the errors of all attempts that failed, so errors.Is and errors.As match any of
+// them.
+type AttemptsError struct {
+	// Attempts contains a record of every attempt, in order.
+	// For retry loops that retry forever, only the most recent attempts are kept.
+	Attempts []AttemptRecord
+
+	err *Error
+}
+
+// Error implements error.
+func (e *AttemptsError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the *Error, followed by the errors of all attempts that failed.
+func (e *AttemptsError) Unwrap() []error {
+	errs := []error{e.err}
+	for _, attempt := range e.Attempts {
+		if attempt.Err != nil {
+			errs = append(errs, attempt.Err)
+		}
+	}
+	return errs
+}
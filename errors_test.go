@@ -0,0 +1,162 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type testErr struct {
+	code int
+}
+
+func (e *testErr) Error() string {
+	return "test error"
+}
+
+func TestErrMaxRetriesExceeded(t *testing.T) {
+	Convey("ErrMaxRetriesExceeded", t, func() {
+		lastErr := &testErr{code: 500}
+		err := Retry(1, func() error {
+			return lastErr
+		})
+
+		Convey("Is wrapped by the returned error", func() {
+			So(errors.Is(err, ErrMaxRetriesExceeded), ShouldBeTrue)
+		})
+
+		Convey("Wraps the last error", func() {
+			So(errors.Unwrap(err), ShouldEqual, lastErr)
+
+			var target *testErr
+			So(errors.As(err, &target), ShouldBeTrue)
+			So(target.code, ShouldEqual, 500)
+		})
+
+		Convey("Includes the last error in the message", func() {
+			So(err.Error(), ShouldEqual, "max retries exceeded: test error")
+		})
+
+		Convey("Is not returned if the last attempt succeeded", func() {
+			err := RetryWithStop(1, func(stop func()) error {
+				return nil
+			})
+			So(err, ShouldBeNil)
+		})
+	})
+}
+
+func TestError(t *testing.T) {
+	Convey("*Error", t, func() {
+		errs := []error{errors.New("foo"), errors.New("bar"), errors.New("baz")}
+		var numCalled int
+		err := NewBackOffRetrier(5*time.Millisecond, 1).Retry(2, func() error {
+			numCalled++
+			return errs[numCalled-1]
+		})
+
+		var retryErr *Error
+		So(errors.As(err, &retryErr), ShouldBeTrue)
+
+		Convey("Contains the number of attempts", func() {
+			So(retryErr.NumAttempts, ShouldEqual, 3)
+		})
+
+		Convey("Contains the errors of all attempts", func() {
+			So(retryErr.Errors, ShouldResemble, errs)
+		})
+
+		Convey("Contains the time elapsed and slept", func() {
+			So(retryErr.TotalSlept, ShouldBeGreaterThanOrEqualTo, 10*time.Millisecond)
+			So(retryErr.TotalElapsed, ShouldBeGreaterThanOrEqualTo, retryErr.TotalSlept)
+		})
+
+		Convey("Unwraps to the last error", func() {
+			So(errors.Unwrap(err), ShouldEqual, errs[2])
+			So(err.Error(), ShouldEqual, "max retries exceeded: baz")
+		})
+	})
+}
+
+func TestAttemptsError(t *testing.T) {
+	Convey("*AttemptsError", t, func() {
+		errFoo := errors.New("foo")
+		errBar := &testErr{code: 503}
+		errs := []error{errFoo, nil, errBar}
+		var numCalled int
+		startTime := time.Now()
+		err := NewBackOffRetrier(0, 1, WithAttemptHistory()).RetryWithStop(2, func(stop func()) error {
+			numCalled++
+			time.Sleep(time.Millisecond)
+			return errs[numCalled-1]
+		})
+
+		var attemptsErr *AttemptsError
+		So(errors.As(err, &attemptsErr), ShouldBeTrue)
+
+		Convey("Contains a record of every attempt", func() {
+			So(attemptsErr.Attempts, ShouldHaveLength, 3)
+			for i, attempt := range attemptsErr.Attempts {
+				So(attempt.Attempt, ShouldEqual, i+1)
+				So(attempt.Err, ShouldEqual, errs[i])
+				So(attempt.Time, ShouldHappenOnOrAfter, startTime)
+				So(attempt.Duration, ShouldBeGreaterThanOrEqualTo, time.Millisecond)
+			}
+			So(attemptsErr.Attempts[1].Time, ShouldHappenAfter, attemptsErr.Attempts[0].Time)
+		})
+
+		Convey("Records the offset of every attempt from the first one", func() {
+			So(attemptsErr.Attempts[0].Offset, ShouldBeLessThan, time.Millisecond)
+			for i := 1; i < len(attemptsErr.Attempts); i++ {
+				So(attemptsErr.Attempts[i].Offset, ShouldBeGreaterThanOrEqualTo, time.Duration(i)*time.Millisecond)
+			}
+		})
+
+		Convey("Unwraps to the *Error and the errors of all failed attempts", func() {
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(err, ShouldWrap, errFoo)
+			So(err, ShouldWrap, errBar)
+
+			var retryErr *Error
+			So(errors.As(err, &retryErr), ShouldBeTrue)
+			So(retryErr.NumAttempts, ShouldEqual, 3)
+			So(attemptsErr.Unwrap(), ShouldResemble, []error{retryErr, errFoo, errBar})
+		})
+
+		Convey("Has the message of the *Error", func() {
+			So(err.Error(), ShouldEqual, "max retries exceeded: test error")
+		})
+
+		Convey("Has no fields without WithAttemptFields()", func() {
+			So(attemptsErr.Attempts[0].Fields, ShouldBeNil)
+		})
+	})
+
+	Convey("WithAttemptFields()", t, func() {
+		type requestKey struct{}
+		ctx := context.WithValue(context.Background(), requestKey{}, "req-1")
+		replicas := []string{"a", "b"}
+		var replica string
+		retrier := NewNoDelayRetrier(WithAttemptHistory(), WithAttemptFields(func(ctx context.Context, attempt int, err error) map[string]string {
+			return map[string]string{"replica": replica, "request": ctx.Value(requestKey{}).(string)}
+		}))
+		var numCalled int
+		err := retrier.RetryCtx(ctx, 2, func() error {
+			replica = replicas[numCalled%len(replicas)]
+			numCalled++
+			return errors.New("foo")
+		})
+
+		Convey("Records the fields of every attempt", func() {
+			var attemptsErr *AttemptsError
+			So(errors.As(err, &attemptsErr), ShouldBeTrue)
+			So(attemptsErr.Attempts, ShouldHaveLength, 3)
+			So(attemptsErr.Attempts[0].Fields, ShouldResemble, map[string]string{"replica": "a", "request": "req-1"})
+			So(attemptsErr.Attempts[1].Fields, ShouldResemble, map[string]string{"replica": "b", "request": "req-1"})
+			So(attemptsErr.Attempts[2].Fields, ShouldResemble, map[string]string{"replica": "a", "request": "req-1"})
+		})
+	})
+}
@@ -0,0 +1,110 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Event is an event of a retry loop, streamed by RetryEvents. It is one of AttemptStarted, AttemptFailed, Succeeded
+// and Exhausted.
+type Event interface {
+	isEvent()
+}
+
+// AttemptStarted is sent when an attempt starts.
+type AttemptStarted struct {
+	// Attempt is the number of the attempt, counting from 1.
+	Attempt int
+}
+
+// AttemptFailed is sent when an attempt failed and is about to be retried.
+type AttemptFailed struct {
+	// Attempt is the number of the failed attempt, counting from 1.
+	Attempt int
+	// Err is the error of the attempt.
+	Err error
+	// Delay is the delay before the next attempt.
+	Delay time.Duration
+}
+
+// Succeeded is sent when the retry loop ended without an error. It is the last event.
+type Succeeded struct {
+	// NumAttempts is the number of attempts that were made.
+	NumAttempts int
+	// Reason is why the loop ended.
+	Reason Reason
+}
+
+// Exhausted is sent when the retry loop ended with an error, e.g. because the maximum number of retries was reached. It
+// is the last event.
+type Exhausted struct {
+	// NumAttempts is the number of attempts that were made.
+	NumAttempts int
+	// Reason is why the loop ended.
+	Reason Reason
+	// Err is the error returned by the retry loop.
+	Err error
+}
+
+func (AttemptStarted) isEvent() {}
+func (AttemptFailed) isEvent()  {}
+func (Succeeded) isEvent()      {}
+func (Exhausted) isEvent()      {}
+
+type eventsKey struct{}
+
+// eventSink receives the events of a retry loop, as passed through its context by RetryEvents.
+type eventSink struct {
+	ch    chan Event
+	done  <-chan struct{}
+	ended bool // Whether the last event was sent.
+}
+
+// eventSinkFromContext returns the event sink in the given context, or nil if there is none.
+func eventSinkFromContext(ctx context.Context) *eventSink {
+	sink, _ := ctx.Value(eventsKey{}).(*eventSink)
+	return sink
+}
+
+// send sends the given event, unless the context of the retry loop is done first. It does nothing on a nil sink.
+func (s *eventSink) send(event Event) {
+	if s == nil {
+		return
+	}
+	select {
+	case s.ch <- event:
+	case <-s.done:
+	}
+}
+
+// end sends the last event, always. It does nothing on a nil sink, or if the last event was sent before.
+func (s *eventSink) end(numAttempts int, reason Reason, err error) {
+	if s == nil || s.ended {
+		return
+	}
+	s.ended = true
+	if err == nil {
+		s.ch <- Succeeded{NumAttempts: numAttempts, Reason: reason}
+		return
+	}
+	s.ch <- Exhausted{NumAttempts: numAttempts, Reason: reason, Err: err}
+}
+
+// RetryEvents retries the given callback at max the given number of times using the given retryer, in a goroutine of
+// its own, and returns a channel on which the events of the retry loop are streamed, like for progress UIs and tests.
+// It stops as soon as a `nil` error is returned. The last event is Succeeded or Exhausted, which carries the error
+// returned by the retryer, after which the channel is closed.
+//
+// The channel must be drained until it is closed. Once the context is done, events other than the last are dropped.
+// Retryers that don't build on the retriers of this package only stream the last event.
+func RetryEvents(ctx context.Context, r Retryer, numTimes int, cb func() error) <-chan Event {
+	sink := &eventSink{ch: make(chan Event), done: ctx.Done()}
+	go func() {
+		defer close(sink.ch)
+		err := r.RetryCtx(context.WithValue(ctx, eventsKey{}, sink), numTimes, cb)
+		if !sink.ended {
+			sink.end(0, endReason(ctx, err, false), err)
+		}
+	}()
+	return sink.ch
+}
@@ -0,0 +1,98 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fakeRetryer is a Retryer that calls the callback once.
+type fakeRetryer struct {
+	Retryer
+}
+
+func (fakeRetryer) RetryCtx(_ context.Context, _ int, cb func() error) error {
+	return cb()
+}
+
+func TestRetryEvents(t *testing.T) {
+	Convey("RetryEvents()", t, func() {
+		ctx := context.Background()
+		retrier := NewConstantDelayRetrier(time.Second, WithClock(&fakeClock{now: time.Unix(0, 0)}))
+		expectedErr := errors.New("foo")
+		collect := func(events <-chan Event) []Event {
+			var all []Event
+			for event := range events {
+				all = append(all, event)
+			}
+			return all
+		}
+
+		Convey("Streams the events of a successful retry loop", func() {
+			var numCalled int
+			events := RetryEvents(ctx, retrier, 5, func() error {
+				numCalled++
+				if numCalled < 2 {
+					return expectedErr
+				}
+				return nil
+			})
+			So(collect(events), ShouldResemble, []Event{
+				AttemptStarted{Attempt: 1},
+				AttemptFailed{Attempt: 1, Err: expectedErr, Delay: time.Second},
+				AttemptStarted{Attempt: 2},
+				Succeeded{NumAttempts: 2, Reason: ReasonSucceeded},
+			})
+		})
+
+		Convey("Streams the events of an exhausted retry loop", func() {
+			all := collect(RetryEvents(ctx, retrier, 1, func() error {
+				return expectedErr
+			}))
+			So(all, ShouldHaveLength, 4)
+			So(all[:3], ShouldResemble, []Event{
+				AttemptStarted{Attempt: 1},
+				AttemptFailed{Attempt: 1, Err: expectedErr, Delay: time.Second},
+				AttemptStarted{Attempt: 2},
+			})
+			exhausted, ok := all[3].(Exhausted)
+			So(ok, ShouldBeTrue)
+			So(exhausted.NumAttempts, ShouldEqual, 2)
+			So(exhausted.Reason, ShouldEqual, ReasonMaxAttempts)
+			So(exhausted.Err, ShouldWrap, ErrMaxRetriesExceeded)
+		})
+
+		Convey("Only sends the last event once the context is done", func() {
+			ctx, cancel := context.WithCancel(ctx)
+			cancel()
+			So(collect(RetryEvents(ctx, retrier, 1, func() error {
+				return nil
+			})), ShouldResemble, []Event{
+				Exhausted{Reason: ReasonContextDone, Err: context.Canceled},
+			})
+		})
+
+		Convey("Only streams the last event of other retryers", func() {
+			So(collect(RetryEvents(ctx, fakeRetryer{}, 1, func() error {
+				return expectedErr
+			})), ShouldResemble, []Event{
+				Exhausted{Reason: ReasonPermanentError, Err: expectedErr},
+			})
+		})
+
+		Convey("Does not stream the events of other retry loops", func() {
+			var numInner int
+			all := collect(RetryEvents(ctx, retrier, 0, func() error {
+				return retrier.RetryCtx(ctx, 0, func() error {
+					numInner++
+					return nil
+				})
+			}))
+			So(numInner, ShouldEqual, 1)
+			So(all, ShouldResemble, []Event{AttemptStarted{Attempt: 1}, Succeeded{NumAttempts: 1, Reason: ReasonSucceeded}})
+		})
+	})
+}
@@ -0,0 +1,66 @@
+package retry
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrFallbackExhausted is returned by Fallback when all alternatives exhausted their retries.
+var ErrFallbackExhausted = errors.New("all alternatives exhausted their retries")
+
+// Alternative is a way of performing an operation, like reading from a region or from a cache.
+type Alternative struct {
+	// Name identifies the alternative, e.g. "eu-west-1" or "cache".
+	Name string
+	// Policy is the policy to retry Do according to. If nil, Do is attempted once.
+	Policy *Policy
+	// Do performs the operation. Every attempt receives a context carrying the attempt, which can be retrieved using
+	// AttemptFromContext.
+	Do func(ctx context.Context) error
+}
+
+// Fallback performs an operation using the primary alternative, retrying it according to its policy, and moves on to
+// the next alternative once the retries of an alternative are exhausted, e.g. to fail over to another region, or to
+// fall back from a cache to the origin. It returns the name of the alternative that succeeded.
+//
+// It runs a ladder with a rung for every alternative, but unlike a plain Ladder, it does not move on if an alternative
+// fails permanently, e.g. because a classifier aborted its retries, but returns that error as is, since the other
+// alternatives would fail likewise. An alternative is exhausted once it gave up, also when its retry budget is
+// depleted, and an alternative without a policy is exhausted as soon as it fails. If all alternatives are exhausted, it
+// returns an error matching ErrFallbackExhausted and the errors of all alternatives. If the context is done, it returns
+// the cause of the context without moving on.
+func Fallback(ctx context.Context, primary Alternative, secondaries ...Alternative) (string, error) {
+	alts := append([]Alternative{primary}, secondaries...)
+	reasons := make([]Reason, len(alts)) // Why the retry loop of every alternative that was tried ended.
+	ladder := &Ladder{
+		rungs: make([]Rung, len(alts)),
+		moveOn: func(i int, err error) bool {
+			return alts[i].Policy == nil || reasons[i] != ReasonPermanentError
+		},
+	}
+	for i, alt := range alts {
+		ladder.rungs[i] = Rung{Name: alt.Name, Do: func(ctx context.Context) error {
+			return alt.do(ctx, &reasons[i])
+		}}
+	}
+	i, errs, err := ladder.run(ctx)
+	if err != nil {
+		return "", err
+	}
+	if i >= 0 {
+		return alts[i].Name, nil
+	}
+	return "", ladder.exhaustedError(ErrFallbackExhausted, "alternative", errs)
+}
+
+// do performs the operation using the alternative, and sets reason to the reason its retry loop ended.
+func (alt Alternative) do(ctx context.Context, reason *Reason) error {
+	if alt.Policy == nil {
+		return withAttemptContext(ctx, alt.Do)()
+	}
+	return alt.Policy.With(func(cfg *config) {
+		cfg.afterEnd = func(r Reason) {
+			*reason = r
+		}
+	}).Do(ctx, alt.Do)
+}
@@ -0,0 +1,80 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFallback(t *testing.T) {
+	Convey("Fallback()", t, func() {
+		policy := NewPolicy().InitialDelay(0).MaxAttempts(2).Build()
+		expectedErr := errors.New("foo")
+		var calls []string
+		alternative := func(name string, policy *Policy, err error) Alternative {
+			return Alternative{Name: name, Policy: policy, Do: func(ctx context.Context) error {
+				attempt, _ := AttemptFromContext(ctx)
+				calls = append(calls, fmt.Sprintf("%s#%d", name, attempt.Number))
+				return err
+			}}
+		}
+
+		Convey("Returns the name of the primary if it succeeds", func() {
+			name, err := Fallback(context.Background(), alternative("primary", &policy, nil), alternative("secondary", &policy, nil))
+			So(err, ShouldBeNil)
+			So(name, ShouldEqual, "primary")
+			So(calls, ShouldResemble, []string{"primary#1"})
+		})
+
+		Convey("Moves on to the next alternative once the retries of one are exhausted", func() {
+			name, err := Fallback(context.Background(),
+				alternative("primary", &policy, expectedErr),
+				alternative("cache", nil, expectedErr),
+				alternative("origin", &policy, nil),
+			)
+			So(err, ShouldBeNil)
+			So(name, ShouldEqual, "origin")
+			So(calls, ShouldResemble, []string{"primary#1", "primary#2", "cache#1", "origin#1"})
+		})
+
+		Convey("Returns an error matching ErrFallbackExhausted if all alternatives are exhausted", func() {
+			otherErr := errors.New("bar")
+			_, err := Fallback(context.Background(), alternative("primary", &policy, expectedErr), alternative("secondary", nil, otherErr))
+			So(err, ShouldWrap, ErrFallbackExhausted)
+			So(err, ShouldWrap, expectedErr)
+			So(err, ShouldWrap, otherErr)
+			So(err.Error(), ShouldContainSubstring, `alternative "secondary": bar`)
+		})
+
+		Convey("Moves on to the next alternative once the retry budget of one is depleted", func() {
+			budgeted := policy.With(WithBudget(NewBudget(0, 0)))
+			name, err := Fallback(context.Background(), alternative("primary", &budgeted, expectedErr), alternative("secondary", &policy, nil))
+			So(err, ShouldBeNil)
+			So(name, ShouldEqual, "secondary")
+			So(calls, ShouldResemble, []string{"primary#1", "secondary#1"})
+		})
+
+		Convey("Does not move on if an alternative fails permanently", func() {
+			abort := policy.With(WithStopOnError(expectedErr))
+			_, err := Fallback(context.Background(), alternative("primary", &abort, expectedErr), alternative("secondary", &policy, nil))
+			So(err, ShouldEqual, expectedErr)
+			So(calls, ShouldResemble, []string{"primary#1"})
+		})
+
+		Convey("Returns the cause of the context without moving on if it is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			primary := alternative("primary", &policy, expectedErr)
+			do := primary.Do
+			primary.Do = func(ctx context.Context) error {
+				cancel()
+				return do(ctx)
+			}
+			_, err := Fallback(ctx, primary, alternative("secondary", &policy, nil))
+			So(err, ShouldEqual, context.Canceled)
+			So(calls, ShouldResemble, []string{"primary#1"})
+		})
+	})
+}
@@ -0,0 +1,203 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// GroupOption configures a Group.
+type GroupOption func(*Group)
+
+// WithGroupPolicy sets the policy to retry the tasks of the group according to, unless a task sets a policy of its
+// own using WithPolicy. Defaults to the policy returned by NewPolicy.
+func WithGroupPolicy(policy Policy) GroupOption {
+	return func(g *Group) {
+		g.policy = policy
+	}
+}
+
+// WithGroupBudget makes all tasks of the group share the given retry budget, so that a fan-out to a flaky backend
+// doesn't multiply its load by the number of retries of every task.
+func WithGroupBudget(budget *Budget) GroupOption {
+	return func(g *Group) {
+		g.budget = budget
+	}
+}
+
+// WithGroupLimit limits the number of tasks running at once to the given number. Go blocks until a task can be
+// started. A number below 1 means no limit.
+func WithGroupLimit(n int) GroupOption {
+	return func(g *Group) {
+		if n > 0 {
+			g.sem = make(chan struct{}, n)
+		}
+	}
+}
+
+// WithFailFast makes the group cancel the context of all tasks as soon as a task fails, i.e. its retries were
+// exhausted or aborted, like an errgroup.Group does.
+func WithFailFast() GroupOption {
+	return func(g *Group) {
+		g.failFast = true
+	}
+}
+
+// TaskOption configures a task of a Group.
+type TaskOption func(*groupTask)
+
+// groupTask holds the settings of a task of a Group.
+type groupTask struct {
+	name   string
+	policy *Policy
+}
+
+// WithPolicy makes the task retry according to the given policy, instead of the policy of the group.
+func WithPolicy(policy Policy) TaskOption {
+	return func(t *groupTask) {
+		t.policy = &policy
+	}
+}
+
+// WithTaskName sets the name identifying the task in the results of the group.
+func WithTaskName(name string) TaskOption {
+	return func(t *groupTask) {
+		t.name = name
+	}
+}
+
+// GroupTask describes the outcome of a task of a Group.
+type GroupTask struct {
+	// Name is the name of the task, as set using WithTaskName.
+	Name string
+	// NumAttempts is the number of attempts made for the task.
+	NumAttempts int
+	// Err is the error returned by the retry loop of the task, or nil if it succeeded.
+	Err error
+}
+
+// Group runs tasks in goroutines, like an errgroup.Group, retrying every task according to a policy of its own, for
+// fan-out calls to flaky backends. Unless WithFailFast is used, the failure of a task does not affect the others.
+// A Group must not be reused after Wait returned.
+type Group struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	policy   Policy
+	budget   *Budget
+	sem      chan struct{}
+	failFast bool
+	wg       sync.WaitGroup
+
+	mu    sync.Mutex
+	tasks []GroupTask
+}
+
+// NewGroup returns a new group. The tasks receive child contexts of the given context.
+func NewGroup(ctx context.Context, opts ...GroupOption) *Group {
+	g := &Group{policy: NewPolicy().Build()}
+	for _, opt := range opts {
+		opt(g)
+	}
+	g.ctx, g.cancel = context.WithCancel(ctx)
+	return g
+}
+
+// Go runs the given task in a new goroutine, retrying it according to the policy of the group, or the one set using
+// WithPolicy. Every attempt receives a context carrying the attempt, which can be retrieved using AttemptFromContext.
+func (g *Group) Go(fn func(ctx context.Context) error, opts ...TaskOption) {
+	var task groupTask
+	for _, opt := range opts {
+		opt(&task)
+	}
+	policy := g.policy
+	if task.policy != nil {
+		policy = *task.policy
+	}
+	if g.budget != nil {
+		policy = policy.With(WithBudget(g.budget))
+	}
+
+	g.mu.Lock()
+	index := len(g.tasks)
+	g.tasks = append(g.tasks, GroupTask{Name: task.name})
+	g.mu.Unlock()
+
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if g.sem != nil {
+			defer func() { <-g.sem }()
+		}
+		var numAttempts int
+		err := policy.Do(g.ctx, func(ctx context.Context) error {
+			numAttempts++
+			return fn(ctx)
+		})
+		if err != nil && g.failFast {
+			g.cancel()
+		}
+
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		g.tasks[index].NumAttempts = numAttempts
+		g.tasks[index].Err = err
+	}()
+}
+
+// Wait blocks until all tasks are done. It returns nil if all tasks succeeded, and a *GroupError describing the
+// outcome of every task otherwise.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+	tasks := g.Tasks()
+	for _, task := range tasks {
+		if task.Err != nil {
+			return &GroupError{Tasks: tasks}
+		}
+	}
+	return nil
+}
+
+// Tasks returns the outcome of every task so far, in the order they were started in.
+func (g *Group) Tasks() []GroupTask {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]GroupTask(nil), g.tasks...)
+}
+
+// GroupError is the error returned by Group.Wait when a task failed. It preserves the outcome of every task, and
+// unwraps to the errors of all tasks that failed, so errors.Is and errors.As match any of them.
+type GroupError struct {
+	// Tasks contains the outcome of every task, in the order they were started in.
+	Tasks []GroupTask
+}
+
+// Error implements error.
+func (e *GroupError) Error() string {
+	failed := e.Failed()
+	return fmt.Sprintf("%d of %d tasks failed: %s", len(failed), len(e.Tasks), errors.Join(e.Unwrap()...))
+}
+
+// Unwrap returns the errors of all tasks that failed, in the order they were started in.
+func (e *GroupError) Unwrap() []error {
+	var errs []error
+	for _, task := range e.Failed() {
+		errs = append(errs, task.Err)
+	}
+	return errs
+}
+
+// Failed returns the outcome of the tasks that failed, in the order they were started in.
+func (e *GroupError) Failed() []GroupTask {
+	var failed []GroupTask
+	for _, task := range e.Tasks {
+		if task.Err != nil {
+			failed = append(failed, task)
+		}
+	}
+	return failed
+}
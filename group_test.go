@@ -0,0 +1,123 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGroup(t *testing.T) {
+	Convey("Group", t, func() {
+		policy := NewPolicy().InitialDelay(0).MaxAttempts(3).Build()
+		expectedErr := errors.New("foo")
+
+		Convey("Retries every task according to its own policy", func() {
+			group := NewGroup(context.Background(), WithGroupPolicy(policy))
+			var numA, numB atomic.Int32
+			group.Go(func(ctx context.Context) error {
+				if numA.Add(1) < 3 {
+					return expectedErr
+				}
+				return nil
+			}, WithTaskName("a"))
+			group.Go(func(ctx context.Context) error {
+				numB.Add(1)
+				return expectedErr
+			}, WithTaskName("b"), WithPolicy(policy.With(WithMaxAttempts(2))))
+			err := group.Wait()
+
+			var groupErr *GroupError
+			So(errors.As(err, &groupErr), ShouldBeTrue)
+			So(err, ShouldWrap, expectedErr)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(err.Error(), ShouldStartWith, "1 of 2 tasks failed: ")
+			So(groupErr.Tasks, ShouldHaveLength, 2)
+			So(groupErr.Tasks[0], ShouldResemble, GroupTask{Name: "a", NumAttempts: 3})
+			So(groupErr.Failed(), ShouldHaveLength, 1)
+			So(groupErr.Failed()[0].Name, ShouldEqual, "b")
+			So(groupErr.Failed()[0].NumAttempts, ShouldEqual, 2)
+		})
+
+		Convey("Wait() returns nil if all tasks succeed", func() {
+			group := NewGroup(context.Background())
+			var attempt Attempt
+			group.Go(func(ctx context.Context) error {
+				attempt, _ = AttemptFromContext(ctx)
+				return nil
+			})
+			So(group.Wait(), ShouldBeNil)
+			So(attempt.Number, ShouldEqual, 1)
+			So(group.Tasks(), ShouldResemble, []GroupTask{{NumAttempts: 1}})
+		})
+
+		Convey("Shares the budget between all tasks", func() {
+			group := NewGroup(context.Background(), WithGroupPolicy(policy.With(WithMaxAttempts(10))), WithGroupBudget(NewBudget(0, 3)))
+			var numCalled atomic.Int32
+			for i := 0; i < 3; i++ {
+				group.Go(func(ctx context.Context) error {
+					numCalled.Add(1)
+					return expectedErr
+				})
+			}
+			So(group.Wait(), ShouldWrap, expectedErr)
+			So(numCalled.Load(), ShouldEqual, 6) // 3 first attempts and 3 retries.
+		})
+
+		Convey("Runs at max the given number of tasks at once", func() {
+			group := NewGroup(context.Background(), WithGroupLimit(2))
+			var running, maxRunning atomic.Int32
+			for i := 0; i < 10; i++ {
+				group.Go(func(ctx context.Context) error {
+					n := running.Add(1)
+					defer running.Add(-1)
+					for {
+						prev := maxRunning.Load()
+						if n <= prev || maxRunning.CompareAndSwap(prev, n) {
+							break
+						}
+					}
+					time.Sleep(time.Millisecond)
+					return nil
+				})
+			}
+			So(group.Wait(), ShouldBeNil)
+			So(maxRunning.Load(), ShouldBeBetweenOrEqual, 1, 2)
+		})
+
+		Convey("Does not cancel the other tasks if one fails", func() {
+			group := NewGroup(context.Background(), WithGroupPolicy(policy))
+			failed := make(chan struct{})
+			var ctxErr error
+			group.Go(func(ctx context.Context) error {
+				defer close(failed)
+				return expectedErr
+			}, WithPolicy(policy.With(WithMaxAttempts(1))))
+			group.Go(func(ctx context.Context) error {
+				<-failed
+				time.Sleep(time.Millisecond)
+				ctxErr = ctx.Err()
+				return nil
+			})
+			So(group.Wait(), ShouldWrap, expectedErr)
+			So(ctxErr, ShouldBeNil)
+		})
+
+		Convey("With WithFailFast(), cancels the other tasks as soon as one fails", func() {
+			group := NewGroup(context.Background(), WithGroupPolicy(policy), WithFailFast())
+			group.Go(func(ctx context.Context) error {
+				return expectedErr
+			}, WithPolicy(policy.With(WithMaxAttempts(1))))
+			group.Go(func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			})
+			err := group.Wait()
+			So(err, ShouldWrap, expectedErr)
+			So(err, ShouldWrap, context.Canceled)
+		})
+	})
+}
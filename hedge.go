@@ -0,0 +1,183 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Hedge calls the given callback, and calls it again in parallel whenever the attempts in flight don't finish within
+// the delay, until one of them succeeds, according to the given policy: the policy decides the maximum number of
+// attempts, and the delays after which the second, third, etc. attempts are started, which grow like the delays
+// between its retries. An attempt that fails starts the next one right away, like a retry would.
+//
+// As the attempts run in parallel rather than in a retry loop, hedging supports only the WithName and WithJoinedErrors
+// options of the policy, and no warm-up. For a policy with other options, it returns an error matching
+// ErrInvalidConfig without making any attempts.
+//
+// Every attempt receives a child of the given context that carries the attempt, which can be retrieved using
+// AttemptFromContext, and which is cancelled once Hedge returns, so that the attempts still in flight are abandoned.
+//
+// Hedge returns nil as soon as an attempt succeeds. If all attempts fail, it returns an *Error matching
+// ErrMaxRetriesExceeded, with the errors in the order in which the attempts failed. If the context is done, it
+// returns the cause of the context.
+func Hedge(ctx context.Context, policy Policy, cb func(ctx context.Context) error) error {
+	_, err := HedgeValue(ctx, policy, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, cb(ctx)
+	})
+	return err
+}
+
+// HedgeValue is like Hedge, for callbacks that return a value. It returns the value of the first successful attempt.
+func HedgeValue[T any](ctx context.Context, policy Policy, cb func(ctx context.Context) (T, error)) (T, error) {
+	cfg, err := hedgeConfig(policy)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	startTime := time.Now()
+	attemptCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		value T
+		err   error
+	}
+	results := make(chan result)
+	strategy := ExponentialBackoff(policy.initialDelay, policy.multiplier)
+	var numStarted int
+	var delay time.Duration
+	var timer *time.Timer
+	var hedge <-chan time.Time
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	canStart := func() bool {
+		return policy.maxAttempts == Forever || numStarted < policy.maxAttempts
+	}
+	start := func() {
+		numStarted++
+		attempt := newAttempt(numStarted, nil)
+		go func() {
+			value, err := cb(context.WithValue(attemptCtx, attemptKey{}, attempt))
+			select {
+			case results <- result{value: value, err: err}:
+			case <-attemptCtx.Done():
+			}
+		}()
+
+		if timer != nil {
+			timer.Stop()
+		}
+		hedge = nil
+		if canStart() {
+			delay = strategy.Delay(numStarted, delay)
+			if policy.maxDelay > 0 && delay > policy.maxDelay {
+				delay = policy.maxDelay
+			}
+			timer = time.NewTimer(policy.jitter.apply(delay))
+			hedge = timer.C
+		}
+	}
+
+	start()
+	var errs []error
+	for {
+		select {
+		case <-hedge:
+			start()
+		case res := <-results:
+			if res.err == nil {
+				return res.value, nil
+			}
+			errs = append(errs, res.err)
+			if canStart() {
+				start()
+			} else if len(errs) == numStarted {
+				var zero T
+				return zero, &Error{
+					Operation:    cfg.name,
+					NumAttempts:  numStarted,
+					TotalElapsed: time.Since(startTime),
+					Errors:       errs,
+					Reason:       ReasonMaxAttempts,
+					joined:       cfg.joinErrors,
+				}
+			}
+		case <-ctx.Done():
+			var zero T
+			return zero, context.Cause(ctx)
+		}
+	}
+}
+
+// hedgeConfig returns the config of the given policy, or an error matching ErrInvalidConfig that names the offending
+// option if the policy has options or settings that hedging doesn't support.
+func hedgeConfig(policy Policy) (config, error) {
+	cfg := newConfig(policy.opts)
+	if option := cfg.unsupportedByHedge(); option != "" {
+		return cfg, fmt.Errorf("%w: hedging supports only the WithName and WithJoinedErrors options, got %s", ErrInvalidConfig, option)
+	}
+	if policy.warmUp > 0 {
+		return cfg, fmt.Errorf("%w: hedging does not support warm-up", ErrInvalidConfig)
+	}
+	return cfg, nil
+}
+
+// unsupportedByHedge returns the name of the first option set in the config that hedging doesn't support, or an empty
+// string if there is none. Options that affect the retry loop only make no sense for hedged attempts, which don't run
+// in one.
+func (cfg *config) unsupportedByHedge() string {
+	options := []struct {
+		name string
+		set  bool
+	}{
+		{"WithMaxAttempts", cfg.maxAttempts != 0},
+		{"WithClassifier", cfg.classifier != nil},
+		{"WithStopOnError", len(cfg.stopOn) > 0},
+		{"WithPacer", cfg.pacer != nil},
+		{"WithErrorBudget", cfg.budget != nil || cfg.priority != 0},
+		{"WithRetryPressure", cfg.pressure != nil},
+		{"WithCostBudget", cfg.maxCost != 0 || cfg.cost != nil},
+		{"WithMaxElapsedTime", cfg.maxElapsed != 0},
+		{"WithDelayFunc", cfg.delayFunc != nil},
+		{"WithDelayHook", cfg.delayHook != nil},
+		{"WithMaxDelay", cfg.maxDelay != 0},
+		{"WithJitter", cfg.jitter != NoJitter},
+		{"WithAttemptHistory", cfg.history},
+		{"WithClock", cfg.clock != nil},
+		{"WithSemaphore", cfg.sem != nil},
+		{"WithLease", cfg.lease != nil},
+		{"WithRandomizationFactor", cfg.randomizationFactor != 0},
+		{"WithAutoReset", cfg.resetAfter != 0},
+		{"WithWarmUp", cfg.warmUp != 0},
+		{"WithPreCheck", cfg.preCheck != nil},
+		{"WithDeduplication", cfg.successStore != nil},
+		{"WithCoordination", cfg.coordStore != nil},
+		{"WithSharedBackoff", cfg.backoffStore != nil},
+		{"WithCredentialRefresh", cfg.refresh != nil || cfg.isExpired != nil},
+		{"WithSoftLimit", cfg.softLimit != 0 || cfg.onSoftLimit != nil},
+		{"WithSuspendDetection", cfg.suspendThreshold != 0 || cfg.onSuspend != nil},
+		{"WithMilestones", len(cfg.milestones) > 0},
+		{"WithOnRetry", cfg.onRetry != nil},
+		{"WithNotify", cfg.notify != nil},
+		{"WithOnSuccess", cfg.onSuccess != nil},
+		{"WithOnError", cfg.onError != nil},
+		{"WithHooks", len(cfg.hooks) > 0},
+		{"WithLogHook", cfg.logHook != nil},
+		{"WithMetrics", cfg.metrics != nil},
+		{"WithAttemptFields", cfg.attemptFields != nil},
+		{"WithErrorClasses or WithErrorLabels", cfg.errorLabel != nil},
+		{"WithBudget", cfg.retryBudget != nil},
+		{"WithStrictStop", cfg.strictStop},
+		{"WithConcurrentAlternatives", cfg.concurrentAlternatives},
+	}
+	for _, option := range options {
+		if option.set {
+			return option.name
+		}
+	}
+	return ""
+}
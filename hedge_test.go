@@ -0,0 +1,133 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHedge(t *testing.T) {
+	Convey("Hedge()", t, func() {
+		policy := NewPolicy().MaxAttempts(3).InitialDelay(10 * time.Millisecond).Build()
+		expectedErr := errors.New("foo")
+		var numCalled atomic.Int32
+
+		Convey("Starts another attempt if the first one doesn't finish within the delay", func() {
+			abandoned := make(chan error, 1)
+			err := Hedge(context.Background(), policy, func(ctx context.Context) error {
+				if numCalled.Add(1) == 1 {
+					<-ctx.Done()
+					abandoned <- ctx.Err()
+					return ctx.Err()
+				}
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(numCalled.Load(), ShouldEqual, 2)
+			So(<-abandoned, ShouldEqual, context.Canceled)
+		})
+
+		Convey("Does not start another attempt if the first one finishes within the delay", func() {
+			err := Hedge(context.Background(), policy, func(ctx context.Context) error {
+				numCalled.Add(1)
+				return nil
+			})
+			So(err, ShouldBeNil)
+			time.Sleep(20 * time.Millisecond)
+			So(numCalled.Load(), ShouldEqual, 1)
+		})
+
+		Convey("Starts the next attempt right away if one fails", func() {
+			policy := NewPolicy().MaxAttempts(3).InitialDelay(time.Hour).Build()
+			start := time.Now()
+			err := Hedge(context.Background(), policy, func(ctx context.Context) error {
+				numCalled.Add(1)
+				return expectedErr
+			})
+			So(time.Since(start), ShouldBeLessThan, time.Second)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(err, ShouldWrap, expectedErr)
+
+			var retryErr *Error
+			So(errors.As(err, &retryErr), ShouldBeTrue)
+			So(retryErr.NumAttempts, ShouldEqual, 3)
+			So(retryErr.Errors, ShouldHaveLength, 3)
+		})
+
+		Convey("Passes the attempt in the context", func() {
+			numbers := make(chan int, 3)
+			_ = Hedge(context.Background(), policy.With(WithName("op")), func(ctx context.Context) error {
+				attempt, _ := AttemptFromContext(ctx)
+				numbers <- attempt.Number
+				return expectedErr
+			})
+			close(numbers)
+			var got []int
+			for number := range numbers {
+				got = append(got, number)
+			}
+			So(got, ShouldResemble, []int{1, 2, 3})
+		})
+
+		Convey("Rejects policies with options it doesn't support", func() {
+			for _, policy := range []Policy{
+				policy.With(WithOnRetry(func(RetryEvent) {})),
+				policy.With(WithMaxAttempts(5)),
+				NewPolicy().WarmUp(1).Build(),
+			} {
+				err := Hedge(context.Background(), policy, func(ctx context.Context) error {
+					numCalled.Add(1)
+					return nil
+				})
+				So(err, ShouldWrap, ErrInvalidConfig)
+			}
+			So(numCalled.Load(), ShouldEqual, 0)
+
+			err := Hedge(context.Background(), policy.With(WithMetrics(NewMetrics())), func(ctx context.Context) error {
+				return nil
+			})
+			So(err.Error(), ShouldContainSubstring, "WithMetrics")
+
+			err = Hedge(context.Background(), policy.With(WithName("op"), WithJoinedErrors()), func(ctx context.Context) error {
+				return nil
+			})
+			So(err, ShouldBeNil)
+		})
+
+		Convey("Returns the cause of the context if it is done", func() {
+			ctx, cancel := context.WithCancelCause(context.Background())
+			cause := errors.New("shutting down")
+			err := Hedge(ctx, policy, func(ctx context.Context) error {
+				if numCalled.Add(1) == 3 {
+					cancel(cause)
+				}
+				<-ctx.Done()
+				return ctx.Err()
+			})
+			So(err, ShouldEqual, cause)
+		})
+	})
+}
+
+func TestHedgeValue(t *testing.T) {
+	Convey("HedgeValue()", t, func() {
+		Convey("Returns the value of the first successful attempt", func() {
+			policy := NewPolicy().MaxAttempts(2).InitialDelay(time.Millisecond).Build()
+			var numCalled atomic.Int32
+			value, err := HedgeValue(context.Background(), policy, func(ctx context.Context) (int, error) {
+				n := numCalled.Add(1)
+				if n == 1 {
+					<-ctx.Done()
+					return 1, ctx.Err()
+				}
+				return int(n), nil
+			})
+			So(err, ShouldBeNil)
+			So(value, ShouldEqual, 2)
+		})
+	})
+}
@@ -0,0 +1,151 @@
+package retry
+
+import (
+	"time"
+)
+
+// RetryEvent describes a failed attempt that is about to be retried.
+type RetryEvent struct {
+	// Operation is the name of the operation, as set using WithName.
+	Operation string
+	// Attempt is the number of the attempt that failed, counting from 1.
+	Attempt int
+	// Elapsed is the time that passed since the first attempt started, measured using the monotonic clock.
+	Elapsed time.Duration
+	// Err is the error of the attempt.
+	Err error
+	// NextDelay is the delay before the next attempt.
+	NextDelay time.Duration
+}
+
+// SuccessEvent describes a retry loop ending successfully.
+type SuccessEvent struct {
+	// Operation is the name of the operation, as set using WithName.
+	Operation string
+	// NumAttempts is the number of attempts that were made.
+	NumAttempts int
+	// Elapsed is the time that passed since the first attempt started.
+	Elapsed time.Duration
+	// Reason is why the loop ended: ReasonSucceeded, ReasonStopped, or ReasonMaxAttempts for RetryWithStop loops
+	// that reach the maximum number of retries after a successful attempt.
+	Reason Reason
+}
+
+// ErrorEvent describes a retry loop ending with an error.
+type ErrorEvent struct {
+	// Operation is the name of the operation, as set using WithName.
+	Operation string
+	// NumAttempts is the number of attempts that were made.
+	NumAttempts int
+	// Elapsed is the time that passed since the first attempt started.
+	Elapsed time.Duration
+	// Reason is why the loop ended.
+	Reason Reason
+	// Err is the error returned by the retry loop.
+	Err error
+}
+
+// WithOnRetry makes the retrier call the given hook before every retry, so that retries can be logged or metered
+// without wrapping every callback.
+func WithOnRetry(hook func(RetryEvent)) Option {
+	return func(cfg *config) {
+		cfg.onRetry = hook
+	}
+}
+
+// WithNotify makes the retrier call the given function right before every sleep between attempts, with the error of
+// the failed attempt and the time it is about to sleep for, e.g. to show "retrying in 5s" messages to users. Unlike
+// the hook of WithOnRetry, it isn't called for retries that are made right away, like during a warm-up.
+func WithNotify(notify func(err error, sleeping time.Duration)) Option {
+	return func(cfg *config) {
+		cfg.notify = notify
+	}
+}
+
+// WithOnSuccess makes the retrier call the given hook when a retry loop ends without an error.
+func WithOnSuccess(hook func(SuccessEvent)) Option {
+	return func(cfg *config) {
+		cfg.onSuccess = hook
+	}
+}
+
+// WithOnError makes the retrier call the given hook when a retry loop ends with an error, whether it gave up, was
+// aborted or the context is done.
+func WithOnError(hook func(ErrorEvent)) Option {
+	return func(cfg *config) {
+		cfg.onError = hook
+	}
+}
+
+// Hooks are lifecycle hooks of retry loops, like those set using WithOnRetry, WithOnSuccess and WithOnError. Nil hooks
+// are skipped.
+type Hooks struct {
+	// OnRetry is called before every retry.
+	OnRetry func(RetryEvent)
+	// OnSuccess is called when a retry loop ends without an error.
+	OnSuccess func(SuccessEvent)
+	// OnError is called when a retry loop ends with an error.
+	OnError func(ErrorEvent)
+}
+
+// WithHooks adds the given hooks to the retrier, which are called after the hooks set using WithOnRetry, WithOnSuccess
+// and WithOnError. Unlike those options, it doesn't replace any hooks, not even those added by other WithHooks options,
+// so that integrations can observe retry loops without taking the hooks of users.
+func WithHooks(hooks Hooks) Option {
+	return func(cfg *config) {
+		cfg.hooks = append(cfg.hooks, hooks)
+	}
+}
+
+// notifyRetry calls the retry hook, logs the retry and sends it to the given event sink, if configured.
+func (cfg *config) notifyRetry(events *eventSink, attempt int, elapsed time.Duration, err error, nextDelay time.Duration) {
+	events.send(AttemptFailed{Attempt: attempt, Err: err, Delay: nextDelay})
+	if cfg.onRetry == nil && cfg.logHook == nil && len(cfg.hooks) == 0 {
+		return
+	}
+	event := RetryEvent{Operation: cfg.name, Attempt: attempt, Elapsed: elapsed, Err: err, NextDelay: nextDelay}
+	if cfg.onRetry != nil {
+		cfg.onRetry(event)
+	}
+	for _, hooks := range cfg.hooks {
+		if hooks.OnRetry != nil {
+			hooks.OnRetry(event)
+		}
+	}
+	if cfg.logHook != nil {
+		cfg.logHook.LogRetry(event)
+	}
+}
+
+// notifyEnd calls the success or the error hook, logs the outcome and sends it to the given event sink, if configured,
+// depending on the given error.
+func (cfg *config) notifyEnd(events *eventSink, numAttempts int, elapsed time.Duration, reason Reason, err error) {
+	events.end(numAttempts, reason, err)
+	if err == nil {
+		event := SuccessEvent{Operation: cfg.name, NumAttempts: numAttempts, Elapsed: elapsed, Reason: reason}
+		if cfg.onSuccess != nil {
+			cfg.onSuccess(event)
+		}
+		for _, hooks := range cfg.hooks {
+			if hooks.OnSuccess != nil {
+				hooks.OnSuccess(event)
+			}
+		}
+		if cfg.logHook != nil {
+			cfg.logHook.LogSuccess(event)
+		}
+		return
+	}
+	event := ErrorEvent{Operation: cfg.name, NumAttempts: numAttempts, Elapsed: elapsed, Reason: reason, Err: err}
+	if cfg.onError != nil {
+		cfg.onError(event)
+	}
+	for _, hooks := range cfg.hooks {
+		if hooks.OnError != nil {
+			hooks.OnError(event)
+		}
+	}
+	if cfg.logHook != nil {
+		cfg.logHook.LogError(event)
+	}
+}
@@ -0,0 +1,115 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHooks(t *testing.T) {
+	Convey("Hooks", t, func() {
+		clock := &fakeClock{now: time.Now()}
+		var retries []RetryEvent
+		var successes []SuccessEvent
+		var failures []ErrorEvent
+		retrier := NewBackOffRetrier(time.Second, 2,
+			WithName("op"),
+			WithClock(clock),
+			WithOnRetry(func(e RetryEvent) { retries = append(retries, e) }),
+			WithOnSuccess(func(e SuccessEvent) { successes = append(successes, e) }),
+			WithOnError(func(e ErrorEvent) { failures = append(failures, e) }),
+		)
+		expectedErr := errors.New("foo")
+		var numCalled int
+
+		Convey("WithOnRetry() calls the hook before every retry, and WithOnSuccess() on success", func() {
+			err := retrier.Retry(5, func() error {
+				numCalled++
+				if numCalled < 3 {
+					return expectedErr
+				}
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(retries, ShouldResemble, []RetryEvent{
+				{Operation: "op", Attempt: 1, Err: expectedErr, NextDelay: time.Second},
+				{Operation: "op", Attempt: 2, Elapsed: time.Second, Err: expectedErr, NextDelay: 2 * time.Second},
+			})
+			So(successes, ShouldResemble, []SuccessEvent{{Operation: "op", NumAttempts: 3, Elapsed: 3 * time.Second, Reason: ReasonSucceeded}})
+			So(failures, ShouldBeEmpty)
+		})
+
+		Convey("WithOnError() calls the hook when the retrier gives up", func() {
+			err := retrier.Retry(1, func() error {
+				return expectedErr
+			})
+			So(failures, ShouldHaveLength, 1)
+			So(failures[0].Operation, ShouldEqual, "op")
+			So(failures[0].NumAttempts, ShouldEqual, 2)
+			So(failures[0].Elapsed, ShouldEqual, time.Second)
+			So(failures[0].Reason, ShouldEqual, ReasonMaxAttempts)
+			So(failures[0].Err, ShouldEqual, err)
+			So(retries, ShouldHaveLength, 1) // Not before giving up.
+			So(successes, ShouldBeEmpty)
+		})
+
+		Convey("WithOnError() calls the hook when the context is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_ = retrier.RetryCtx(ctx, 1, func() error {
+				return nil
+			})
+			So(failures, ShouldResemble, []ErrorEvent{{Operation: "op", Reason: ReasonContextDone, Err: context.Canceled}})
+		})
+
+		Convey("WithHooks() adds hooks next to the others, without replacing them", func() {
+			var calls []string
+			retrier := NewNoDelayRetrier(
+				WithOnRetry(func(RetryEvent) { calls = append(calls, "onRetry") }),
+				WithHooks(Hooks{OnRetry: func(RetryEvent) { calls = append(calls, "hooks#1") }}),
+				WithHooks(Hooks{
+					OnRetry:   func(RetryEvent) { calls = append(calls, "hooks#2") },
+					OnSuccess: func(SuccessEvent) { calls = append(calls, "onSuccess") },
+					OnError:   func(ErrorEvent) { calls = append(calls, "onError") },
+				}),
+			)
+			var numCalled int
+			So(retrier.Retry(1, func() error {
+				numCalled++
+				if numCalled == 1 {
+					return expectedErr
+				}
+				return nil
+			}), ShouldBeNil)
+			So(retrier.Retry(0, func() error { return expectedErr }), ShouldNotBeNil)
+			So(calls, ShouldResemble, []string{"onRetry", "hooks#1", "hooks#2", "onSuccess", "onError"})
+		})
+
+		Convey("WithNotify() calls the function right before every sleep", func() {
+			type call struct {
+				err      error
+				sleeping time.Duration
+			}
+			var calls []call
+			retrier := NewBackOffRetrier(time.Second, 2, WithClock(clock), WithWarmUp(1), WithNotify(func(err error, sleeping time.Duration) {
+				So(clock.sleeps, ShouldHaveLength, len(calls)) // Before sleeping.
+				calls = append(calls, call{err: err, sleeping: sleeping})
+			}))
+			_ = retrier.Retry(3, func() error {
+				return expectedErr
+			})
+			So(calls, ShouldResemble, []call{{expectedErr, time.Second}, {expectedErr, 2 * time.Second}})
+		})
+
+		Convey("WithOnRetry() is called for immediate retries", func() {
+			retrier := NewNoDelayRetrier(WithClock(clock), WithWarmUp(1), WithOnRetry(func(e RetryEvent) { retries = append(retries, e) }))
+			_ = retrier.Retry(1, func() error {
+				return expectedErr
+			})
+			So(retries, ShouldResemble, []RetryEvent{{Attempt: 1, Err: expectedErr}})
+		})
+	})
+}
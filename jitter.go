@@ -0,0 +1,89 @@
+package retry
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// JitterMode decides how the delay before a retry is randomized, so that many clients retrying at the same time
+// don't synchronize into thundering herds. Jitter only changes the delay that is slept for. The delays of a backoff
+// still grow as if there was no jitter.
+type JitterMode int
+
+const (
+	// NoJitter sleeps for the exact delay. It is the default.
+	NoJitter JitterMode = iota
+	// FullJitter sleeps for a random duration between 0 and the delay, as described in
+	// https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/. It spreads retries the most, at the
+	// cost of sometimes retrying almost right away.
+	FullJitter
+	// EqualJitter sleeps for half the delay plus a random duration between 0 and the other half. It keeps a minimum
+	// backoff of half the delay, while still spreading retries.
+	EqualJitter
+)
+
+// String implements fmt.Stringer.
+func (m JitterMode) String() string {
+	switch m {
+	case NoJitter:
+		return "none"
+	case FullJitter:
+		return "full"
+	case EqualJitter:
+		return "equal"
+	default:
+		return "unknown"
+	}
+}
+
+// valid reports whether m is a known jitter mode.
+func (m JitterMode) valid() bool {
+	return m >= NoJitter && m <= EqualJitter
+}
+
+// apply returns the given delay with jitter applied.
+func (m JitterMode) apply(delay time.Duration) time.Duration {
+	if delay <= 0 {
+		return delay
+	}
+	switch m {
+	case FullJitter:
+		return rand.N(delay + 1)
+	case EqualJitter:
+		half := delay / 2
+		return delay - half + rand.N(half+1)
+	default:
+		return delay
+	}
+}
+
+// WithJitter makes the retrier randomize the delay before every retry according to the given jitter mode.
+func WithJitter(mode JitterMode) Option {
+	return func(cfg *config) {
+		cfg.jitter = mode
+	}
+}
+
+// WithRandomizationFactor makes the retrier randomize the delay before every retry by up to the given factor in
+// either direction, like the backoff of github.com/cenkalti/backoff: with a factor of 0.5, a delay of 1s becomes a
+// random delay between 0.5s and 1.5s. The factor must be between 0 and 1. If combined with a jitter mode, the
+// jitter is applied first.
+func WithRandomizationFactor(factor float64) Option {
+	return func(cfg *config) {
+		cfg.randomizationFactor = factor
+	}
+}
+
+// randomize returns the given delay randomized by up to the given factor in either direction.
+func randomize(delay time.Duration, factor float64) time.Duration {
+	if delay <= 0 || factor <= 0 {
+		return delay
+	}
+	spread := factor * float64(delay)
+	randomized := float64(delay) - spread + rand.Float64()*2*spread
+	if randomized >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(randomized)
+}
@@ -0,0 +1,110 @@
+package retry
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestJitterMode(t *testing.T) {
+	Convey("JitterMode", t, func() {
+		Convey("NoJitter returns the exact delay", func() {
+			So(NoJitter.apply(time.Second), ShouldEqual, time.Second)
+			So(NoJitter.apply(0), ShouldEqual, 0)
+		})
+
+		Convey("FullJitter returns a random delay between 0 and the delay", func() {
+			seen := map[bool]bool{}
+			for i := 0; i < 1000; i++ {
+				delay := FullJitter.apply(time.Second)
+				So(delay, ShouldBeBetweenOrEqual, 0, time.Second)
+				seen[delay < time.Second/2] = true
+			}
+			So(seen, ShouldHaveLength, 2)
+			So(FullJitter.apply(0), ShouldEqual, 0)
+		})
+
+		Convey("EqualJitter returns half the delay plus a random duration between 0 and the other half", func() {
+			seen := map[bool]bool{}
+			for i := 0; i < 1000; i++ {
+				delay := EqualJitter.apply(time.Second)
+				So(delay, ShouldBeBetweenOrEqual, time.Second/2, time.Second)
+				seen[delay < 3*time.Second/4] = true
+			}
+			So(seen, ShouldHaveLength, 2)
+			So(EqualJitter.apply(time.Nanosecond), ShouldEqual, time.Nanosecond)
+			So(EqualJitter.apply(0), ShouldEqual, 0)
+		})
+
+		Convey("A retrier with jitter still grows its delays as if there was no jitter", func() {
+			var prevs []time.Duration
+			retrier := NewStrategyRetrier(BackoffFunc(func(_ int, prev time.Duration) time.Duration {
+				prevs = append(prevs, prev)
+				return prev + time.Millisecond
+			}), WithJitter(FullJitter))
+			_ = retrier.Retry(3, func() error {
+				return errors.New("foo")
+			})
+			So(prevs, ShouldResemble, []time.Duration{0, time.Millisecond, 2 * time.Millisecond})
+		})
+
+		Convey("String() returns the name of the mode", func() {
+			So(NoJitter.String(), ShouldEqual, "none")
+			So(FullJitter.String(), ShouldEqual, "full")
+			So(EqualJitter.String(), ShouldEqual, "equal")
+			So(JitterMode(-1).String(), ShouldEqual, "unknown")
+		})
+
+		Convey("WithJitter() sets the jitter mode", func() {
+			So(NewBackOffRetrier(time.Second, 2, WithJitter(NoJitter)).Jitter(), ShouldEqual, NoJitter)
+		})
+	})
+}
+
+func TestWithRandomizationFactor(t *testing.T) {
+	Convey("WithRandomizationFactor()", t, func() {
+		Convey("Randomizes the delay by up to the factor in either direction", func() {
+			seen := map[bool]bool{}
+			for i := 0; i < 1000; i++ {
+				delay := randomize(time.Second, 0.5)
+				So(delay, ShouldBeBetweenOrEqual, time.Second/2, 3*time.Second/2)
+				seen[delay < time.Second] = true
+			}
+			So(seen, ShouldHaveLength, 2)
+		})
+
+		Convey("Does not randomize without a factor or delay", func() {
+			So(randomize(time.Second, 0), ShouldEqual, time.Second)
+			So(randomize(0, 0.5), ShouldEqual, 0)
+		})
+
+		Convey("Saturates instead of overflowing", func() {
+			So(randomize(math.MaxInt64, 1), ShouldBeGreaterThan, 0)
+		})
+
+		Convey("Applies to the delays slept for by the retrier", func() {
+			clock := &fakeClock{now: time.Now()}
+			retrier := NewConstantDelayRetrier(time.Second, WithClock(clock), WithRandomizationFactor(0.5))
+			So(retrier.Retry(100, func() error {
+				return errors.New("foo")
+			}), ShouldWrap, ErrMaxRetriesExceeded)
+			So(clock.sleeps, ShouldHaveLength, 100)
+			var varied bool
+			for _, d := range clock.sleeps {
+				So(d, ShouldBeBetweenOrEqual, time.Second/2, 3*time.Second/2)
+				varied = varied || d != time.Second
+			}
+			So(varied, ShouldBeTrue)
+		})
+
+		Convey("Is validated by BackOffRetrier", func() {
+			So(NewBackOffRetrier(time.Second, 2, WithRandomizationFactor(0.5)).RandomizationFactor(), ShouldEqual, 0.5)
+			So(NewBackOffRetrier(time.Second, 2, WithRandomizationFactor(0.5)).Validate(), ShouldBeNil)
+			So(NewBackOffRetrier(time.Second, 2, WithRandomizationFactor(1.5)).Validate(), ShouldWrap, ErrInvalidConfig)
+			So(NewBackOffRetrier(time.Second, 2, WithRandomizationFactor(-0.5)).Validate(), ShouldWrap, ErrInvalidConfig)
+		})
+	})
+}
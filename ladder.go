@@ -0,0 +1,102 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrLadderExhausted is returned when all rungs of a ladder failed.
+var ErrLadderExhausted = errors.New("all rungs of the ladder failed")
+
+// Rung is a step of a ladder.
+type Rung struct {
+	// Name identifies the rung, e.g. "primary" or "cache".
+	Name string
+	// Retryer retries Do. If nil, Do is attempted once, which is useful for static fallbacks.
+	Retryer Retryer
+	// NumTimes is the number of times Retryer retries Do.
+	NumTimes int
+	// Do is the action of the rung.
+	Do func(ctx context.Context) error
+}
+
+// Ladder is a graceful degradation ladder: an ordered list of rungs, for example a primary with retries, a secondary
+// with retries and a static fallback. Running the ladder walks down the rungs until one succeeds.
+type Ladder struct {
+	rungs  []Rung
+	moveOn func(i int, err error) bool // Whether to move down after the given rung failed with the given error, if set.
+}
+
+// NewLadder returns a new ladder with the given rungs, in order.
+func NewLadder(rungs ...Rung) *Ladder {
+	return &Ladder{rungs: rungs}
+}
+
+// Run runs the rungs of the ladder in order, moving down to the next rung as soon as a rung fails, and returns the
+// name of the rung that succeeded.
+// If all rungs fail, it returns an error matching ErrLadderExhausted and the errors of all rungs. If the context is
+// done, it returns the context error without moving down any further.
+func (l *Ladder) Run(ctx context.Context) (string, error) {
+	i, errs, err := l.run(ctx)
+	if err != nil {
+		return "", err
+	}
+	if i >= 0 {
+		return l.rungs[i].Name, nil
+	}
+	return "", l.exhaustedError(ErrLadderExhausted, "rung", errs)
+}
+
+// run runs the rungs of the ladder in order, like Run, and returns the index of the rung that succeeded, the errors of
+// the rungs if all of them failed, or the error that stopped the ladder.
+func (l *Ladder) run(ctx context.Context) (int, []error, error) {
+	return tryInOrder(ctx, len(l.rungs), func(i int) error {
+		return l.runRung(ctx, l.rungs[i])
+	}, l.moveOn)
+}
+
+// exhaustedError returns an error matching the given sentinel and the given errors of the rungs, which are prefixed
+// with the given noun and the names of the rungs.
+func (l *Ladder) exhaustedError(sentinel error, noun string, errs []error) error {
+	for i, err := range errs {
+		errs[i] = fmt.Errorf("%s %q: %w", noun, l.rungs[i].Name, err)
+	}
+	return fmt.Errorf("%w: %w", sentinel, errors.Join(errs...))
+}
+
+// runRung runs the given rung.
+func (l *Ladder) runRung(ctx context.Context, rung Rung) error {
+	cb := func() error {
+		return rung.Do(ctx)
+	}
+	if rung.Retryer == nil {
+		return cb()
+	}
+	return rung.Retryer.RetryCtx(ctx, rung.NumTimes, cb)
+}
+
+// tryInOrder calls try for the indexes from 0 to n-1, in order, until it returns nil, and returns that index. If the
+// context is done, or try returns an error for which moveOn returns false, given the index, it returns -1 and the
+// cause of the context or that error, without trying any further. moveOn may be nil, to always move on. If all tries
+// fail, it returns -1 and their errors, in order.
+func tryInOrder(ctx context.Context, n int, try func(i int) error, moveOn func(i int, err error) bool) (int, []error, error) {
+	var errs []error
+	for i := 0; i < n; i++ {
+		if ctx.Err() != nil {
+			return -1, nil, context.Cause(ctx)
+		}
+		err := try(i)
+		if err == nil {
+			return i, nil, nil
+		}
+		if ctx.Err() != nil {
+			return -1, nil, context.Cause(ctx)
+		}
+		if moveOn != nil && !moveOn(i, err) {
+			return -1, nil, err
+		}
+		errs = append(errs, err)
+	}
+	return -1, errs, nil
+}
@@ -0,0 +1,80 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Ladder(t *testing.T) {
+	Convey("*Ladder.Run()", t, func() {
+		errPrimary := errors.New("primary")
+		errSecondary := errors.New("secondary")
+		numCalled := map[string]int{}
+		rung := func(name string, retryer Retryer, numTimes int, err error) Rung {
+			return Rung{
+				Name:     name,
+				Retryer:  retryer,
+				NumTimes: numTimes,
+				Do: func(context.Context) error {
+					numCalled[name]++
+					return err
+				},
+			}
+		}
+
+		Convey("Returns the name of the first rung that succeeds", func() {
+			ladder := NewLadder(
+				rung("primary", NewNoDelayRetrier(), 2, nil),
+				rung("fallback", nil, 0, nil),
+			)
+			name, err := ladder.Run(context.Background())
+			So(err, ShouldBeNil)
+			So(name, ShouldEqual, "primary")
+			So(numCalled, ShouldResemble, map[string]int{"primary": 1})
+		})
+
+		Convey("Walks down the ladder as each rung exhausts its retries", func() {
+			ladder := NewLadder(
+				rung("primary", NewNoDelayRetrier(), 2, errPrimary),
+				rung("secondary", NewNoDelayRetrier(), 1, errSecondary),
+				rung("fallback", nil, 0, nil),
+			)
+			name, err := ladder.Run(context.Background())
+			So(err, ShouldBeNil)
+			So(name, ShouldEqual, "fallback")
+			So(numCalled, ShouldResemble, map[string]int{"primary": 3, "secondary": 2, "fallback": 1})
+		})
+
+		Convey("If all rungs fail, returns the errors of all rungs", func() {
+			ladder := NewLadder(
+				rung("primary", NewNoDelayRetrier(), 1, errPrimary),
+				rung("secondary", nil, 0, errSecondary),
+			)
+			name, err := ladder.Run(context.Background())
+			So(name, ShouldBeEmpty)
+			So(err, ShouldWrap, ErrLadderExhausted)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(err, ShouldWrap, errPrimary)
+			So(err, ShouldWrap, errSecondary)
+			So(err.Error(), ShouldContainSubstring, `rung "primary"`)
+		})
+
+		Convey("If the context is done, does not move down any further", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			ladder := NewLadder(
+				Rung{Name: "primary", Do: func(context.Context) error {
+					cancel()
+					return errPrimary
+				}},
+				rung("fallback", nil, 0, nil),
+			)
+			name, err := ladder.Run(ctx)
+			So(name, ShouldBeEmpty)
+			So(err, ShouldEqual, context.Canceled)
+			So(numCalled["fallback"], ShouldEqual, 0)
+		})
+	})
+}
@@ -0,0 +1,31 @@
+package retry
+
+import (
+	"context"
+)
+
+// WithLease makes every attempt acquire a resource, like a connection from a pool, using the given lease function
+// before running, and release it using the returned release function as soon as it is done, whether it succeeds,
+// fails or panics. Sleeping between attempts doesn't hold the resource, so a context that is canceled mid-sleep doesn't
+// leak it. If the lease function returns an error, the attempt fails with that error without running, and is retried
+// like any other failed attempt.
+func WithLease(lease func(ctx context.Context) (release func(), err error)) Option {
+	return func(cfg *config) {
+		cfg.lease = lease
+	}
+}
+
+// runAttempt runs the given callback, holding a lease while it runs if WithLease was used.
+func (cfg *config) runAttempt(ctx context.Context, stop func(), cb func(stop func()) error) error {
+	if cfg.lease == nil {
+		return cb(stop)
+	}
+	release, err := cfg.lease(ctx)
+	if err != nil {
+		return err
+	}
+	if release != nil {
+		defer release()
+	}
+	return cb(stop)
+}
@@ -0,0 +1,92 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithLease(t *testing.T) {
+	Convey("WithLease()", t, func() {
+		var numLeased, numReleased, numCalled int
+		var held bool
+		lease := func(ctx context.Context) (func(), error) {
+			numLeased++
+			held = true
+			return func() {
+				numReleased++
+				held = false
+			}, nil
+		}
+
+		Convey("Holds a lease during every attempt, and releases it after", func() {
+			retrier := NewNoDelayRetrier(WithLease(lease))
+			err := retrier.Retry(5, func() error {
+				numCalled++
+				So(held, ShouldBeTrue)
+				if numCalled < 3 {
+					return errors.New("foo")
+				}
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(numLeased, ShouldEqual, 3)
+			So(numReleased, ShouldEqual, 3)
+		})
+
+		Convey("Does not hold the lease while sleeping", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			retrier := NewConstantDelayRetrier(time.Hour, WithLease(lease))
+			go func() {
+				time.Sleep(5 * time.Millisecond)
+				cancel()
+			}()
+			err := retrier.RetryCtx(ctx, 5, func() error {
+				return errors.New("foo")
+			})
+			So(err, ShouldEqual, context.Canceled)
+			So(numLeased, ShouldEqual, 1)
+			So(numReleased, ShouldEqual, 1)
+		})
+
+		Convey("Releases the lease if an attempt panics", func() {
+			retrier := NewNoDelayRetrier(WithLease(lease))
+			So(func() {
+				_ = retrier.Retry(5, func() error {
+					panic("foo")
+				})
+			}, ShouldPanicWith, "foo")
+			So(numLeased, ShouldEqual, 1)
+			So(numReleased, ShouldEqual, 1)
+		})
+
+		Convey("If leasing fails, fails the attempt without running it", func() {
+			expectedErr := errors.New("pool exhausted")
+			retrier := NewNoDelayRetrier(WithLease(func(ctx context.Context) (func(), error) {
+				numLeased++
+				if numLeased < 3 {
+					return nil, expectedErr
+				}
+				return func() { numReleased++ }, nil
+			}))
+			err := retrier.Retry(5, func() error {
+				numCalled++
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(numLeased, ShouldEqual, 3)
+			So(numCalled, ShouldEqual, 1)
+			So(numReleased, ShouldEqual, 1)
+
+			numLeased = 0
+			err = retrier.Retry(1, func() error {
+				return nil
+			})
+			So(err, ShouldWrap, expectedErr)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+		})
+	})
+}
@@ -0,0 +1,128 @@
+package retry
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// LintCheck identifies a check of Policy.Lint.
+type LintCheck string
+
+const (
+	// LintInvalid flags settings that produce a broken schedule, as reported by BackOffRetrier.Validate.
+	LintInvalid LintCheck = "invalid"
+	// LintNoDelay flags policies that make many attempts without any delay between them, which hammers a struggling
+	// dependency.
+	LintNoDelay LintCheck = "no-delay"
+	// LintNoJitter flags growing delays without jitter while many clients share the policy, which makes the clients
+	// retry in lockstep, in synchronized waves.
+	LintNoJitter LintCheck = "no-jitter"
+	// LintUnbounded flags policies that retry forever without a maximum elapsed time.
+	LintUnbounded LintCheck = "unbounded"
+	// LintExceedsDeadline flags policies whose worst-case duration exceeds the deadline of the requests they serve,
+	// so that the last attempts can never be made in time.
+	LintExceedsDeadline LintCheck = "exceeds-deadline"
+)
+
+// LintWarning is a dangerous configuration found by Policy.Lint.
+type LintWarning struct {
+	// Check is the check that found the configuration.
+	Check LintCheck
+	// Message describes the configuration.
+	Message string
+}
+
+// String implements fmt.Stringer.
+func (w LintWarning) String() string {
+	return fmt.Sprintf("%s: %s", w.Check, w.Message)
+}
+
+// LintOptions describes the environment a policy is used in, for Policy.Lint.
+type LintOptions struct {
+	// NumClients is the number of clients that retry the same dependency using the policy, like the number of
+	// instances of a service. Defaults to 1.
+	NumClients int
+	// RequestDeadline is the typical deadline of the requests the policy retries in. 0 means there is none.
+	RequestDeadline time.Duration
+	// MaxAttemptsWithoutDelay is the number of attempts at which a policy without delays is flagged. Defaults to 3.
+	MaxAttemptsWithoutDelay int
+}
+
+// Lint inspects the policy for dangerous configurations, like many attempts without delays, unjittered exponential
+// backoff shared by many clients, or a worst-case duration exceeding the deadline of the requests, and returns a
+// warning for every configuration found. Call it at startup, e.g. to log the warnings or fail tests.
+func (p Policy) Lint(opts LintOptions) []LintWarning {
+	if opts.NumClients < 1 {
+		opts.NumClients = 1
+	}
+	if opts.MaxAttemptsWithoutDelay < 1 {
+		opts.MaxAttemptsWithoutDelay = 3
+	}
+	retrier := p.retrier()
+	cfg := retrier.cfg
+	var warnings []LintWarning
+	warn := func(check LintCheck, format string, args ...any) {
+		warnings = append(warnings, LintWarning{Check: check, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if err := retrier.Validate(); err != nil {
+		warn(LintInvalid, "%s", err)
+		return warnings
+	}
+	forever := p.numTimes() < 0
+	if p.initialDelay == 0 && cfg.delayFunc == nil && (forever || p.maxAttempts >= opts.MaxAttemptsWithoutDelay) {
+		warn(LintNoDelay, "%s without any delay between them", p.describeAttempts())
+	}
+	if opts.NumClients > 1 && p.initialDelay > 0 && p.multiplier > 1 && p.jitter == NoJitter && cfg.randomizationFactor == 0 {
+		warn(LintNoJitter, "%d clients back off exponentially without jitter, so they retry in synchronized waves", opts.NumClients)
+	}
+	if forever && cfg.maxElapsed == 0 {
+		warn(LintUnbounded, "retries forever without a maximum elapsed time")
+	}
+	if opts.RequestDeadline > 0 {
+		switch worstCase := p.worstCaseDelay(cfg.maxElapsed); {
+		case worstCase == math.MaxInt64:
+			warn(LintExceedsDeadline, "may sleep forever, which exceeds the request deadline of %s", opts.RequestDeadline)
+		case worstCase > opts.RequestDeadline:
+			warn(LintExceedsDeadline, "sleeps for up to %s in total, which exceeds the request deadline of %s", worstCase, opts.RequestDeadline)
+		}
+	}
+	return warnings
+}
+
+// describeAttempts describes the number of attempts the policy makes.
+func (p Policy) describeAttempts() string {
+	if p.numTimes() < 0 {
+		return "retries forever"
+	}
+	return fmt.Sprintf("makes %d attempts", p.maxAttempts)
+}
+
+// worstCaseDelay returns the total time the policy sleeps for if all attempts fail, without jitter, capped at the
+// given maximum elapsed time if it is positive. It is math.MaxInt64 for policies that retry forever without a maximum.
+func (p Policy) worstCaseDelay(maxElapsed time.Duration) time.Duration {
+	if p.numTimes() < 0 {
+		if maxElapsed > 0 {
+			return maxElapsed
+		}
+		return math.MaxInt64
+	}
+	strategy := ExponentialBackoff(p.initialDelay, p.multiplier)
+	var total, delay time.Duration
+	for retry := 1; retry <= p.numTimes()-p.warmUp; retry++ {
+		delay = strategy.Delay(retry, delay)
+		if p.maxDelay > 0 && delay > p.maxDelay {
+			delay = p.maxDelay
+		}
+		if total > math.MaxInt64-delay {
+			total = math.MaxInt64
+			break
+		}
+		total += delay
+	}
+	if maxElapsed > 0 && total > maxElapsed {
+		return maxElapsed
+	}
+	return total
+}
@@ -0,0 +1,74 @@
+package retry
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPolicyLint(t *testing.T) {
+	Convey("Policy.Lint()", t, func() {
+		checks := func(warnings []LintWarning) []LintCheck {
+			var checks []LintCheck
+			for _, warning := range warnings {
+				checks = append(checks, warning.Check)
+			}
+			return checks
+		}
+
+		Convey("Does not flag the default policy", func() {
+			So(NewPolicy().Build().Lint(LintOptions{}), ShouldBeEmpty)
+		})
+
+		Convey("Flags invalid settings", func() {
+			warnings := NewPolicy().Multiplier(0.5).Build().Lint(LintOptions{})
+			So(checks(warnings), ShouldResemble, []LintCheck{LintInvalid})
+			So(warnings[0].Message, ShouldContainSubstring, "back off coefficient")
+		})
+
+		Convey("Flags many attempts without delay", func() {
+			warnings := NewPolicy().InitialDelay(0).MaxAttempts(10).Build().Lint(LintOptions{})
+			So(warnings, ShouldResemble, []LintWarning{{Check: LintNoDelay, Message: "makes 10 attempts without any delay between them"}})
+
+			So(NewPolicy().InitialDelay(0).MaxAttempts(2).Build().Lint(LintOptions{}), ShouldBeEmpty)
+			So(NewPolicy().InitialDelay(0).MaxAttempts(10).Build().Lint(LintOptions{MaxAttemptsWithoutDelay: 20}), ShouldBeEmpty)
+		})
+
+		Convey("Flags unjittered exponential backoff shared by many clients", func() {
+			policy := NewPolicy().Build()
+			So(checks(policy.Lint(LintOptions{NumClients: 100})), ShouldResemble, []LintCheck{LintNoJitter})
+			So(policy.Lint(LintOptions{NumClients: 1}), ShouldBeEmpty)
+			So(NewPolicy().Jitter(FullJitter).Build().Lint(LintOptions{NumClients: 100}), ShouldBeEmpty)
+			So(policy.With(WithRandomizationFactor(0.5)).Lint(LintOptions{NumClients: 100}), ShouldBeEmpty)
+		})
+
+		Convey("Flags retrying forever without a maximum elapsed time", func() {
+			policy := NewPolicy().MaxAttempts(Forever).Build()
+			So(checks(policy.Lint(LintOptions{})), ShouldResemble, []LintCheck{LintUnbounded})
+			So(policy.With(WithMaxElapsedTime(time.Minute)).Lint(LintOptions{}), ShouldBeEmpty)
+		})
+
+		Convey("Flags worst-case durations exceeding the request deadline", func() {
+			// Sleeps for 1s, 2s, 4s and 8s.
+			policy := NewPolicy().MaxAttempts(5).InitialDelay(time.Second).Build()
+			warnings := policy.Lint(LintOptions{RequestDeadline: 10 * time.Second})
+			So(warnings, ShouldResemble, []LintWarning{{
+				Check:   LintExceedsDeadline,
+				Message: "sleeps for up to 15s in total, which exceeds the request deadline of 10s",
+			}})
+			So(policy.Lint(LintOptions{RequestDeadline: 15 * time.Second}), ShouldBeEmpty)
+
+			Convey("Taking the maximum delay, the warm-up and the maximum elapsed time into account", func() {
+				So(NewPolicy().MaxAttempts(5).InitialDelay(time.Second).MaxDelay(2*time.Second).Build().Lint(LintOptions{RequestDeadline: 7 * time.Second}), ShouldBeEmpty)
+				So(NewPolicy().MaxAttempts(5).InitialDelay(time.Second).WarmUp(1).Build().Lint(LintOptions{RequestDeadline: 7 * time.Second}), ShouldBeEmpty)
+				So(policy.With(WithMaxElapsedTime(5*time.Second)).Lint(LintOptions{RequestDeadline: 10 * time.Second}), ShouldBeEmpty)
+			})
+
+			Convey("Describing policies that retry forever", func() {
+				warnings := NewPolicy().MaxAttempts(Forever).Build().Lint(LintOptions{RequestDeadline: time.Second})
+				So(warnings[1].String(), ShouldEqual, "exceeds-deadline: may sleep forever, which exceeds the request deadline of 1s")
+			})
+		})
+	})
+}
@@ -0,0 +1,88 @@
+package retry
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogHook logs the events of retry loops. Implement it to plug in any logging library.
+type LogHook interface {
+	// LogRetry logs a failed attempt that is about to be retried.
+	LogRetry(event RetryEvent)
+	// LogSuccess logs a retry loop ending without an error.
+	LogSuccess(event SuccessEvent)
+	// LogError logs a retry loop ending with an error.
+	LogError(event ErrorEvent)
+}
+
+// WithLogHook makes the retrier log every retry and the outcome of every retry loop using the given hook. It can be
+// combined with WithOnRetry, WithOnSuccess and WithOnError.
+func WithLogHook(hook LogHook) Option {
+	return func(cfg *config) {
+		cfg.logHook = hook
+	}
+}
+
+// WithLogger makes the retrier log every retry and the outcome of every retry loop using the given logger, at the
+// default log levels. Use WithLogHook with NewSlogHook for other levels.
+func WithLogger(logger *slog.Logger) Option {
+	return WithLogHook(NewSlogHook(logger, DefaultLogLevels))
+}
+
+// LogLevels are the levels at which the events of retry loops are logged.
+type LogLevels struct {
+	// Retry is the level of failed attempts that are retried.
+	Retry slog.Level
+	// Success is the level of retry loops ending without an error.
+	Success slog.Level
+	// Error is the level of retry loops ending with an error.
+	Error slog.Level
+}
+
+// DefaultLogLevels are the log levels used by WithLogger. Successes are logged at debug level, so that they don't
+// flood the logs.
+var DefaultLogLevels = LogLevels{
+	Retry:   slog.LevelWarn,
+	Success: slog.LevelDebug,
+	Error:   slog.LevelError,
+}
+
+// SlogHook is a LogHook that logs using a *slog.Logger.
+type SlogHook struct {
+	logger *slog.Logger
+	levels LogLevels
+}
+
+// NewSlogHook returns a new log hook that logs using the given logger at the given levels.
+func NewSlogHook(logger *slog.Logger, levels LogLevels) *SlogHook {
+	return &SlogHook{logger: logger, levels: levels}
+}
+
+// LogRetry implements LogHook.
+func (h *SlogHook) LogRetry(event RetryEvent) {
+	h.logger.LogAttrs(context.Background(), h.levels.Retry, "attempt failed, retrying",
+		slog.String("operation", event.Operation),
+		slog.Int("attempt", event.Attempt),
+		slog.Duration("delay", event.NextDelay),
+		slog.Any("error", event.Err),
+	)
+}
+
+// LogSuccess implements LogHook.
+func (h *SlogHook) LogSuccess(event SuccessEvent) {
+	h.logger.LogAttrs(context.Background(), h.levels.Success, "succeeded",
+		slog.String("operation", event.Operation),
+		slog.Int("attempts", event.NumAttempts),
+		slog.Duration("elapsed", event.Elapsed),
+	)
+}
+
+// LogError implements LogHook.
+func (h *SlogHook) LogError(event ErrorEvent) {
+	h.logger.LogAttrs(context.Background(), h.levels.Error, "failed",
+		slog.String("operation", event.Operation),
+		slog.Int("attempts", event.NumAttempts),
+		slog.Duration("elapsed", event.Elapsed),
+		slog.Any("error", event.Err),
+	)
+}
@@ -0,0 +1,72 @@
+package retry
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithLogger(t *testing.T) {
+	Convey("WithLogger()", t, func() {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+			Level: slog.LevelDebug,
+			ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.TimeKey {
+					return slog.Attr{}
+				}
+				return a
+			},
+		}))
+		clock := &fakeClock{now: time.Now()}
+		expectedErr := errors.New("foo")
+
+		Convey("Logs every retry and the outcome", func() {
+			var numCalled int
+			retrier := NewBackOffRetrier(time.Second, 2, WithName("op"), WithClock(clock), WithLogger(logger))
+			err := retrier.Retry(5, func() error {
+				numCalled++
+				if numCalled < 2 {
+					return expectedErr
+				}
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(strings.Split(strings.TrimSpace(buf.String()), "\n"), ShouldResemble, []string{
+				`level=WARN msg="attempt failed, retrying" operation=op attempt=1 delay=1s error=foo`,
+				`level=DEBUG msg=succeeded operation=op attempts=2 elapsed=1s`,
+			})
+		})
+
+		Convey("Logs errors", func() {
+			retrier := NewNoDelayRetrier(WithClock(clock), WithLogger(logger))
+			_ = retrier.Retry(0, func() error {
+				return expectedErr
+			})
+			So(strings.TrimSpace(buf.String()), ShouldEqual, `level=ERROR msg=failed operation="" attempts=1 elapsed=0s error="max retries exceeded: foo"`)
+		})
+
+		Convey("NewSlogHook() logs at the given levels", func() {
+			retrier := NewNoDelayRetrier(WithLogHook(NewSlogHook(logger, LogLevels{Success: slog.LevelInfo})))
+			_ = retrier.Retry(0, func() error {
+				return nil
+			})
+			So(buf.String(), ShouldStartWith, "level=INFO msg=succeeded")
+		})
+
+		Convey("Can be combined with hooks", func() {
+			var numRetries int
+			retrier := NewNoDelayRetrier(WithLogger(logger), WithOnRetry(func(RetryEvent) { numRetries++ }))
+			_ = retrier.Retry(1, func() error {
+				return expectedErr
+			})
+			So(numRetries, ShouldEqual, 1)
+			So(buf.String(), ShouldContainSubstring, "attempt failed, retrying")
+		})
+	})
+}
@@ -0,0 +1,336 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// maxForeverErrors is the maximum number of errors and attempt records kept by a loop that retries forever.
+const maxForeverErrors = 100
+
+// retryLoop retries the given callback at max the given number of times, sleeping for the delay returned by the given
+// strategy after every failed attempt. If numTimes is negative, it retries forever. The maximum number of attempts
+// set using WithMaxAttempts caps the number of attempts. If the context was marked using ContextNoRetry, or retrying is
+// disabled using Disable, it makes exactly one attempt.
+// If withStop is true, it stops only when `stop` is called. Otherwise, it stops as soon as a `nil` error is returned.
+// If the context is done, it returns the cause of the context, as returned by context.Cause.
+//
+// All elapsed time is measured using the monotonic clock, so that wall clock jumps don't corrupt the maximum elapsed
+// time or the deadline awareness.
+//
+// If the context has a deadline, it gives up as soon as the next delay plus the mean duration of the attempts so far
+// would not fit before the deadline, instead of sleeping into a guaranteed deadline exceeded error.
+func retryLoop(ctx context.Context, cfg *config, numTimes int, withStop bool, strategy BackoffStrategy, cb func(stop func()) error) (retErr error) {
+	clock := cfg.getClock()
+	startTime := clock.Now()
+	if cfg.maxAttempts > 0 && (numTimes < 0 || numTimes >= cfg.maxAttempts) {
+		numTimes = cfg.maxAttempts - 1
+	}
+	if isNoRetry(ctx) {
+		numTimes = 0
+	}
+	disabled := Disabled()
+	if disabled {
+		numTimes = 0
+	}
+	var err error
+	var stopped atomic.Bool // Set by `stop`, which may be called by other goroutines.
+	signal := stopSignalFromContext(ctx)
+	stop := func() {
+		stopped.Store(true)
+		signal.stop(ErrStopped)
+	}
+	if cfg.maxElapsed > 0 && cfg.clock == nil {
+		defer signal.stopAfter(cfg.maxElapsed, ErrMaxElapsedTimeExceeded)()
+	}
+	var delay, slept time.Duration
+	var errs []error
+	var numAttempts int
+	var attemptsDur time.Duration
+	var history []AttemptRecord
+	var totalCost float64
+	var sentinel error
+	var giveUp Reason // Why the loop gave up, if it gave up by itself.
+	var refreshed bool
+	var numFailures int          // The number of failed attempts that were classified.
+	var succeededSince time.Time // When the current streak of successful attempts started.
+	var resetAt int              // The index of the attempt after which the backoff was last reset.
+	var numMilestones int        // The number of milestones that were reached.
+	var forgiven time.Duration   // The time forgiven for suspensions, which doesn't count against the maximum elapsed time.
+	events := eventSinkFromContext(ctx)
+	session := sessionFromContext(ctx)
+	opStats := cfg.metrics.operation(cfg.name)
+	if session != nil {
+		session.Operation = cfg.name
+		session.Start = startTime
+	}
+	defer func() {
+		if session != nil {
+			session.End = clock.Now()
+		}
+		reason := giveUp
+		if reason == 0 {
+			reason = endReason(ctx, retErr, stopped.Load())
+		}
+		cfg.stats.record(numAttempts, slept, retErr)
+		opStats.record(numAttempts, slept, retErr)
+		cfg.notifyEnd(events, numAttempts, clock.Now().Sub(startTime), reason, retErr)
+		if cfg.afterEnd != nil {
+			cfg.afterEnd(reason)
+		}
+	}()
+	for i := 0; numTimes < 0 || i <= numTimes; i++ {
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+		if err := cfg.checkDuplicate(ctx, clock.Now()); err != nil {
+			return err
+		}
+		if err := cfg.checkDependency(ctx, clock.Now()); err != nil {
+			return err
+		}
+		if expired, checkErr := cfg.waitForPreCheck(ctx, clock, startTime.Add(forgiven)); expired {
+			err = checkErr
+			errs = append(errs, checkErr)
+			sentinel = ErrMaxElapsedTimeExceeded
+			giveUp = ReasonBudgetExhausted
+			break
+		} else if checkErr != nil {
+			return checkErr
+		}
+		if cfg.sem != nil {
+			if err := cfg.sem.Acquire(ctx, cfg.semWeight); err != nil {
+				if ctx.Err() != nil {
+					return context.Cause(ctx)
+				}
+				return err
+			}
+		}
+		if numAttempts > 0 {
+			cfg.stats.recordRetry(numAttempts)
+			opStats.recordRetry(numAttempts)
+		} else {
+			cfg.retryBudget.deposit(ctx)
+		}
+		events.send(AttemptStarted{Attempt: numAttempts + 1})
+		attemptStart := clock.Now()
+		err = func() error {
+			if cfg.sem != nil {
+				// Release the weight also if the callback panics, so that it doesn't leak.
+				defer cfg.sem.Release(cfg.semWeight)
+			}
+			return cfg.runAttempt(ctx, stop, cb)
+		}()
+		attemptDur := clock.Now().Sub(attemptStart)
+		attemptsDur += attemptDur
+		numAttempts++
+		if err != nil {
+			if numTimes < 0 && len(errs) == maxForeverErrors {
+				// Don't let the errors of a loop that retries forever pile up.
+				errs = errs[1:]
+			}
+			errs = append(errs, err)
+			if class := cfg.errorClass(err); class != "" {
+				cfg.stats.recordError(class)
+				opStats.recordError(class)
+			}
+		}
+		if cfg.history || session != nil {
+			record := AttemptRecord{Attempt: numAttempts, Time: attemptStart, Offset: attemptStart.Sub(startTime), Duration: attemptDur, Err: err}
+			if cfg.history {
+				if numTimes < 0 && len(history) == maxForeverErrors {
+					history = history[1:]
+				}
+				if cfg.attemptFields != nil {
+					record.Fields = cfg.attemptFields(ctx, numAttempts, err)
+				}
+				history = append(history, record)
+			}
+			session.recordAttempt(record, numTimes < 0)
+		}
+		if cfg.budget != nil {
+			cfg.budget.Record(err != nil)
+		}
+		if cfg.pressure != nil {
+			cfg.pressure.Record(cfg.name, numAttempts > 1)
+		}
+		if cfg.cost != nil {
+			totalCost += cfg.cost(numAttempts, err)
+		}
+		if err == nil {
+			if err := cfg.recordSuccess(ctx, clock.Now()); err != nil {
+				return err
+			}
+			cfg.resetSharedBackoff(ctx)
+		} else {
+			numMilestones = cfg.reachMilestones(numMilestones, numTimes, numAttempts, clock.Now().Sub(startTime), err)
+		}
+		if stopped.Load() || (err == nil && !withStop) {
+			return err
+		}
+		if err == nil && succeededSince.IsZero() {
+			succeededSince = attemptStart
+		}
+		var action Action
+		if err != nil {
+			// Classified before the last attempt short-circuits, so that its error is returned as is if it aborts.
+			action = cfg.classify(err)
+			if action.kind == actionAbort {
+				return err
+			}
+		}
+		if err != nil {
+			// Refreshed before the last attempt short-circuits, since the retry with the refreshed credentials doesn't
+			// count against the maximum number of retries.
+			justRefreshed, refreshErr := cfg.refreshCredentials(ctx, err, refreshed)
+			if refreshErr != nil {
+				return refreshErr
+			}
+			if justRefreshed {
+				// Retry right away with the refreshed credentials.
+				refreshed = true
+				if numTimes >= 0 {
+					numTimes++
+				}
+				cfg.notifyRetry(events, numAttempts, clock.Now().Sub(startTime), err, 0)
+				continue
+			}
+		}
+		if err == nil || (i == numTimes && (!cfg.sleepAfterLastAttempt || disabled)) {
+			// Returning nil does not trigger sleep, and there is no need to sleep after the last attempt.
+			continue
+		}
+		if cfg.budget != nil && !retryAllowed(i, cfg.budget.maxRetries(numTimes, cfg.priority)) {
+			// The error budget burns too fast to retry any further.
+			giveUp = ReasonBudgetExhausted
+			break
+		}
+		if cfg.cost != nil && totalCost >= cfg.maxCost {
+			sentinel = ErrCostBudgetExceeded
+			giveUp = ReasonBudgetExhausted
+			break
+		}
+
+		if !cfg.retryBudget.withdraw(ctx) {
+			// Retries are skipped while the budget is depleted.
+			giveUp = ReasonBudgetExhausted
+			return err
+		}
+		numFailures++
+		if numFailures <= cfg.warmUp && action.kind == actionRetry {
+			// Retry right away during the warm-up. Backoff engages after it, starting from the first delay.
+			resetAt = i + 1
+			cfg.notifyRetry(events, numAttempts, clock.Now().Sub(startTime), err, 0)
+			continue
+		}
+		if cfg.resetAfter > 0 && !succeededSince.IsZero() && clock.Now().Sub(succeededSince) >= cfg.resetAfter {
+			// A blip after a period of sustained success starts backing off from the start again.
+			delay = 0
+			resetAt = i
+		}
+		succeededSince = time.Time{}
+		if cfg.delayFunc != nil {
+			delay = cfg.delayFunc(numAttempts, err)
+		} else {
+			delay = cfg.sharedDelay(ctx, strategy, i+1-resetAt, delay)
+		}
+		if cfg.maxDelay > 0 && delay > cfg.maxDelay {
+			delay = cfg.maxDelay
+		}
+		sleepDur := randomize(cfg.jitter.apply(delay), cfg.randomizationFactor)
+		if cfg.pacer != nil {
+			sleepDur = 0
+		}
+		if cfg.softLimit > 0 && numAttempts >= cfg.softLimit {
+			if numAttempts == cfg.softLimit && cfg.onSoftLimit != nil {
+				cfg.onSoftLimit(SoftLimitEvent{Operation: cfg.name, NumAttempts: numAttempts, Err: err})
+			}
+			sleepDur = cfg.trickleDelay
+		}
+		if action.kind == actionRetryAfter {
+			sleepDur = action.delay
+		}
+		if cfg.delayHook != nil {
+			sleepDur = cfg.delayHook(numAttempts, sleepDur, err)
+		}
+		if cfg.maxElapsed > 0 && clock.Now().Sub(startTime)-forgiven+sleepDur >= cfg.maxElapsed {
+			sentinel = ErrMaxElapsedTimeExceeded
+			giveUp = ReasonBudgetExhausted
+			break
+		}
+		if deadline, ok := ctx.Deadline(); ok && clock.Now().Add(sleepDur+attemptsDur/time.Duration(numAttempts)).After(deadline) {
+			// The next attempt can't finish before the deadline.
+			sentinel = context.DeadlineExceeded
+			giveUp = ReasonContextDone
+			break
+		}
+		cfg.notifyRetry(events, numAttempts, clock.Now().Sub(startTime), err, sleepDur)
+		if cfg.notify != nil {
+			cfg.notify(err, sleepDur)
+		}
+		if cfg.beforeSleep != nil {
+			cfg.beforeSleep(numAttempts, sleepDur)
+		}
+		sleepStart := clock.Now()
+		sleepErr := clock.Sleep(ctx, sleepDur)
+		if sleepErr == nil {
+			forgiven += cfg.detectSuspend(numAttempts, sleepDur, clock.Now().Sub(sleepStart))
+		}
+		if sleepErr == nil && cfg.pacer != nil {
+			sleepErr = cfg.pacer.Wait(ctx)
+		}
+		session.recordSleep(SleepRecord{Time: sleepStart, Planned: sleepDur, Duration: clock.Now().Sub(sleepStart)}, numTimes < 0)
+		if sleepErr != nil {
+			return sleepErr
+		}
+		slept += clock.Now().Sub(sleepStart)
+	}
+	if giveUp == 0 {
+		giveUp = ReasonMaxAttempts
+	}
+	if err == nil && !(withStop && cfg.strictStop) {
+		return nil
+	}
+	retryErr := &Error{
+		Operation:    cfg.name,
+		NumAttempts:  numAttempts,
+		TotalElapsed: clock.Now().Sub(startTime),
+		TotalSlept:   slept,
+		Errors:       errs,
+		Reason:       giveUp,
+		sentinel:     sentinel,
+		joined:       cfg.joinErrors,
+	}
+	var giveUpErr error = retryErr
+	if cfg.history {
+		giveUpErr = &AttemptsError{Attempts: history, err: retryErr}
+	}
+	if err := cfg.markDependencyDown(ctx, clock.Now()); err != nil {
+		return errors.Join(giveUpErr, err)
+	}
+	return giveUpErr
+}
+
+// sleep sleeps for the given duration, or until the given context is done, in which case it returns the cause of the
+// context.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// retryAllowed reports whether another retry is allowed after the given number of retries, given the maximum number
+// of retries, which is negative for retrying forever.
+func retryAllowed(numRetries, maxRetries int) bool {
+	return maxRetries < 0 || numRetries < maxRetries
+}
@@ -0,0 +1,132 @@
+package retry
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics aggregates the statistics of retry loops, grouped by the operation name set using WithName, across all
+// retriers that share it. Updates are lock-free atomic operations once an operation was seen, so it can be enabled on
+// hot paths of high-QPS services without becoming a point of contention. It is safe for concurrent use, and is meant to
+// be shared by all retriers of an application.
+type Metrics struct {
+	operations sync.Map // Operation name to *operationMetrics.
+}
+
+// operationMetrics keeps the statistics of an operation. Unlike retrierStats, it doesn't take a lock.
+type operationMetrics struct {
+	attempts     atomic.Int64
+	retries      atomic.Int64
+	retrying     atomic.Int64
+	successes    atomic.Int64
+	failures     atomic.Int64
+	slept        atomic.Int64 // In nanoseconds.
+	errorClasses sync.Map     // Error class to *atomic.Int64.
+}
+
+// NewMetrics returns a new metrics registry.
+func NewMetrics() *Metrics {
+	return &Metrics{}
+}
+
+// WithMetrics makes the retrier record the statistics of its retry loops in the given registry.
+func WithMetrics(metrics *Metrics) Option {
+	return func(cfg *config) {
+		cfg.metrics = metrics
+	}
+}
+
+// Stats returns the statistics of the given operation.
+func (m *Metrics) Stats(operation string) RetrierStats {
+	om, ok := m.operations.Load(operation)
+	if !ok {
+		return RetrierStats{}
+	}
+	return om.(*operationMetrics).stats()
+}
+
+// All returns the statistics of all operations, by operation name.
+func (m *Metrics) All() map[string]RetrierStats {
+	all := make(map[string]RetrierStats)
+	m.operations.Range(func(operation, om any) bool {
+		all[operation.(string)] = om.(*operationMetrics).stats()
+		return true
+	})
+	return all
+}
+
+// operation returns the metrics of the given operation, creating them if the operation wasn't seen before. It returns
+// nil on nil metrics, so that recording in the result does nothing.
+func (m *Metrics) operation(operation string) *operationMetrics {
+	if m == nil {
+		return nil
+	}
+	if om, ok := m.operations.Load(operation); ok {
+		return om.(*operationMetrics)
+	}
+	om, _ := m.operations.LoadOrStore(operation, &operationMetrics{})
+	return om.(*operationMetrics)
+}
+
+// recordRetry records that a retry loop is about to make a retry, which is its first one if numAttempts is 1. It does
+// nothing on nil metrics.
+func (om *operationMetrics) recordRetry(numAttempts int) {
+	if om == nil {
+		return
+	}
+	om.retries.Add(1)
+	if numAttempts == 1 {
+		om.retrying.Add(1)
+	}
+}
+
+// recordError records that an attempt failed with an error of the given class. It does nothing on nil metrics.
+func (om *operationMetrics) recordError(class string) {
+	if om == nil {
+		return
+	}
+	n, ok := om.errorClasses.Load(class)
+	if !ok {
+		n, _ = om.errorClasses.LoadOrStore(class, &atomic.Int64{})
+	}
+	n.(*atomic.Int64).Add(1)
+}
+
+// record records the outcome of a retry loop. It does nothing on nil metrics.
+func (om *operationMetrics) record(numAttempts int, slept time.Duration, err error) {
+	if om == nil {
+		return
+	}
+	om.attempts.Add(int64(numAttempts))
+	if numAttempts > 1 {
+		om.retrying.Add(-1)
+	}
+	om.slept.Add(int64(slept))
+	if err == nil {
+		om.successes.Add(1)
+	} else {
+		om.failures.Add(1)
+	}
+}
+
+// stats returns a snapshot of the statistics. The counters are read one by one, so a snapshot taken while retry loops
+// are recording may be off by the updates that happen meanwhile.
+func (om *operationMetrics) stats() RetrierStats {
+	stats := RetrierStats{
+		Attempts:   int(om.attempts.Load()),
+		Retries:    int(om.retries.Load()),
+		Retrying:   int(om.retrying.Load()),
+		Successes:  int(om.successes.Load()),
+		Failures:   int(om.failures.Load()),
+		TotalSlept: time.Duration(om.slept.Load()),
+	}
+	om.errorClasses.Range(func(class, n any) bool {
+		if stats.ErrorClasses == nil {
+			stats.ErrorClasses = make(map[string]int)
+		}
+		stats.ErrorClasses[class.(string)] = int(n.(*atomic.Int64).Load())
+		return true
+	})
+	return stats
+}
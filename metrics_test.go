@@ -0,0 +1,77 @@
+package retry
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMetrics(t *testing.T) {
+	Convey("Metrics", t, func() {
+		metrics := NewMetrics()
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		expectedErr := errors.New("foo")
+
+		Convey("Aggregates the statistics of all retriers sharing an operation name", func() {
+			a := NewConstantDelayRetrier(time.Second, WithClock(clock), WithName("op"), WithMetrics(metrics))
+			b := NewConstantDelayRetrier(2*time.Second, WithClock(clock), WithName("op"), WithMetrics(metrics))
+			var numCalled int
+			So(a.Retry(5, func() error {
+				numCalled++
+				if numCalled < 2 {
+					return expectedErr
+				}
+				return nil
+			}), ShouldBeNil)
+			So(b.Retry(1, func() error {
+				return expectedErr
+			}), ShouldNotBeNil)
+
+			So(metrics.Stats("op"), ShouldResemble, RetrierStats{
+				Attempts:   4,
+				Retries:    2,
+				Successes:  1,
+				Failures:   1,
+				TotalSlept: 3 * time.Second,
+			})
+		})
+
+		Convey("Counts failed attempts per error class", func() {
+			retrier := NewNoDelayRetrier(WithName("op"), WithMetrics(metrics), WithErrorClasses(ErrorClass{Label: "foo", Target: expectedErr}))
+			_ = retrier.Retry(1, func() error { return expectedErr })
+			So(metrics.Stats("op").ErrorClasses, ShouldResemble, map[string]int{"foo": 2})
+		})
+
+		Convey("Keeps operations apart", func() {
+			_ = NewNoDelayRetrier(WithName("a"), WithMetrics(metrics)).Retry(0, func() error { return nil })
+			_ = NewNoDelayRetrier(WithName("b"), WithMetrics(metrics)).Retry(0, func() error { return expectedErr })
+
+			all := metrics.All()
+			So(all, ShouldHaveLength, 2)
+			So(all["a"].Successes, ShouldEqual, 1)
+			So(all["b"].Failures, ShouldEqual, 1)
+		})
+
+		Convey("Stats() returns zero statistics for unknown operations", func() {
+			So(metrics.Stats("unknown"), ShouldResemble, RetrierStats{})
+		})
+
+		Convey("Is safe for concurrent use", func() {
+			retrier := NewNoDelayRetrier(WithName("op"), WithMetrics(metrics))
+			var wg sync.WaitGroup
+			for i := 0; i < 50; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_ = retrier.Retry(1, func() error { return expectedErr })
+				}()
+			}
+			wg.Wait()
+			So(metrics.Stats("op").Attempts, ShouldEqual, 100)
+			So(metrics.Stats("op").Retries, ShouldEqual, 50)
+		})
+	})
+}
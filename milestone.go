@@ -0,0 +1,60 @@
+package retry
+
+import (
+	"slices"
+	"time"
+)
+
+// MilestoneEvent describes a retry loop reaching a milestone.
+type MilestoneEvent struct {
+	// Operation is the name of the operation, as set using WithName.
+	Operation string
+	// Milestone is the fraction of the budget that was reached, e.g. 0.5.
+	Milestone float64
+	// NumAttempts is the number of attempts that were made.
+	NumAttempts int
+	// Elapsed is the time that passed since the first attempt started.
+	Elapsed time.Duration
+	// Err is the error of the last attempt.
+	Err error
+}
+
+// WithMilestones makes the retrier call the given hook once for each of the given milestones that a retry loop
+// reaches, so that long retry loops can log or alert with escalating severity before they give up. Milestones are
+// fractions of the budget of the loop, e.g. 0.25, 0.5 and 0.75. The budget is the maximum number of attempts, or the
+// maximum elapsed time set using WithMaxElapsedTime, whichever is used up the most. Milestones are only checked after
+// failed attempts, and are never reached by loops that retry forever without a maximum elapsed time.
+func WithMilestones(hook func(MilestoneEvent), milestones ...float64) Option {
+	milestones = slices.Clone(milestones)
+	slices.Sort(milestones)
+	return func(cfg *config) {
+		cfg.onMilestone = hook
+		cfg.milestones = milestones
+	}
+}
+
+// reachMilestones calls the milestone hook for every milestone that was reached since the given number of reached
+// milestones, and returns the new number of reached milestones.
+func (cfg *config) reachMilestones(numReached, numTimes, numAttempts int, elapsed time.Duration, err error) int {
+	if cfg.onMilestone == nil || numReached == len(cfg.milestones) {
+		return numReached
+	}
+	var progress float64
+	if numTimes >= 0 {
+		progress = float64(numAttempts) / float64(numTimes+1)
+	}
+	if cfg.maxElapsed > 0 {
+		progress = max(progress, float64(elapsed)/float64(cfg.maxElapsed))
+	}
+	for numReached < len(cfg.milestones) && progress >= cfg.milestones[numReached] {
+		cfg.onMilestone(MilestoneEvent{
+			Operation:   cfg.name,
+			Milestone:   cfg.milestones[numReached],
+			NumAttempts: numAttempts,
+			Elapsed:     elapsed,
+			Err:         err,
+		})
+		numReached++
+	}
+	return numReached
+}
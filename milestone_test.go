@@ -0,0 +1,80 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithMilestones(t *testing.T) {
+	Convey("WithMilestones()", t, func() {
+		var events []MilestoneEvent
+		hook := func(e MilestoneEvent) {
+			events = append(events, e)
+		}
+		expectedErr := errors.New("foo")
+
+		Convey("Calls the hook once per milestone of the maximum number of attempts", func() {
+			retrier := NewNoDelayRetrier(WithName("op"), WithMilestones(hook, 0.75, 0.25, 0.5))
+			err := retrier.Retry(7, func() error {
+				return expectedErr
+			})
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(events, ShouldHaveLength, 3)
+			So(events[0].Operation, ShouldEqual, "op")
+			So(events[0].Milestone, ShouldEqual, 0.25)
+			So(events[0].NumAttempts, ShouldEqual, 2)
+			So(events[0].Err, ShouldEqual, expectedErr)
+			So(events[1].Milestone, ShouldEqual, 0.5)
+			So(events[1].NumAttempts, ShouldEqual, 4)
+			So(events[2].Milestone, ShouldEqual, 0.75)
+			So(events[2].NumAttempts, ShouldEqual, 6)
+		})
+
+		Convey("Calls the hook for milestones of the maximum elapsed time", func() {
+			clock := &fakeClock{now: time.Now()}
+			retrier := NewConstantDelayRetrier(time.Second, WithClock(clock), WithMaxElapsedTime(4*time.Second), WithMilestones(hook, 0.5))
+			err := retrier.RetryForever(context.Background(), func() error {
+				return expectedErr
+			})
+			So(err, ShouldWrap, ErrMaxElapsedTimeExceeded)
+			So(events, ShouldHaveLength, 1)
+			So(events[0].NumAttempts, ShouldEqual, 3)
+			So(events[0].Elapsed, ShouldEqual, 2*time.Second)
+		})
+
+		Convey("Calls the hook for all milestones that were reached at once", func() {
+			retrier := NewNoDelayRetrier(WithMilestones(hook, 0.25, 0.5))
+			_ = retrier.Retry(1, func() error {
+				return expectedErr
+			})
+			So(events, ShouldHaveLength, 2)
+			So(events[0].NumAttempts, ShouldEqual, 1)
+			So(events[1].NumAttempts, ShouldEqual, 1)
+		})
+
+		Convey("Does not call the hook after successful attempts", func() {
+			retrier := NewNoDelayRetrier(WithMilestones(hook, 0.5))
+			_ = retrier.RetryWithStop(1, func(stop func()) error {
+				return nil
+			})
+			So(events, ShouldBeEmpty)
+		})
+
+		Convey("Does not call the hook for loops that retry forever without a maximum elapsed time", func() {
+			retrier := NewNoDelayRetrier(WithMilestones(hook, 0.01))
+			var numCalled int
+			_ = retrier.RetryForever(context.Background(), func() error {
+				numCalled++
+				if numCalled == 100 {
+					return nil
+				}
+				return expectedErr
+			})
+			So(events, ShouldBeEmpty)
+		})
+	})
+}
@@ -0,0 +1,94 @@
+package retry
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// NegativeCache remembers for which keys a retrier gave up, so that subsequent calls for the same key fail fast with
+// the same error, instead of running a full retry loop against something that is known to be failing.
+// It is safe for concurrent use.
+type NegativeCache struct {
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[string]negativeCacheEntry
+}
+
+// negativeCacheEntry is the cached give-up of a key.
+type negativeCacheEntry struct {
+	err     error
+	expires time.Time
+}
+
+// NewNegativeCache returns a new negative cache that remembers give-ups for the given TTL.
+func NewNegativeCache(ttl time.Duration) *NegativeCache {
+	return &NegativeCache{ttl: ttl, now: time.Now, entries: make(map[string]negativeCacheEntry)}
+}
+
+// Do calls the given function, which is expected to run a retry loop, unless a give-up was cached for the given key,
+// in which case it returns the cached error right away.
+// If the function returns an error because the retrier gave up (an *Error), that error is cached for the key. Other
+// errors, such as context errors or errors aborted by a classifier, are not cached.
+func (c *NegativeCache) Do(key string, fn func() error) error {
+	if err := c.Get(key); err != nil {
+		return err
+	}
+	err := fn()
+	var retryErr *Error
+	if errors.As(err, &retryErr) {
+		c.set(key, err)
+	}
+	return err
+}
+
+// Get returns the cached error of the given key, or nil if there is none or it has expired.
+func (c *NegativeCache) Get(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	if !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		return nil
+	}
+	return entry.err
+}
+
+// Purge removes the cached error of the given key, so that the next call for it runs the retry loop again.
+func (c *NegativeCache) Purge(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// PurgeAll removes all cached errors.
+func (c *NegativeCache) PurgeAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
+
+// Len returns the number of cached errors, including those that expired but were not removed yet.
+func (c *NegativeCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// set caches the given error for the given key. It also removes expired entries, so that keys that are never
+// looked up again don't pile up.
+func (c *NegativeCache) set(key string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for k, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = negativeCacheEntry{err: err, expires: now.Add(c.ttl)}
+}
@@ -0,0 +1,101 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_NegativeCache(t *testing.T) {
+	Convey("*NegativeCache", t, func() {
+		now := time.Now()
+		cache := NewNegativeCache(time.Minute)
+		cache.now = func() time.Time {
+			return now
+		}
+		expectedErr := errors.New("foo")
+		var numCalled int
+		failing := func() error {
+			return Retry(2, func() error {
+				numCalled++
+				return expectedErr
+			})
+		}
+
+		Convey("Caches give-ups, and fails fast with the cached error", func() {
+			err := cache.Do("foo", failing)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(err, ShouldWrap, expectedErr)
+			So(numCalled, ShouldEqual, 3)
+
+			cachedErr := cache.Do("foo", failing)
+			So(cachedErr, ShouldEqual, err)
+			So(cache.Get("foo"), ShouldEqual, err)
+			So(numCalled, ShouldEqual, 3)
+
+			Convey("Keys are cached separately", func() {
+				So(cache.Do("bar", failing), ShouldNotBeNil)
+				So(numCalled, ShouldEqual, 6)
+			})
+
+			Convey("The cached error expires after the TTL", func() {
+				now = now.Add(time.Minute)
+				So(cache.Get("foo"), ShouldBeNil)
+				So(cache.Len(), ShouldEqual, 0)
+				So(cache.Do("foo", failing), ShouldNotBeNil)
+				So(numCalled, ShouldEqual, 6)
+			})
+
+			Convey("Expired entries are removed when caching another error", func() {
+				now = now.Add(time.Minute)
+				So(cache.Do("bar", failing), ShouldNotBeNil)
+				So(cache.Len(), ShouldEqual, 1)
+			})
+
+			Convey("Purge() removes the cached error of a key", func() {
+				cache.Purge("foo")
+				So(cache.Get("foo"), ShouldBeNil)
+				So(cache.Do("foo", failing), ShouldNotBeNil)
+				So(numCalled, ShouldEqual, 6)
+			})
+
+			Convey("PurgeAll() removes all cached errors", func() {
+				So(cache.Do("bar", failing), ShouldNotBeNil)
+				cache.PurgeAll()
+				So(cache.Len(), ShouldEqual, 0)
+			})
+		})
+
+		Convey("Does not cache successes or errors that are not give-ups", func() {
+			So(cache.Do("foo", func() error {
+				return nil
+			}), ShouldBeNil)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			So(cache.Do("foo", func() error {
+				return RetryCtx(ctx, 2, func() error {
+					return expectedErr
+				})
+			}), ShouldEqual, context.Canceled)
+
+			So(cache.Do("foo", func() error {
+				return expectedErr
+			}), ShouldEqual, expectedErr)
+
+			So(cache.Len(), ShouldEqual, 0)
+		})
+
+		Convey("Caches give-ups with an attempt history", func() {
+			err := cache.Do("foo", func() error {
+				return NewNoDelayRetrier(WithAttemptHistory()).Retry(1, func() error {
+					return expectedErr
+				})
+			})
+			So(cache.Get("foo"), ShouldEqual, err)
+		})
+	})
+}
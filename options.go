@@ -0,0 +1,237 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Option configures a retrier.
+type Option func(*config)
+
+// config holds the settings of a retrier that can be changed using options.
+type config struct {
+	name        string
+	maxAttempts int
+	classifier  Classifier
+	stopOn      []error
+	pacer       *Pacer
+	joinErrors  bool
+	budget      *ErrorBudget
+	pressure    *RetryPressure
+	priority    Priority
+	maxCost     float64
+	cost        func(attempt int, err error) float64
+	maxElapsed  time.Duration
+	delayFunc   func(attempt int, lastErr error) time.Duration
+	delayHook   func(attempt int, proposed time.Duration, err error) time.Duration
+	maxDelay    time.Duration
+	jitter      JitterMode
+	history     bool
+	clock       Clock
+	sem         Semaphore
+	semWeight   int64
+	lease       func(ctx context.Context) (release func(), err error)
+
+	randomizationFactor float64
+	resetAfter          time.Duration
+	warmUp              int
+
+	preCheck         func(ctx context.Context) error
+	preCheckInterval time.Duration
+
+	successStore SuccessStore
+	dedupWindow  time.Duration
+
+	coordStore CoordinationStore
+	dependency string
+	cooldown   time.Duration
+
+	backoffStore BackoffStore
+	backoffKey   string
+
+	isExpired func(err error) bool
+	refresh   func(ctx context.Context) error
+
+	softLimit    int
+	trickleDelay time.Duration
+	onSoftLimit  func(SoftLimitEvent)
+
+	suspendThreshold time.Duration
+	forgiveSuspend   bool
+	onSuspend        func(SuspendEvent)
+
+	milestones  []float64
+	onMilestone func(MilestoneEvent)
+
+	onRetry   func(RetryEvent)
+	notify    func(err error, sleeping time.Duration)
+	onSuccess func(SuccessEvent)
+	onError   func(ErrorEvent)
+	hooks     []Hooks
+	logHook   LogHook
+	metrics   *Metrics
+
+	attemptFields func(ctx context.Context, attempt int, err error) map[string]string
+	errorLabel    func(err error) string
+	retryBudget   *Budget
+
+	strictStop bool
+
+	concurrentAlternatives bool
+
+	// beforeSleep is called right before every sleep between attempts, for use within the package, without taking
+	// the hooks of users.
+	beforeSleep func(numAttempts int, sleeping time.Duration)
+	// afterEnd is called with the reason a retry loop ended, like beforeSleep for use within the package.
+	afterEnd func(reason Reason)
+
+	// stats keeps the statistics of the retrier, if it has any.
+	stats *retrierStats
+
+	// sleepAfterLastAttempt makes the retrier also sleep after the last attempt failed. For backwards compatibility,
+	// RetryWithDelay does this.
+	sleepAfterLastAttempt bool
+}
+
+// newConfig returns a config with the given options applied.
+func newConfig(opts []Option) config {
+	cfg := config{stats: &retrierStats{}}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WithMaxAttempts makes the retrier make at max the given number of attempts in total, including the first one.
+// Unlike the numTimes argument of the retry methods, which is the number of retries and thus makes numTimes+1 calls,
+// WithMaxAttempts(3) means exactly 3 calls at max. Pass Forever as numTimes to let the maximum number of attempts alone
+// decide. Otherwise, the lowest of the two wins.
+func WithMaxAttempts(n int) Option {
+	return func(cfg *config) {
+		cfg.maxAttempts = n
+	}
+}
+
+// WithJoinedErrors makes the retrier return the errors of all attempts, joined using errors.Join, instead of only
+// the error of the last attempt when the maximum number of retries is reached.
+func WithJoinedErrors() Option {
+	return func(cfg *config) {
+		cfg.joinErrors = true
+	}
+}
+
+// WithStrictStop makes RetryWithStop and RetryWithStopCtx return an *Error matching ErrMaxRetriesExceeded when the
+// maximum number of retries is reached without `stop` being called, even if the last attempt returned nil. Without
+// it, nil is returned in that case, which can't be told apart from `stop` being called after a successful attempt.
+func WithStrictStop() Option {
+	return func(cfg *config) {
+		cfg.strictStop = true
+	}
+}
+
+// WithCostBudget makes the retrier call the given cost function after every attempt, and stop retrying once the total
+// cost of all attempts reaches the given maximum. The cost function receives the attempt number, counting from 1, and
+// the error of the attempt, and returns the cost of the attempt in any unit, like API credits or dollars.
+// When the budget is used up, an *Error matching ErrCostBudgetExceeded is returned.
+func WithCostBudget(maxCost float64, cost func(attempt int, err error) float64) Option {
+	return func(cfg *config) {
+		cfg.maxCost = maxCost
+		cfg.cost = cost
+	}
+}
+
+// WithMaxElapsedTime makes the retrier stop retrying once the given time has passed since the first attempt started,
+// regardless of the number of retries left. It also stops if sleeping for the next delay would exceed the maximum.
+// When the maximum is reached, an *Error matching ErrMaxElapsedTimeExceeded is returned.
+func WithMaxElapsedTime(maxElapsed time.Duration) Option {
+	return func(cfg *config) {
+		cfg.maxElapsed = maxElapsed
+	}
+}
+
+// WithAttemptHistory makes the retrier keep a record of every attempt, and return an *AttemptsError containing them
+// instead of an *Error when it gives up.
+func WithAttemptHistory() Option {
+	return func(cfg *config) {
+		cfg.history = true
+	}
+}
+
+// WithAttemptFields makes the retrier call the given function after every attempt, and record the key/value pairs it
+// returns, like the host, shard or request ID the attempt used, as the Fields of the attempt in the *AttemptsError. The
+// function receives the context of the retry loop, the number of the attempt, counting from 1, and its error. It is
+// only called if WithAttemptHistory is used as well.
+func WithAttemptFields(fields func(ctx context.Context, attempt int, err error) map[string]string) Option {
+	return func(cfg *config) {
+		cfg.attemptFields = fields
+	}
+}
+
+// WithDelayFunc makes the retrier sleep for the delay returned by the given function after every failed attempt,
+// instead of the delay of its own backoff. The function receives the number of the failed attempt, counting from 1,
+// and its error, so it can implement any schedule, like a lookup table or error-dependent delays. The max delay and
+// jitter still apply.
+func WithDelayFunc(delayFunc func(attempt int, lastErr error) time.Duration) Option {
+	return func(cfg *config) {
+		cfg.delayFunc = delayFunc
+	}
+}
+
+// WithDelayHook makes the retrier call the given hook before every sleep, with the number of the failed attempt,
+// counting from 1, the delay it proposes to sleep for and the error of the attempt. The retrier sleeps for the delay
+// returned by the hook instead, e.g. to honor an application-specific throttle signal, without having to implement a
+// whole BackoffStrategy. The max delay, jitter and delays requested by classifiers are applied to the proposed delay
+// already. The maximum elapsed time and deadlines are checked against the returned delay.
+func WithDelayHook(hook func(attempt int, proposed time.Duration, err error) time.Duration) Option {
+	return func(cfg *config) {
+		cfg.delayHook = hook
+	}
+}
+
+// WithWarmUp makes the retrier retry right away after the first numFailures failed attempts, before backoff engages,
+// starting from the first delay. This suits dependencies with known brief blips, like connection pool churn, where
+// an immediate retry almost always succeeds. Delays requested by a classifier using RetryAfter are still honored.
+func WithWarmUp(numFailures int) Option {
+	return func(cfg *config) {
+		cfg.warmUp = numFailures
+	}
+}
+
+// WithMaxDelay makes the retrier cap the delay before every retry at the given maximum, at which a growing backoff
+// plateaus. 0 means no maximum.
+func WithMaxDelay(maxDelay time.Duration) Option {
+	return func(cfg *config) {
+		cfg.maxDelay = maxDelay
+	}
+}
+
+// SoftLimitEvent describes the soft limit of a retry loop being reached.
+type SoftLimitEvent struct {
+	// Operation is the name of the operation, as set using WithName.
+	Operation string
+	// NumAttempts is the number of attempts that were made.
+	NumAttempts int
+	// Err is the error of the last attempt.
+	Err error
+}
+
+// WithSoftLimit sets a soft limit on the number of attempts, next to the hard limit given by the number of times to
+// retry. Once the given number of attempts failed, the given hook is called once, e.g. to alert that something is
+// persistently wrong, and from then on the retrier keeps retrying at a trickle, sleeping for the given trickle delay
+// before every retry. This is useful for long-lived reconcile loops that retry forever. The hook may be nil.
+func WithSoftLimit(numAttempts int, trickleDelay time.Duration, hook func(SoftLimitEvent)) Option {
+	return func(cfg *config) {
+		cfg.softLimit = numAttempts
+		cfg.trickleDelay = trickleDelay
+		cfg.onSoftLimit = hook
+	}
+}
+
+// WithName tags the retry loops of the retrier with the given operation name, e.g. "charge-card". The name is set as
+// the Operation of the returned *Error, prefixes its message, and is passed to hooks, so that their output can be
+// attributed to an operation.
+func WithName(name string) Option {
+	return func(cfg *config) {
+		cfg.name = name
+	}
+}
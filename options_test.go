@@ -0,0 +1,414 @@
+package retry
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithJoinedErrors(t *testing.T) {
+	Convey("WithJoinedErrors()", t, func() {
+		retrier := NewBackOffRetrier(0, 1, WithJoinedErrors())
+		errDNS := errors.New("dns")
+		errTimeout := errors.New("timeout")
+		errInternal := errors.New("internal")
+
+		Convey("If the maximum number of tries is reached, returns the errors of all attempts", func() {
+			errs := []error{errDNS, errTimeout, errInternal}
+			var numCalled int
+			err := retrier.Retry(2, func() error {
+				numCalled++
+				return errs[numCalled-1]
+			})
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(err, ShouldWrap, errDNS)
+			So(err, ShouldWrap, errTimeout)
+			So(err, ShouldWrap, errInternal)
+			So(err.Error(), ShouldEqual, "max retries exceeded: dns\ntimeout\ninternal")
+		})
+
+		Convey("Does not include attempts that returned nil", func() {
+			var numCalled int
+			err := retrier.RetryWithStop(2, func(stop func()) error {
+				numCalled++
+				if numCalled == 2 {
+					return nil
+				}
+				return errDNS
+			})
+			So(err.Error(), ShouldEqual, "max retries exceeded: dns\ndns")
+		})
+
+		Convey("If an attempt succeeds, returns nil", func() {
+			err := retrier.Retry(2, func() error {
+				return nil
+			})
+			So(err, ShouldBeNil)
+		})
+	})
+}
+
+func TestWithCostBudget(t *testing.T) {
+	Convey("WithCostBudget()", t, func() {
+		var costs []float64
+		retrier := NewBackOffRetrier(0, 1, WithCostBudget(5, func(attempt int, err error) float64 {
+			costs = append(costs, float64(attempt))
+			return float64(attempt)
+		}))
+		var numCalled int
+
+		Convey("Stops retrying once the total cost reaches the budget", func() {
+			expectedErr := errors.New("foo")
+			err := retrier.Retry(10, func() error {
+				numCalled++
+				return expectedErr
+			})
+			So(err, ShouldWrap, ErrCostBudgetExceeded)
+			So(err, ShouldWrap, expectedErr)
+			So(errors.Is(err, ErrMaxRetriesExceeded), ShouldBeFalse)
+			So(err.Error(), ShouldEqual, "cost budget exceeded: foo")
+			So(numCalled, ShouldEqual, 3) // 1 + 2 + 3 >= 5
+			So(costs, ShouldResemble, []float64{1, 2, 3})
+		})
+
+		Convey("If an attempt succeeds within the budget, returns nil", func() {
+			err := retrier.Retry(10, func() error {
+				numCalled++
+				if numCalled == 2 {
+					return nil
+				}
+				return errors.New("foo")
+			})
+			So(err, ShouldBeNil)
+			So(costs, ShouldResemble, []float64{1, 2})
+		})
+	})
+}
+
+func TestWithMaxElapsedTime(t *testing.T) {
+	Convey("WithMaxElapsedTime()", t, func() {
+		var numCalled int
+
+		Convey("Stops retrying once the maximum elapsed time is reached", func() {
+			retrier := NewBackOffRetrier(time.Millisecond, 1, WithMaxElapsedTime(50*time.Millisecond))
+			startTime := time.Now()
+
+			expectedErr := errors.New("foo")
+			err := retrier.Retry(Forever, func() error {
+				numCalled++
+				time.Sleep(10 * time.Millisecond)
+				return expectedErr
+			})
+			So(err, ShouldWrap, ErrMaxElapsedTimeExceeded)
+			So(err, ShouldWrap, expectedErr)
+			So(numCalled, ShouldBeBetween, 2, 6)
+			So(time.Since(startTime), ShouldBeLessThan, 100*time.Millisecond)
+		})
+
+		Convey("Does not sleep past the maximum elapsed time", func() {
+			retrier := NewBackOffRetrier(time.Second, 1, WithMaxElapsedTime(50*time.Millisecond))
+			startTime := time.Now()
+
+			err := retrier.Retry(10, func() error {
+				numCalled++
+				return errors.New("foo")
+			})
+			So(err, ShouldWrap, ErrMaxElapsedTimeExceeded)
+			So(numCalled, ShouldEqual, 1)
+			So(time.Since(startTime), ShouldBeLessThan, 50*time.Millisecond)
+		})
+	})
+}
+
+func TestWithMaxDelay(t *testing.T) {
+	Convey("WithMaxDelay()", t, func() {
+		Convey("Caps the delays of any strategy at the maximum", func() {
+			var prevs []time.Duration
+			retrier := NewStrategyRetrier(BackoffFunc(func(retry int, prev time.Duration) time.Duration {
+				prevs = append(prevs, prev)
+				return time.Duration(retry) * time.Millisecond
+			}), WithMaxDelay(2*time.Millisecond))
+
+			err := retrier.Retry(3, func() error {
+				return errors.New("foo")
+			})
+			var retryErr *Error
+			So(errors.As(err, &retryErr), ShouldBeTrue)
+			So(prevs, ShouldResemble, []time.Duration{0, time.Millisecond, 2 * time.Millisecond})
+			So(retryErr.TotalSlept, ShouldBeGreaterThanOrEqualTo, 5*time.Millisecond)
+		})
+	})
+}
+
+func TestWithSoftLimit(t *testing.T) {
+	Convey("WithSoftLimit()", t, func() {
+		var numCalled int
+		var hookCalls []SoftLimitEvent
+		expectedErr := errors.New("foo")
+		hook := func(event SoftLimitEvent) {
+			hookCalls = append(hookCalls, event)
+		}
+
+		Convey("Calls the hook once the soft limit is reached, and retries at a trickle until the hard limit", func() {
+			retrier := NewStrategyRetrier(NoBackoff, WithSoftLimit(3, 10*time.Millisecond, hook), WithName("reconcile"))
+			startTime := time.Now()
+			err := retrier.Retry(4, func() error {
+				numCalled++
+				return expectedErr
+			})
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(numCalled, ShouldEqual, 5)
+			So(hookCalls, ShouldResemble, []SoftLimitEvent{{Operation: "reconcile", NumAttempts: 3, Err: expectedErr}})
+			// Attempts 1-3 without delay, then 2 trickle delays before attempts 4 and 5.
+			So(time.Since(startTime), ShouldBeBetween, 20*time.Millisecond, 100*time.Millisecond)
+		})
+
+		Convey("Does not call the hook if the callback succeeds before the soft limit", func() {
+			retrier := NewStrategyRetrier(NoBackoff, WithSoftLimit(3, time.Hour, hook))
+			err := retrier.Retry(Forever, func() error {
+				numCalled++
+				if numCalled == 3 {
+					return nil
+				}
+				return expectedErr
+			})
+			So(err, ShouldBeNil)
+			So(hookCalls, ShouldBeEmpty)
+		})
+
+		Convey("The hook may be nil", func() {
+			retrier := NewStrategyRetrier(NoBackoff, WithSoftLimit(1, time.Millisecond, nil))
+			err := retrier.Retry(2, func() error {
+				return expectedErr
+			})
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+		})
+	})
+}
+
+func TestWithName(t *testing.T) {
+	Convey("WithName()", t, func() {
+		expectedErr := errors.New("foo")
+
+		Convey("Sets the operation of the returned error, and prefixes its message", func() {
+			err := NewNoDelayRetrier(WithName("charge-card")).Retry(1, func() error {
+				return expectedErr
+			})
+			var retryErr *Error
+			So(errors.As(err, &retryErr), ShouldBeTrue)
+			So(retryErr.Operation, ShouldEqual, "charge-card")
+			So(err.Error(), ShouldEqual, "charge-card: max retries exceeded: foo")
+		})
+
+		Convey("Without a name, the message is not prefixed", func() {
+			err := NewNoDelayRetrier().Retry(1, func() error {
+				return expectedErr
+			})
+			So(err.Error(), ShouldEqual, "max retries exceeded: foo")
+		})
+	})
+}
+
+func TestWithDelayFunc(t *testing.T) {
+	Convey("WithDelayFunc()", t, func() {
+		errSlow := errors.New("slow down")
+		errOther := errors.New("other")
+		type call struct {
+			attempt int
+			err     error
+		}
+		var calls []call
+		clock := &fakeClock{now: time.Now()}
+		delays := []time.Duration{time.Second, 5 * time.Second, time.Minute}
+		retrier := NewBackOffRetrier(time.Hour, 2, WithClock(clock), WithDelayFunc(func(attempt int, lastErr error) time.Duration {
+			calls = append(calls, call{attempt: attempt, err: lastErr})
+			if errors.Is(lastErr, errSlow) {
+				return 10 * time.Minute
+			}
+			return delays[attempt-1]
+		}))
+
+		Convey("Sleeps for the delays returned by the function instead of the backoff", func() {
+			errs := []error{errOther, errSlow, errOther, errOther}
+			var numCalled int
+			err := retrier.Retry(3, func() error {
+				numCalled++
+				return errs[numCalled-1]
+			})
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(calls, ShouldResemble, []call{{1, errOther}, {2, errSlow}, {3, errOther}})
+			So(clock.sleeps, ShouldResemble, []time.Duration{time.Second, 10 * time.Minute, time.Minute})
+		})
+
+		Convey("The max delay still applies", func() {
+			retrier := NewNoDelayRetrier(WithClock(clock), WithMaxDelay(time.Minute), WithDelayFunc(func(int, error) time.Duration {
+				return time.Hour
+			}))
+			_ = retrier.Retry(1, func() error {
+				return errOther
+			})
+			So(clock.sleeps, ShouldResemble, []time.Duration{time.Minute})
+		})
+	})
+}
+
+func TestWithDelayHook(t *testing.T) {
+	Convey("WithDelayHook()", t, func() {
+		errThrottled := errors.New("throttled")
+		type call struct {
+			attempt  int
+			proposed time.Duration
+			err      error
+		}
+		var calls []call
+		clock := &fakeClock{now: time.Now()}
+		retrier := NewBackOffRetrier(time.Second, 2, WithClock(clock), WithDelayHook(func(attempt int, proposed time.Duration, err error) time.Duration {
+			calls = append(calls, call{attempt: attempt, proposed: proposed, err: err})
+			if errors.Is(err, errThrottled) {
+				return time.Minute
+			}
+			return proposed
+		}))
+		errOther := errors.New("other")
+
+		Convey("Sleeps for the delays returned by the hook instead of the proposed ones", func() {
+			errs := []error{errOther, errThrottled, errOther, errOther}
+			var numCalled int
+			err := retrier.Retry(3, func() error {
+				numCalled++
+				return errs[numCalled-1]
+			})
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(calls, ShouldResemble, []call{{1, time.Second, errOther}, {2, 2 * time.Second, errThrottled}, {3, 4 * time.Second, errOther}})
+			So(clock.sleeps, ShouldResemble, []time.Duration{time.Second, time.Minute, 4 * time.Second})
+		})
+
+		Convey("The maximum elapsed time is checked against the returned delay", func() {
+			retrier := NewNoDelayRetrier(WithClock(clock), WithMaxElapsedTime(time.Minute), WithDelayHook(func(int, time.Duration, error) time.Duration {
+				return time.Hour
+			}))
+			err := retrier.Retry(1, func() error {
+				return errOther
+			})
+			So(err, ShouldWrap, ErrMaxElapsedTimeExceeded)
+			So(clock.sleeps, ShouldBeEmpty)
+		})
+	})
+}
+
+func TestWithMaxAttempts(t *testing.T) {
+	Convey("WithMaxAttempts()", t, func() {
+		var numCalled int
+		fail := func() error {
+			numCalled++
+			return errors.New("foo")
+		}
+
+		Convey("Makes exactly the given number of attempts at max", func() {
+			err := NewNoDelayRetrier(WithMaxAttempts(3)).Retry(Forever, fail)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(numCalled, ShouldEqual, 3)
+		})
+
+		Convey("The lowest of the maximum number of attempts and numTimes+1 wins", func() {
+			_ = NewNoDelayRetrier(WithMaxAttempts(3)).Retry(10, fail)
+			So(numCalled, ShouldEqual, 3)
+
+			numCalled = 0
+			_ = NewNoDelayRetrier(WithMaxAttempts(3)).Retry(1, fail)
+			So(numCalled, ShouldEqual, 2)
+		})
+
+		Convey("Stops as soon as an attempt succeeds", func() {
+			err := NewNoDelayRetrier(WithMaxAttempts(3)).Retry(Forever, func() error {
+				numCalled++
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(numCalled, ShouldEqual, 1)
+		})
+
+		Convey("Applies to RetryWithStop", func() {
+			_ = NewNoDelayRetrier(WithMaxAttempts(2)).RetryWithStop(Forever, func(func()) error {
+				numCalled++
+				return nil
+			})
+			So(numCalled, ShouldEqual, 2)
+		})
+	})
+}
+
+func TestWithWarmUp(t *testing.T) {
+	Convey("WithWarmUp()", t, func() {
+		clock := &fakeClock{now: time.Now()}
+		var numCalled int
+		fail := func() error {
+			numCalled++
+			return errors.New("foo")
+		}
+
+		Convey("Retries right away after the first failures, then backs off from the first delay", func() {
+			retrier := NewBackOffRetrier(time.Second, 2, WithClock(clock), WithWarmUp(2))
+			err := retrier.Retry(4, fail)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(numCalled, ShouldEqual, 5)
+			So(clock.sleeps, ShouldResemble, []time.Duration{time.Second, 2 * time.Second})
+		})
+
+		Convey("Honors RetryAfter during the warm-up", func() {
+			retrier := NewBackOffRetrier(time.Second, 2, WithClock(clock), WithWarmUp(2), WithClassifier(ClassifierFunc(func(error) Action {
+				return RetryAfter(time.Minute)
+			})))
+			_ = retrier.Retry(1, fail)
+			So(clock.sleeps, ShouldResemble, []time.Duration{time.Minute})
+		})
+
+		Convey("Can be set on a policy", func() {
+			p := NewPolicy().WarmUp(2).Build()
+			So(p.WarmUp(), ShouldEqual, 2)
+			So(p.retrier().cfg.warmUp, ShouldEqual, 2)
+		})
+	})
+}
+
+func TestWithStrictStop(t *testing.T) {
+	Convey("WithStrictStop()", t, func() {
+		retrier := NewNoDelayRetrier(WithStrictStop())
+		var numCalled int
+
+		Convey("If stop is never called, returns an *Error, even if the last attempt returned nil", func() {
+			err := retrier.RetryWithStop(2, func(stop func()) error {
+				numCalled++
+				return nil
+			})
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(numCalled, ShouldEqual, 3)
+
+			var retryErr *Error
+			So(errors.As(err, &retryErr), ShouldBeTrue)
+			So(retryErr.NumAttempts, ShouldEqual, 3)
+			So(retryErr.Unwrap(), ShouldBeNil)
+		})
+
+		Convey("If stop is called, returns the error of the attempt", func() {
+			err := retrier.RetryWithStop(2, func(stop func()) error {
+				numCalled++
+				if numCalled == 2 {
+					stop()
+				}
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(numCalled, ShouldEqual, 2)
+		})
+
+		Convey("Does not affect loops that stop on nil", func() {
+			err := retrier.Retry(2, func() error {
+				return nil
+			})
+			So(err, ShouldBeNil)
+		})
+	})
+}
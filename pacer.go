@@ -0,0 +1,51 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Pacer releases retries at a fixed total rate. When shared by many retry loops, for example those retrying the
+// items of a batch job, it bounds the aggregate retry throughput of all of them, rather than each loop waiting for a
+// timer of its own.
+type Pacer struct {
+	ticker *time.Ticker
+}
+
+// NewPacer returns a new pacer that releases the given number of retries per second, in total. It returns an error
+// matching ErrInvalidConfig if the rate is not positive, or so high that the interval between retries would be less
+// than a nanosecond. The pacer must be stopped using Stop when it is no longer needed.
+func NewPacer(retriesPerSecond float64) (*Pacer, error) {
+	if !(retriesPerSecond > 0) {
+		return nil, fmt.Errorf("%w: retries per second must be positive, got %v", ErrInvalidConfig, retriesPerSecond)
+	}
+	interval := time.Duration(float64(time.Second) / retriesPerSecond)
+	if interval <= 0 {
+		return nil, fmt.Errorf("%w: retries per second must be at most 1e9, got %v", ErrInvalidConfig, retriesPerSecond)
+	}
+	return &Pacer{ticker: time.NewTicker(interval)}, nil
+}
+
+// Wait blocks until the pacer releases a retry, or until the given context is done.
+func (p *Pacer) Wait(ctx context.Context) error {
+	select {
+	case <-p.ticker.C:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// Stop stops the pacer. No more retries are released after it is stopped.
+func (p *Pacer) Stop() {
+	p.ticker.Stop()
+}
+
+// WithPacer makes the retrier wait for the given pacer to release a retry, instead of sleeping for a delay of its own.
+// Delays requested by a classifier using RetryAfter are still honored before waiting for the pacer.
+func WithPacer(pacer *Pacer) Option {
+	return func(cfg *config) {
+		cfg.pacer = pacer
+	}
+}
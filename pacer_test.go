@@ -0,0 +1,73 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithPacer(t *testing.T) {
+	Convey("WithPacer()", t, func() {
+		pacer, err := NewPacer(100) // One retry per 10ms.
+		So(err, ShouldBeNil)
+		defer pacer.Stop()
+		retrier := NewBackOffRetrier(time.Hour, 2, WithPacer(pacer))
+
+		Convey("Releases retries of all retry loops at the pacer's rate", func() {
+			startTime := time.Now()
+
+			var wg sync.WaitGroup
+			errs := make([]error, 3)
+			for i := range errs {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					var numCalled int
+					errs[i] = retrier.Retry(1, func() error {
+						numCalled++
+						if numCalled == 2 {
+							return nil
+						}
+						return errors.New("foo")
+					})
+				}()
+			}
+			wg.Wait()
+
+			for _, err := range errs {
+				So(err, ShouldBeNil)
+			}
+			// 3 retries at 10ms each. The back off delay of an hour is not used.
+			So(time.Since(startTime), ShouldBeGreaterThanOrEqualTo, 25*time.Millisecond)
+			So(time.Since(startTime), ShouldBeLessThan, time.Second)
+		})
+
+		Convey("If the context is done while waiting, returns the context error", func() {
+			pacer.Stop()
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+
+			err := retrier.RetryCtx(ctx, 1, func() error {
+				return errors.New("foo")
+			})
+			So(err, ShouldEqual, context.DeadlineExceeded)
+		})
+	})
+}
+
+func TestNewPacer(t *testing.T) {
+	Convey("NewPacer()", t, func() {
+		Convey("Returns ErrInvalidConfig for rates that would break the ticker", func() {
+			for _, rate := range []float64{0, -1, math.NaN(), 1e10, math.Inf(1)} {
+				pacer, err := NewPacer(rate)
+				So(pacer, ShouldBeNil)
+				So(err, ShouldWrap, ErrInvalidConfig)
+			}
+		})
+	})
+}
@@ -0,0 +1,31 @@
+package retry
+
+import (
+	"context"
+)
+
+// RetryPages fetches the pages of a paginated API using the given fetch function, starting at the given cursor, and
+// retries every page at max the given number of times using the given retryer. The fetch function receives the cursor
+// of the page to fetch and returns the cursor of the next page, or the zero value if it fetched the last page.
+//
+// When fetching a page fails, only that page is retried, from its cursor, instead of starting over at the first page.
+// Backoff thus applies per page. If a page can't be fetched, its cursor is returned with the error of the retryer, so
+// that fetching can be resumed from it later.
+func RetryPages[C comparable](ctx context.Context, r Retryer, numTimesPerPage int, cursor C, fetch func(ctx context.Context, cursor C) (next C, err error)) (C, error) {
+	var zero C
+	for {
+		var next C
+		err := r.RetryCtx(ctx, numTimesPerPage, func() error {
+			var err error
+			next, err = fetch(ctx, cursor)
+			return err
+		})
+		if err != nil {
+			return cursor, err
+		}
+		if next == zero {
+			return zero, nil
+		}
+		cursor = next
+	}
+}
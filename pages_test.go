@@ -0,0 +1,75 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRetryPages(t *testing.T) {
+	Convey("RetryPages()", t, func() {
+		ctx := context.Background()
+		clock := &fakeClock{now: time.Now()}
+		retrier := NewBackOffRetrier(time.Second, 2, WithClock(clock))
+		expectedErr := errors.New("foo")
+		var fetched []int
+		var numFailures int
+		// fetch fetches pages 0 to 3, failing numFailures times on page 2.
+		fetch := func(_ context.Context, cursor int) (int, error) {
+			if cursor == 2 && numFailures > 0 {
+				numFailures--
+				return 0, expectedErr
+			}
+			fetched = append(fetched, cursor)
+			if cursor == 3 {
+				return 0, nil
+			}
+			return cursor + 1, nil
+		}
+
+		Convey("Fetches all pages", func() {
+			cursor, err := RetryPages(ctx, retrier, 3, 0, fetch)
+			So(err, ShouldBeNil)
+			So(cursor, ShouldEqual, 0)
+			So(fetched, ShouldResemble, []int{0, 1, 2, 3})
+		})
+
+		Convey("Retries a failed page from its cursor, backing off per page", func() {
+			numFailures = 2
+			_, err := RetryPages(ctx, retrier, 3, 0, fetch)
+			So(err, ShouldBeNil)
+			So(fetched, ShouldResemble, []int{0, 1, 2, 3})
+			So(clock.sleeps, ShouldResemble, []time.Duration{time.Second, 2 * time.Second})
+		})
+
+		Convey("If a page can't be fetched, returns its cursor and the error", func() {
+			numFailures = 10
+			cursor, err := RetryPages(ctx, retrier, 1, 0, fetch)
+			So(err, ShouldWrap, expectedErr)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(cursor, ShouldEqual, 2)
+
+			numFailures = 0
+			_, err = RetryPages(ctx, retrier, 1, cursor, fetch)
+			So(err, ShouldBeNil)
+			So(fetched, ShouldResemble, []int{0, 1, 2, 3})
+		})
+
+		Convey("Supports any comparable cursor", func() {
+			var pages []string
+			_, err := RetryPages(ctx, retrier, 0, "", func(_ context.Context, cursor string) (string, error) {
+				pages = append(pages, cursor)
+				if n, _ := strconv.Atoi(cursor); n < 2 {
+					return strconv.Itoa(n + 1), nil
+				}
+				return "", nil
+			})
+			So(err, ShouldBeNil)
+			So(pages, ShouldResemble, []string{"", "1", "2"})
+		})
+	})
+}
@@ -0,0 +1,185 @@
+package retry
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Policy describes how to retry: how many attempts to make, how long to back off between them, and any other
+// options. Policies are immutable and can be reused and shared between goroutines. Use NewPolicy to build one.
+type Policy struct {
+	maxAttempts  int
+	initialDelay time.Duration
+	multiplier   float64
+	maxDelay     time.Duration
+	jitter       JitterMode
+	warmUp       int
+	opts         []Option
+}
+
+// PolicyBuilder builds a Policy.
+type PolicyBuilder struct {
+	policy Policy
+}
+
+// NewPolicy returns a builder for a new policy. Unless changed, the policy makes at max 3 attempts, with an initial
+// delay of 100ms that is doubled after every failed attempt, without a maximum delay.
+func NewPolicy() *PolicyBuilder {
+	return &PolicyBuilder{policy: Policy{
+		maxAttempts:  3,
+		initialDelay: 100 * time.Millisecond,
+		multiplier:   2,
+	}}
+}
+
+// MaxAttempts sets the maximum number of attempts, including the first one. Pass Forever to retry forever. Other
+// numbers less than 1 are raised to 1 by Build, which makes a single attempt.
+func (b *PolicyBuilder) MaxAttempts(n int) *PolicyBuilder {
+	b.policy.maxAttempts = n
+	return b
+}
+
+// InitialDelay sets the delay before the first retry.
+func (b *PolicyBuilder) InitialDelay(delay time.Duration) *PolicyBuilder {
+	b.policy.initialDelay = delay
+	return b
+}
+
+// Multiplier sets the number the delay is multiplied by after every retry.
+func (b *PolicyBuilder) Multiplier(multiplier float64) *PolicyBuilder {
+	b.policy.multiplier = multiplier
+	return b
+}
+
+// MaxDelay sets the maximum delay, at which the backoff plateaus. 0 means no maximum.
+func (b *PolicyBuilder) MaxDelay(delay time.Duration) *PolicyBuilder {
+	b.policy.maxDelay = delay
+	return b
+}
+
+// Jitter sets the jitter mode, which randomizes the delays. Defaults to NoJitter.
+func (b *PolicyBuilder) Jitter(mode JitterMode) *PolicyBuilder {
+	b.policy.jitter = mode
+	return b
+}
+
+// WarmUp sets the number of failed attempts after which to retry right away, before backoff engages. Defaults to 0.
+func (b *PolicyBuilder) WarmUp(numFailures int) *PolicyBuilder {
+	b.policy.warmUp = numFailures
+	return b
+}
+
+// With adds the given options to the policy.
+func (b *PolicyBuilder) With(opts ...Option) *PolicyBuilder {
+	b.policy.opts = append(b.policy.opts, opts...)
+	return b
+}
+
+// Build returns the policy. The builder can be used to build more policies afterwards, without affecting the
+// returned one.
+func (b *PolicyBuilder) Build() Policy {
+	p := b.policy
+	if p.maxAttempts != Forever && p.maxAttempts < 1 {
+		p.maxAttempts = 1
+	}
+	p.opts = slices.Clip(slices.Clone(p.opts))
+	return p
+}
+
+// MaxAttempts returns the maximum number of attempts, including the first one. It is Forever for retrying forever.
+func (p Policy) MaxAttempts() int {
+	return p.maxAttempts
+}
+
+// InitialDelay returns the delay before the first retry.
+func (p Policy) InitialDelay() time.Duration {
+	return p.initialDelay
+}
+
+// Multiplier returns the number the delay is multiplied by after every retry.
+func (p Policy) Multiplier() float64 {
+	return p.multiplier
+}
+
+// MaxDelay returns the maximum delay, or 0 if there is none.
+func (p Policy) MaxDelay() time.Duration {
+	return p.maxDelay
+}
+
+// Jitter returns the jitter mode.
+func (p Policy) Jitter() JitterMode {
+	return p.jitter
+}
+
+// WarmUp returns the number of failed attempts after which to retry right away, before backoff engages.
+func (p Policy) WarmUp() int {
+	return p.warmUp
+}
+
+// With returns a copy of the policy with the given options added.
+func (p Policy) With(opts ...Option) Policy {
+	p.opts = slices.Concat(p.opts, opts)
+	return p
+}
+
+// Retry retries the given callback according to the policy.
+// It stops as soon as a `nil` error is returned.
+func (p Policy) Retry(cb func() error) error {
+	return p.RetryCtx(context.Background(), cb)
+}
+
+// RetryCtx retries the given callback according to the policy.
+// It stops as soon as a `nil` error is returned.
+func (p Policy) RetryCtx(ctx context.Context, cb func() error) error {
+	return p.retrier().RetryCtx(ctx, p.numTimes(), cb)
+}
+
+// Do retries the given callback according to the policy.
+// It stops as soon as a `nil` error is returned. Every attempt receives a child context of the given context that
+// carries the attempt, which can be retrieved using AttemptFromContext. See the package level Do.
+func (p Policy) Do(ctx context.Context, cb func(ctx context.Context) error) error {
+	return Do(ctx, p.retrier(), p.numTimes(), cb)
+}
+
+// DoWithStop retries the given callback according to the policy.
+// It stops only when `stop` is called. Every attempt receives a child context of the given context that carries the
+// attempt, which is cancelled as soon as the loop decides to stop. See the package level DoWithStop.
+func (p Policy) DoWithStop(ctx context.Context, cb func(ctx context.Context, stop func()) error) error {
+	return DoWithStop(ctx, p.retrier(), p.numTimes(), cb)
+}
+
+// RetryWithStop retries the given callback according to the policy.
+// It stops only when `stop` is called.
+func (p Policy) RetryWithStop(cb func(stop func()) error) error {
+	return p.RetryWithStopCtx(context.Background(), cb)
+}
+
+// RetryWithStopCtx retries the given callback according to the policy.
+// It stops only when `stop` is called.
+func (p Policy) RetryWithStopCtx(ctx context.Context, cb func(stop func()) error) error {
+	return p.retrier().RetryWithStopCtx(ctx, p.numTimes(), cb)
+}
+
+// numTimes returns the number of times to retry, which is negative for retrying forever.
+func (p Policy) numTimes() int {
+	if p.maxAttempts == Forever {
+		return Forever
+	}
+	return max(p.maxAttempts-1, 0)
+}
+
+// retrier returns a back off retrier configured according to the policy.
+func (p Policy) retrier() *BackOffRetrier {
+	opts := p.opts
+	if p.maxDelay > 0 {
+		opts = append(opts, WithMaxDelay(p.maxDelay))
+	}
+	if p.jitter != NoJitter {
+		opts = append(opts, WithJitter(p.jitter))
+	}
+	if p.warmUp > 0 {
+		opts = append(opts, WithWarmUp(p.warmUp))
+	}
+	return NewBackOffRetrier(p.initialDelay, p.multiplier, opts...)
+}
@@ -0,0 +1,153 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNewPolicy(t *testing.T) {
+	Convey("NewPolicy()", t, func() {
+		Convey("Has sensible defaults", func() {
+			p := NewPolicy().Build()
+			So(p.MaxAttempts(), ShouldEqual, 3)
+			So(p.InitialDelay(), ShouldEqual, 100*time.Millisecond)
+			So(p.Multiplier(), ShouldEqual, 2)
+			So(p.MaxDelay(), ShouldEqual, 0)
+			So(p.Jitter(), ShouldEqual, NoJitter)
+		})
+
+		Convey("Builds a policy with the given settings", func() {
+			p := NewPolicy().MaxAttempts(5).InitialDelay(time.Second).Multiplier(3).MaxDelay(10 * time.Second).Jitter(FullJitter).Build()
+			So(p.MaxAttempts(), ShouldEqual, 5)
+			So(p.InitialDelay(), ShouldEqual, time.Second)
+			So(p.Multiplier(), ShouldEqual, 3)
+			So(p.MaxDelay(), ShouldEqual, 10*time.Second)
+			So(p.Jitter(), ShouldEqual, FullJitter)
+			So(p.retrier().Jitter(), ShouldEqual, FullJitter)
+		})
+
+		Convey("Built policies are not affected by later changes to the builder", func() {
+			b := NewPolicy().MaxAttempts(2).With(WithJoinedErrors())
+			p := b.Build()
+			b.MaxAttempts(10).With(WithMaxElapsedTime(time.Nanosecond))
+			So(p.MaxAttempts(), ShouldEqual, 2)
+			So(p.opts, ShouldHaveLength, 1)
+		})
+
+		Convey("With() returns a copy of the policy with the given options added", func() {
+			p := NewPolicy().With(WithJoinedErrors()).Build()
+			withOpts := p.With(WithAttemptHistory())
+			So(withOpts.opts, ShouldHaveLength, 2)
+			So(p.opts, ShouldHaveLength, 1)
+		})
+	})
+}
+
+func TestPolicy(t *testing.T) {
+	Convey("Policy", t, func() {
+		p := NewPolicy().MaxAttempts(3).InitialDelay(time.Millisecond).Multiplier(10).MaxDelay(5 * time.Millisecond).Build()
+		var numCalled int
+
+		Convey("Retry() makes at max the maximum number of attempts", func() {
+			expectedErr := errors.New("foo")
+			err := p.Retry(func() error {
+				numCalled++
+				return expectedErr
+			})
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(err, ShouldWrap, expectedErr)
+			So(numCalled, ShouldEqual, 3)
+		})
+
+		Convey("Caps the delay at the maximum delay", func() {
+			var retryErr *Error
+			err := p.Retry(func() error {
+				return errors.New("foo")
+			})
+			So(errors.As(err, &retryErr), ShouldBeTrue)
+			So(retryErr.TotalSlept, ShouldBeBetween, 6*time.Millisecond, 20*time.Millisecond) // 1ms + 5ms instead of 1ms + 10ms.
+		})
+
+		Convey("RetryCtx() stops as soon as nil is returned", func() {
+			err := p.RetryCtx(context.Background(), func() error {
+				numCalled++
+				if numCalled == 2 {
+					return nil
+				}
+				return errors.New("foo")
+			})
+			So(err, ShouldBeNil)
+			So(numCalled, ShouldEqual, 2)
+		})
+
+		Convey("Do() passes the attempt in the context", func() {
+			var numbers []int
+			err := p.Do(context.Background(), func(ctx context.Context) error {
+				attempt, _ := AttemptFromContext(ctx)
+				numbers = append(numbers, attempt.Number)
+				return errors.New("foo")
+			})
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(numbers, ShouldResemble, []int{1, 2, 3})
+		})
+
+		Convey("RetryWithStop() keeps retrying until stop is called", func() {
+			err := p.RetryWithStop(func(stop func()) error {
+				numCalled++
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(numCalled, ShouldEqual, 3)
+		})
+
+		Convey("RetryWithStopCtx() returns the context error", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			err := p.RetryWithStopCtx(ctx, func(stop func()) error {
+				numCalled++
+				return nil
+			})
+			So(err, ShouldEqual, context.Canceled)
+			So(numCalled, ShouldEqual, 0)
+		})
+
+		Convey("Applies the options of the policy", func() {
+			p := NewPolicy().InitialDelay(0).With(WithJoinedErrors()).Build()
+			err := p.Retry(func() error {
+				return errors.New("foo")
+			})
+			So(err.Error(), ShouldEqual, "max retries exceeded: foo\nfoo\nfoo")
+		})
+
+		Convey("Retries forever if the maximum number of attempts is Forever", func() {
+			p := NewPolicy().MaxAttempts(Forever).InitialDelay(0).Build()
+			err := p.Retry(func() error {
+				numCalled++
+				if numCalled == 100 {
+					return nil
+				}
+				return errors.New("foo")
+			})
+			So(err, ShouldBeNil)
+			So(numCalled, ShouldEqual, 100)
+		})
+
+		Convey("Makes a single attempt if the maximum number of attempts is less than 1, but not Forever", func() {
+			for _, n := range []int{0, -2} {
+				p := NewPolicy().MaxAttempts(n).InitialDelay(0).Build()
+				So(p.MaxAttempts(), ShouldEqual, 1)
+				numCalled = 0
+				err := p.Retry(func() error {
+					numCalled++
+					return errors.New("foo")
+				})
+				So(err, ShouldWrap, ErrMaxRetriesExceeded)
+				So(numCalled, ShouldEqual, 1)
+			}
+		})
+	})
+}
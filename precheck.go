@@ -0,0 +1,60 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// defaultPreCheckInterval is the interval at which a failing pre-check is run again if the given interval isn't
+// positive.
+const defaultPreCheckInterval = time.Second
+
+// WithPreCheck makes the retrier run the given check before every attempt, e.g. to find out whether a circuit breaker
+// is closed or a valid token is available. If the check fails, the attempt is skipped without being counted, so that
+// doomed calls don't use up attempts. The error of the check is classified like the error of an attempt: if the
+// classifier aborts, the error of the check is returned. Otherwise, the check is run again after the given interval,
+// or after the delay requested using RetryAfter, until it succeeds or the context is done. An interval of 0 or less
+// defaults to 1 second.
+// The time spent waiting for the check counts against the maximum elapsed time set using WithMaxElapsedTime: once the
+// next check would exceed it, the retrier gives up with an *Error whose last error is the error of the check.
+func WithPreCheck(check func(ctx context.Context) error, interval time.Duration) Option {
+	return func(cfg *config) {
+		cfg.preCheck = check
+		cfg.preCheckInterval = interval
+		if interval <= 0 {
+			cfg.preCheckInterval = defaultPreCheckInterval
+		}
+	}
+}
+
+// waitForPreCheck runs the pre-check, if any, until it succeeds. It returns the error of the pre-check if the
+// classifier aborts, or the context error if the context is done while waiting. If waiting for the next check would
+// exceed the maximum elapsed time, counting from the given time, it returns true and the error of the pre-check.
+func (cfg *config) waitForPreCheck(ctx context.Context, clock Clock, since time.Time) (bool, error) {
+	if cfg.preCheck == nil {
+		return false, nil
+	}
+	for {
+		err := cfg.preCheck(ctx)
+		if err == nil {
+			return false, nil
+		}
+		if ctx.Err() != nil {
+			return false, context.Cause(ctx)
+		}
+		action := cfg.classify(err)
+		if action.kind == actionAbort {
+			return false, err
+		}
+		wait := cfg.preCheckInterval
+		if action.kind == actionRetryAfter && action.delay > 0 {
+			wait = action.delay
+		}
+		if cfg.maxElapsed > 0 && clock.Now().Sub(since)+wait >= cfg.maxElapsed {
+			return true, err
+		}
+		if err := clock.Sleep(ctx, wait); err != nil {
+			return false, err
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithPreCheck(t *testing.T) {
+	Convey("WithPreCheck()", t, func() {
+		errOpen := errors.New("breaker open")
+		errPermanent := errors.New("permanent")
+		var numChecked, numCalled int
+		check := func(fails int, err error) func(ctx context.Context) error {
+			return func(context.Context) error {
+				numChecked++
+				if numChecked <= fails {
+					return err
+				}
+				return nil
+			}
+		}
+
+		Convey("Skips attempts while the check fails, without counting them", func() {
+			clock := &fakeClock{now: time.Now()}
+			retrier := NewNoDelayRetrier(WithPreCheck(check(3, errOpen), time.Second), WithClock(clock))
+			err := retrier.Retry(1, func() error {
+				numCalled++
+				return errors.New("foo")
+			})
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(numCalled, ShouldEqual, 2)
+			So(numChecked, ShouldEqual, 5)
+			So(clock.sleeps, ShouldResemble, []time.Duration{time.Second, time.Second, time.Second})
+		})
+
+		Convey("Defaults an interval of 0 or less to 1 second", func() {
+			clock := &fakeClock{now: time.Now()}
+			retrier := NewNoDelayRetrier(WithPreCheck(check(2, errOpen), 0), WithClock(clock))
+			So(retrier.Retry(1, func() error { return nil }), ShouldBeNil)
+			So(clock.sleeps, ShouldResemble, []time.Duration{time.Second, time.Second})
+		})
+
+		Convey("Gives up with the error of the check once the maximum elapsed time would be exceeded", func() {
+			clock := &fakeClock{now: time.Now()}
+			retrier := NewNoDelayRetrier(
+				WithPreCheck(check(1000, errOpen), 2*time.Second),
+				WithMaxElapsedTime(5*time.Second),
+				WithClock(clock),
+			)
+			err := retrier.Retry(3, func() error {
+				numCalled++
+				return nil
+			})
+			So(err, ShouldWrap, ErrMaxElapsedTimeExceeded)
+			So(err, ShouldWrap, errOpen)
+			var retryErr *Error
+			So(errors.As(err, &retryErr), ShouldBeTrue)
+			So(retryErr.Reason, ShouldEqual, ReasonBudgetExhausted)
+			So(numCalled, ShouldEqual, 0)
+			So(clock.sleeps, ShouldResemble, []time.Duration{2 * time.Second, 2 * time.Second})
+		})
+
+		Convey("Honors the classifier", func() {
+			classifier := ClassifierFunc(func(err error) Action {
+				if errors.Is(err, errPermanent) {
+					return ActionAbort
+				}
+				return RetryAfter(time.Minute)
+			})
+
+			Convey("Aborting returns the error of the check", func() {
+				retrier := NewNoDelayRetrier(WithPreCheck(check(1, errPermanent), time.Second), WithClassifier(classifier))
+				err := retrier.Retry(3, func() error {
+					numCalled++
+					return nil
+				})
+				So(err, ShouldEqual, errPermanent)
+				So(numCalled, ShouldEqual, 0)
+			})
+
+			Convey("RetryAfter waits for the given delay", func() {
+				clock := &fakeClock{now: time.Now()}
+				retrier := NewNoDelayRetrier(WithPreCheck(check(1, errOpen), time.Second), WithClassifier(classifier), WithClock(clock))
+				err := retrier.Retry(3, func() error {
+					numCalled++
+					return nil
+				})
+				So(err, ShouldBeNil)
+				So(numCalled, ShouldEqual, 1)
+				So(clock.sleeps, ShouldResemble, []time.Duration{time.Minute})
+			})
+		})
+
+		Convey("Returns the context error if the context is done while waiting", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			retrier := NewNoDelayRetrier(WithPreCheck(check(1000, errOpen), time.Millisecond))
+			err := retrier.RetryCtx(ctx, 3, func() error {
+				numCalled++
+				return nil
+			})
+			So(err, ShouldEqual, context.DeadlineExceeded)
+			So(numCalled, ShouldEqual, 0)
+		})
+	})
+}
@@ -0,0 +1,159 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrQueueStopped is returned when enqueueing a job to a queue that was stopped or is draining.
+var ErrQueueStopped = errors.New("queue stopped")
+
+// JobResult describes the outcome of a job of a Queue.
+type JobResult struct {
+	// Name is the name the job was enqueued with.
+	Name string
+	// NumAttempts is the number of attempts made for the job.
+	NumAttempts int
+	// Err is the error returned by the retry loop of the job, or nil if it succeeded.
+	Err error
+}
+
+// QueueOption configures a Queue.
+type QueueOption func(*Queue)
+
+// WithOnJobDone makes the queue call the given hook after every job ended, e.g. to log the jobs that failed. The hook
+// may be called by multiple goroutines at once.
+func WithOnJobDone(hook func(JobResult)) QueueOption {
+	return func(q *Queue) {
+		q.onDone = hook
+	}
+}
+
+// Queue is an in-process background retry queue. Jobs are enqueued with a policy of their own, and every job backs
+// off independently. Their attempts are executed by a pool of workers: a job only occupies a worker while an attempt
+// runs, not while it backs off, so jobs that back off for a long time don't hold up the others.
+// It is safe for concurrent use.
+type Queue struct {
+	workers chan struct{} // Holds a value for every worker that is busy.
+	ctx     context.Context
+	cancel  context.CancelFunc
+	onDone  func(JobResult)
+	wg      sync.WaitGroup
+	numJobs atomic.Int64
+
+	store        Store
+	storePolicy  Policy
+	storeHandler func(ctx context.Context, job StoredJob) error
+
+	mu      sync.Mutex
+	stopped bool
+	running map[string]bool // The IDs of the durable jobs that are running.
+}
+
+// NewQueue returns a new queue that runs the attempts of its jobs using the given number of workers, which is at
+// least 1.
+func NewQueue(numWorkers int, opts ...QueueOption) *Queue {
+	q := &Queue{workers: make(chan struct{}, max(numWorkers, 1)), running: make(map[string]bool)}
+	for _, opt := range opts {
+		opt(q)
+	}
+	q.ctx, q.cancel = context.WithCancel(context.Background())
+	return q
+}
+
+// Enqueue enqueues a job, which retries the given callback according to the given policy, and returns right away. The
+// name identifies the job in its JobResult. Every attempt receives a context carrying the attempt, which can be
+// retrieved using AttemptFromContext, and which is cancelled when the queue is stopped. It returns ErrQueueStopped if
+// the queue was stopped or is draining.
+func (q *Queue) Enqueue(name string, policy Policy, cb func(ctx context.Context) error) error {
+	if !q.begin() {
+		return ErrQueueStopped
+	}
+	go q.run(name, policy, cb)
+	return nil
+}
+
+// Len returns the number of jobs that did not end yet.
+func (q *Queue) Len() int {
+	return int(q.numJobs.Load())
+}
+
+// Stop stops accepting jobs, cancels the contexts of the jobs that did not end yet, and returns once they ended.
+func (q *Queue) Stop() {
+	q.close()
+	q.cancel()
+	q.wg.Wait()
+}
+
+// Drain stops accepting jobs, and returns once all jobs ended, letting them retry as usual. If the given context is
+// done first, it returns the cause of the context, and the jobs keep running: call Stop to stop them.
+func (q *Queue) Drain(ctx context.Context) error {
+	q.close()
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		q.cancel()
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// begin registers a new job, and returns false if the queue is not accepting jobs.
+func (q *Queue) begin() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.beginLocked()
+}
+
+// beginLocked is begin for callers that hold q.mu.
+func (q *Queue) beginLocked() bool {
+	if q.stopped {
+		return false
+	}
+	q.wg.Add(1)
+	q.numJobs.Add(1)
+	return true
+}
+
+// end unregisters a job that ended.
+func (q *Queue) end() {
+	q.numJobs.Add(-1)
+	q.wg.Done()
+}
+
+// close stops accepting jobs.
+func (q *Queue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.stopped = true
+}
+
+// run runs a job.
+func (q *Queue) run(name string, policy Policy, cb func(ctx context.Context) error) {
+	defer q.end()
+	var numAttempts int
+	err := policy.With(WithLease(q.acquireWorker)).Do(q.ctx, func(ctx context.Context) error {
+		numAttempts++
+		return cb(ctx)
+	})
+	if q.onDone != nil {
+		q.onDone(JobResult{Name: name, NumAttempts: numAttempts, Err: err})
+	}
+}
+
+// acquireWorker waits for a worker to become available, and returns a function that releases it again.
+func (q *Queue) acquireWorker(ctx context.Context) (func(), error) {
+	select {
+	case q.workers <- struct{}{}:
+		return func() { <-q.workers }, nil
+	case <-ctx.Done():
+		return nil, context.Cause(ctx)
+	}
+}
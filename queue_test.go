@@ -0,0 +1,118 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestQueue(t *testing.T) {
+	Convey("Queue", t, func() {
+		policy := NewPolicy().InitialDelay(0).MaxAttempts(3).Build()
+		expectedErr := errors.New("foo")
+		var mu sync.Mutex
+		var results []JobResult
+		queue := NewQueue(2, WithOnJobDone(func(result JobResult) {
+			mu.Lock()
+			defer mu.Unlock()
+			results = append(results, result)
+		}))
+
+		Convey("Retries every job according to its own policy", func() {
+			var numCalled atomic.Int32
+			So(queue.Enqueue("a", policy, func(ctx context.Context) error {
+				if numCalled.Add(1) < 2 {
+					return expectedErr
+				}
+				return nil
+			}), ShouldBeNil)
+			So(queue.Enqueue("b", policy.With(WithMaxAttempts(1)), func(ctx context.Context) error {
+				return expectedErr
+			}), ShouldBeNil)
+			So(queue.Drain(context.Background()), ShouldBeNil)
+			So(queue.Len(), ShouldEqual, 0)
+
+			So(results, ShouldHaveLength, 2)
+			byName := map[string]JobResult{results[0].Name: results[0], results[1].Name: results[1]}
+			So(byName["a"], ShouldResemble, JobResult{Name: "a", NumAttempts: 2})
+			So(byName["b"].NumAttempts, ShouldEqual, 1)
+			So(byName["b"].Err, ShouldWrap, expectedErr)
+		})
+
+		Convey("Runs at max the given number of attempts at once", func() {
+			var running, maxRunning atomic.Int32
+			for i := 0; i < 10; i++ {
+				_ = queue.Enqueue("job", policy, func(ctx context.Context) error {
+					n := running.Add(1)
+					defer running.Add(-1)
+					for {
+						prev := maxRunning.Load()
+						if n <= prev || maxRunning.CompareAndSwap(prev, n) {
+							break
+						}
+					}
+					time.Sleep(time.Millisecond)
+					return nil
+				})
+			}
+			So(queue.Drain(context.Background()), ShouldBeNil)
+			So(maxRunning.Load(), ShouldBeBetweenOrEqual, 1, 2)
+		})
+
+		Convey("Does not occupy a worker while a job backs off", func() {
+			slow := NewPolicy().InitialDelay(time.Hour).MaxAttempts(2).Build()
+			for i := 0; i < 2; i++ {
+				_ = queue.Enqueue("backing off", slow, func(ctx context.Context) error {
+					return expectedErr
+				})
+			}
+			done := make(chan struct{})
+			_ = queue.Enqueue("quick", policy, func(ctx context.Context) error {
+				close(done)
+				return nil
+			})
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				So("the quick job did not run", ShouldBeEmpty)
+			}
+			queue.Stop()
+		})
+
+		Convey("Stop() cancels the jobs and waits for them", func() {
+			started := make(chan struct{})
+			_ = queue.Enqueue("blocking", policy, func(ctx context.Context) error {
+				close(started)
+				<-ctx.Done()
+				return ctx.Err()
+			})
+			<-started
+			queue.Stop()
+			So(queue.Len(), ShouldEqual, 0)
+			So(results, ShouldHaveLength, 1)
+			So(results[0].Err, ShouldEqual, context.Canceled)
+			So(queue.Enqueue("late", policy, func(ctx context.Context) error { return nil }), ShouldEqual, ErrQueueStopped)
+		})
+
+		Convey("Drain() returns the cause of the context if it is done first", func() {
+			started := make(chan struct{})
+			_ = queue.Enqueue("blocking", policy, func(ctx context.Context) error {
+				close(started)
+				<-ctx.Done()
+				return nil
+			})
+			<-started
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			So(queue.Drain(ctx), ShouldEqual, context.Canceled)
+			So(queue.Len(), ShouldEqual, 1)
+			So(queue.Enqueue("late", policy, func(ctx context.Context) error { return nil }), ShouldEqual, ErrQueueStopped)
+			queue.Stop()
+		})
+	})
+}
@@ -0,0 +1,58 @@
+package retry
+
+import (
+	"context"
+)
+
+// Reason describes why a retry loop ended. Errors alone don't always tell, e.g. a RetryWithStop loop returns nil both
+// when `stop` was called and when the maximum number of retries was reached after a successful attempt.
+type Reason int
+
+const (
+	// ReasonSucceeded means an attempt succeeded, in a loop that stops as soon as a `nil` error is returned.
+	ReasonSucceeded Reason = iota + 1
+	// ReasonStopped means `stop` or `abort` was called.
+	ReasonStopped
+	// ReasonMaxAttempts means the maximum number of retries was reached.
+	ReasonMaxAttempts
+	// ReasonContextDone means the context was done, or its deadline was too close to make another attempt.
+	ReasonContextDone
+	// ReasonPermanentError means an error that must not be retried ended the loop, e.g. because a classifier aborted.
+	ReasonPermanentError
+	// ReasonBudgetExhausted means an error budget, cost budget or maximum elapsed time was used up.
+	ReasonBudgetExhausted
+)
+
+// String implements fmt.Stringer.
+func (r Reason) String() string {
+	switch r {
+	case ReasonSucceeded:
+		return "succeeded"
+	case ReasonStopped:
+		return "stopped"
+	case ReasonMaxAttempts:
+		return "max attempts"
+	case ReasonContextDone:
+		return "context done"
+	case ReasonPermanentError:
+		return "permanent error"
+	case ReasonBudgetExhausted:
+		return "budget exhausted"
+	default:
+		return "unknown"
+	}
+}
+
+// endReason returns the reason a retry loop ended with the given error, if the loop didn't give up by itself.
+func endReason(ctx context.Context, err error, stopped bool) Reason {
+	switch {
+	case stopped:
+		return ReasonStopped
+	case err == nil:
+		return ReasonSucceeded
+	case ctx.Err() != nil:
+		return ReasonContextDone
+	default:
+		return ReasonPermanentError
+	}
+}
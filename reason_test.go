@@ -0,0 +1,99 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestReason(t *testing.T) {
+	Convey("Reason", t, func() {
+		var reasons []Reason
+		opts := []Option{
+			WithClock(&fakeClock{now: time.Unix(0, 0)}),
+			WithOnSuccess(func(e SuccessEvent) { reasons = append(reasons, e.Reason) }),
+			WithOnError(func(e ErrorEvent) { reasons = append(reasons, e.Reason) }),
+		}
+		retrier := NewConstantDelayRetrier(time.Second, opts...)
+		expectedErr := errors.New("foo")
+
+		Convey("Is ReasonSucceeded when an attempt succeeded", func() {
+			_ = retrier.Retry(5, func() error {
+				return nil
+			})
+			So(reasons, ShouldResemble, []Reason{ReasonSucceeded})
+		})
+
+		Convey("Is ReasonStopped when stop was called", func() {
+			_ = retrier.RetryWithStop(5, func(stop func()) error {
+				stop()
+				return nil
+			})
+			So(reasons, ShouldResemble, []Reason{ReasonStopped})
+		})
+
+		Convey("Is ReasonStopped when abort was called", func() {
+			_ = retrier.RetryWithAbort(5, func(abort func(err error)) error {
+				abort(expectedErr)
+				return nil
+			})
+			So(reasons, ShouldResemble, []Reason{ReasonStopped})
+		})
+
+		Convey("Is ReasonMaxAttempts when the maximum number of retries is reached, also on the *Error", func() {
+			err := retrier.Retry(2, func() error {
+				return expectedErr
+			})
+			So(reasons, ShouldResemble, []Reason{ReasonMaxAttempts})
+			var retryErr *Error
+			So(errors.As(err, &retryErr), ShouldBeTrue)
+			So(retryErr.Reason, ShouldEqual, ReasonMaxAttempts)
+		})
+
+		Convey("Is ReasonMaxAttempts when a RetryWithStop loop succeeds without stop being called", func() {
+			_ = retrier.RetryWithStop(2, func(stop func()) error {
+				return nil
+			})
+			So(reasons, ShouldResemble, []Reason{ReasonMaxAttempts})
+		})
+
+		Convey("Is ReasonContextDone when the context is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_ = retrier.RetryCtx(ctx, 5, func() error {
+				return nil
+			})
+			So(reasons, ShouldResemble, []Reason{ReasonContextDone})
+		})
+
+		Convey("Is ReasonPermanentError when a classifier aborts", func() {
+			retrier := NewConstantDelayRetrier(time.Second, append(opts, WithClassifier(ClassifierFunc(func(error) Action {
+				return ActionAbort
+			})))...)
+			_ = retrier.Retry(5, func() error {
+				return expectedErr
+			})
+			So(reasons, ShouldResemble, []Reason{ReasonPermanentError})
+		})
+
+		Convey("Is ReasonBudgetExhausted when the maximum elapsed time is reached", func() {
+			retrier := NewConstantDelayRetrier(time.Second, append(opts, WithMaxElapsedTime(1500*time.Millisecond))...)
+			err := retrier.Retry(5, func() error {
+				return expectedErr
+			})
+			So(reasons, ShouldResemble, []Reason{ReasonBudgetExhausted})
+			var retryErr *Error
+			So(errors.As(err, &retryErr), ShouldBeTrue)
+			So(retryErr.Reason, ShouldEqual, ReasonBudgetExhausted)
+		})
+
+		Convey("String() describes the reason", func() {
+			So(ReasonSucceeded.String(), ShouldEqual, "succeeded")
+			So(ReasonBudgetExhausted.String(), ShouldEqual, "budget exhausted")
+			So(Reason(0).String(), ShouldEqual, "unknown")
+		})
+	})
+}
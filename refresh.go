@@ -0,0 +1,34 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+)
+
+// WithCredentialRefresh makes the retrier call the given refresh function when an attempt fails with an error for
+// which isExpired returns true, like a 401 Unauthorized response, and then retry right away, without sleeping or
+// advancing the backoff. The retry doesn't count against the maximum number of retries, so it's made also if the last
+// attempt failed. Credentials are refreshed at max once per retry loop: if an attempt fails with an expired
+// credentials error again after refreshing, the failure is permanent and its error is returned.
+// If refreshing fails, the error of the refresh function is returned.
+func WithCredentialRefresh(isExpired func(err error) bool, refresh func(ctx context.Context) error) Option {
+	return func(cfg *config) {
+		cfg.isExpired = isExpired
+		cfg.refresh = refresh
+	}
+}
+
+// refreshCredentials refreshes the credentials if the given error says they expired. It returns whether they were
+// refreshed, or an error if the failure is permanent or refreshing failed.
+func (cfg *config) refreshCredentials(ctx context.Context, err error, refreshed bool) (bool, error) {
+	if cfg.isExpired == nil || !cfg.isExpired(err) {
+		return false, nil
+	}
+	if refreshed {
+		return false, err
+	}
+	if refreshErr := cfg.refresh(ctx); refreshErr != nil {
+		return false, fmt.Errorf("refresh credentials: %w", refreshErr)
+	}
+	return true, nil
+}
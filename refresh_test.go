@@ -0,0 +1,96 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithCredentialRefresh(t *testing.T) {
+	Convey("WithCredentialRefresh()", t, func() {
+		errUnauthorized := errors.New("401 unauthorized")
+		errOther := errors.New("other")
+		isExpired := func(err error) bool {
+			return errors.Is(err, errUnauthorized)
+		}
+		var numRefreshed, numCalled int
+		refresh := func(context.Context) error {
+			numRefreshed++
+			return nil
+		}
+		clock := &fakeClock{now: time.Now()}
+
+		Convey("Refreshes once and retries right away", func() {
+			retrier := NewBackOffRetrier(time.Second, 2, WithClock(clock), WithCredentialRefresh(isExpired, refresh))
+			errs := []error{errUnauthorized, errOther, nil}
+			err := retrier.Retry(5, func() error {
+				numCalled++
+				return errs[numCalled-1]
+			})
+			So(err, ShouldBeNil)
+			So(numCalled, ShouldEqual, 3)
+			So(numRefreshed, ShouldEqual, 1)
+			// No sleep after the refresh, and the backoff starts at the initial delay.
+			So(clock.sleeps, ShouldResemble, []time.Duration{time.Second})
+		})
+
+		Convey("Retries with the refreshed credentials also if the last attempt failed", func() {
+			retrier := NewNoDelayRetrier(WithCredentialRefresh(isExpired, refresh))
+			errs := []error{errOther, errUnauthorized, nil}
+			err := retrier.Retry(1, func() error {
+				numCalled++
+				return errs[numCalled-1]
+			})
+			So(err, ShouldBeNil)
+			So(numCalled, ShouldEqual, 3)
+			So(numRefreshed, ShouldEqual, 1)
+
+			numCalled = 0
+			err = NewNoDelayRetrier(WithCredentialRefresh(isExpired, refresh)).Retry(0, func() error {
+				numCalled++
+				return errUnauthorized
+			})
+			So(err, ShouldEqual, errUnauthorized)
+			So(numCalled, ShouldEqual, 2)
+		})
+
+		Convey("Repeated expired credentials errors are permanent", func() {
+			retrier := NewBackOffRetrier(time.Second, 2, WithClock(clock), WithCredentialRefresh(isExpired, refresh))
+			err := retrier.Retry(5, func() error {
+				numCalled++
+				return errUnauthorized
+			})
+			So(err, ShouldEqual, errUnauthorized)
+			So(numCalled, ShouldEqual, 2)
+			So(numRefreshed, ShouldEqual, 1)
+			So(clock.sleeps, ShouldBeEmpty)
+		})
+
+		Convey("If refreshing fails, returns its error", func() {
+			errRefresh := errors.New("refresh failed")
+			retrier := NewNoDelayRetrier(WithCredentialRefresh(isExpired, func(context.Context) error {
+				return errRefresh
+			}))
+			err := retrier.Retry(5, func() error {
+				numCalled++
+				return errUnauthorized
+			})
+			So(err, ShouldWrap, errRefresh)
+			So(numCalled, ShouldEqual, 1)
+		})
+
+		Convey("Other errors are retried as usual", func() {
+			retrier := NewNoDelayRetrier(WithCredentialRefresh(isExpired, refresh))
+			err := retrier.Retry(2, func() error {
+				numCalled++
+				return errOther
+			})
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(numCalled, ShouldEqual, 3)
+			So(numRefreshed, ShouldEqual, 0)
+		})
+	})
+}
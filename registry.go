@@ -0,0 +1,108 @@
+package retry
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+)
+
+// StatsReporter reports the cumulative statistics of a retrier. It is implemented by *BackOffRetrier,
+// *StrategyRetrier, *ConstantDelayRetrier and *AdaptiveBackOffRetrier.
+type StatsReporter interface {
+	Stats() RetrierStats
+}
+
+var (
+	_ StatsReporter = (*BackOffRetrier)(nil)
+	_ StatsReporter = (*StrategyRetrier)(nil)
+	_ StatsReporter = (*ConstantDelayRetrier)(nil)
+	_ StatsReporter = (*AdaptiveBackOffRetrier)(nil)
+)
+
+// NamedStats contains the statistics of all retriers registered under a name.
+type NamedStats struct {
+	// Name is the name the retriers were registered under.
+	Name string
+	// NumRetriers is the number of retriers registered under the name.
+	NumRetriers int
+	RetrierStats
+}
+
+// registryEntry is a retrier registered using Register.
+type registryEntry struct {
+	r StatsReporter
+}
+
+// registry holds the retriers registered using Register, by name.
+var registry = struct {
+	mu       sync.Mutex
+	retriers map[string][]*registryEntry
+}{retriers: make(map[string][]*registryEntry)}
+
+// Register registers the given retrier under the given name in the process-wide registry, so that its statistics
+// are reported by RegisteredStats. The statistics of all retriers registered under the same name are aggregated.
+// Call the returned function to unregister the retrier again.
+func Register(name string, r StatsReporter) (unregister func()) {
+	entry := &registryEntry{r: r}
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.retriers[name] = append(registry.retriers[name], entry)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			registry.mu.Lock()
+			defer registry.mu.Unlock()
+			registry.retriers[name] = slices.DeleteFunc(registry.retriers[name], func(e *registryEntry) bool {
+				return e == entry
+			})
+			if len(registry.retriers[name]) == 0 {
+				delete(registry.retriers, name)
+			}
+		})
+	}
+}
+
+// RegisteredStats returns a snapshot of the aggregated statistics of the retriers registered using Register, for
+// e.g. health endpoints. The operations retrying the most come first: they are sorted by the number of retry loops that
+// are currently retrying, then by the number of retries, then by name.
+func RegisteredStats() []NamedStats {
+	registry.mu.Lock()
+	all := make([]NamedStats, 0, len(registry.retriers))
+	retriers := make([][]StatsReporter, 0, len(registry.retriers))
+	for name, entries := range registry.retriers {
+		all = append(all, NamedStats{Name: name, NumRetriers: len(entries)})
+		reporters := make([]StatsReporter, len(entries))
+		for i, entry := range entries {
+			reporters[i] = entry.r
+		}
+		retriers = append(retriers, reporters)
+	}
+	registry.mu.Unlock()
+
+	for i, reporters := range retriers {
+		for _, r := range reporters {
+			stats := r.Stats()
+			all[i].Attempts += stats.Attempts
+			all[i].Retries += stats.Retries
+			all[i].Retrying += stats.Retrying
+			all[i].Successes += stats.Successes
+			all[i].Failures += stats.Failures
+			all[i].TotalSlept += stats.TotalSlept
+			for class, n := range stats.ErrorClasses {
+				if all[i].ErrorClasses == nil {
+					all[i].ErrorClasses = make(map[string]int)
+				}
+				all[i].ErrorClasses[class] += n
+			}
+		}
+	}
+	slices.SortFunc(all, func(a, b NamedStats) int {
+		return cmp.Or(
+			cmp.Compare(b.Retrying, a.Retrying),
+			cmp.Compare(b.Retries, a.Retries),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+	return all
+}
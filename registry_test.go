@@ -0,0 +1,64 @@
+package retry
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fixedStats is a StatsReporter that reports fixed statistics.
+type fixedStats RetrierStats
+
+func (s fixedStats) Stats() RetrierStats {
+	return RetrierStats(s)
+}
+
+func TestRegister(t *testing.T) {
+	Convey("Register()", t, func() {
+		Convey("Reports the aggregated statistics of all retriers registered under a name", func() {
+			a, b := NewNoDelayRetrier(), NewNoDelayRetrier()
+			defer Register("op", a)()
+			defer Register("op", b)()
+			_ = a.Retry(1, func() error { return errors.New("foo") })
+			_ = b.Retry(1, func() error { return nil })
+
+			So(RegisteredStats(), ShouldResemble, []NamedStats{{
+				Name:         "op",
+				NumRetriers:  2,
+				RetrierStats: RetrierStats{Attempts: 3, Retries: 1, Successes: 1, Failures: 1, TotalSlept: a.Stats().TotalSlept},
+			}})
+		})
+
+		Convey("Aggregates the error classes", func() {
+			defer Register("op", fixedStats{ErrorClasses: map[string]int{"timeout": 1, "auth": 2}})()
+			defer Register("op", fixedStats{ErrorClasses: map[string]int{"timeout": 3}})()
+			So(RegisteredStats()[0].ErrorClasses, ShouldResemble, map[string]int{"timeout": 4, "auth": 2})
+		})
+
+		Convey("Sorts the operations retrying the most first", func() {
+			defer Register("b", fixedStats{Retries: 5})()
+			defer Register("a", fixedStats{Retries: 5})()
+			defer Register("c", fixedStats{Retries: 10})()
+			defer Register("d", fixedStats{Retrying: 1, Retries: 1})()
+
+			var names []string
+			for _, stats := range RegisteredStats() {
+				names = append(names, stats.Name)
+			}
+			So(names, ShouldResemble, []string{"d", "c", "a", "b"})
+		})
+
+		Convey("The returned function unregisters the retrier", func() {
+			unregisterA := Register("op", fixedStats{Attempts: 1})
+			unregisterB := Register("op", fixedStats{Attempts: 2, TotalSlept: time.Second})
+			unregisterA()
+			unregisterA()
+			So(RegisteredStats(), ShouldResemble, []NamedStats{{Name: "op", NumRetriers: 1, RetrierStats: RetrierStats{Attempts: 2, TotalSlept: time.Second}}})
+
+			unregisterB()
+			So(RegisteredStats(), ShouldBeEmpty)
+		})
+	})
+}
@@ -5,82 +5,95 @@ import (
 	"time"
 )
 
+// Forever can be passed as the number of times to retry to retry forever, until the callback succeeds or the context
+// is done. Any negative number has the same effect.
+const Forever = -1
+
 // Retry retries the given callback at max the given number of times.
 // It stops as soon as a `nil` error is returned.
+// If the maximum number of retries is reached, an *Error wrapping the last error is returned.
 func Retry(numTimes int, cb func() error) error {
-	var err error
-	for i := 0; i <= numTimes; i++ {
-		err = cb()
-		if err == nil {
-			break
-		}
-	}
-	return err
+	return RetryCtx(context.Background(), numTimes, cb)
 }
 
 // RetryCtx retries the given callback at max the given number of times.
 // It stops as soon as a `nil` error is returned.
+// If the maximum number of retries is reached, an *Error wrapping the last error is returned.
 func RetryCtx(ctx context.Context, numTimes int, cb func() error) error {
-	return Retry(numTimes, func() error {
-		if ctx.Err() != nil {
-			return ctx.Err()
-		}
+	return retryLoop(ctx, &config{}, numTimes, false, NoBackoff, func(func()) error {
 		return cb()
 	})
 }
 
+// RetryForever retries the given callback until a `nil` error is returned or the given context is done.
+func RetryForever(ctx context.Context, cb func() error) error {
+	return RetryCtx(ctx, Forever, cb)
+}
+
 // RetryWithDelay retries the given callback at max the given number of times.
 // It stops as soon as a `nil` error is returned.
 // It sleeps for the given delay if an error happens.
+// If the maximum number of retries is reached, an *Error wrapping the last error is returned.
 func RetryWithDelay(numTimes int, delay time.Duration, cb func() error) error {
-	var err error
-	for i := 0; i <= numTimes; i++ {
-		err = cb()
-		if err == nil {
-			break
-		}
-		time.Sleep(delay)
-	}
-	return err
+	return RetryWithDelayCtx(context.Background(), numTimes, delay, cb)
 }
 
 // RetryWithDelayCtx retries the given callback at max the given number of times.
 // It stops as soon as a `nil` error is returned.
 // It sleeps for the given delay if an error happens.
+// If the maximum number of retries is reached, an *Error wrapping the last error is returned.
 func RetryWithDelayCtx(ctx context.Context, numTimes int, delay time.Duration, cb func() error) error {
-	return RetryWithDelay(numTimes, delay, func() error {
-		if ctx.Err() != nil {
-			return ctx.Err()
-		}
+	return retryLoop(ctx, &config{sleepAfterLastAttempt: true}, numTimes, false, ConstantBackoff(delay), func(func()) error {
 		return cb()
 	})
 }
 
 // RetryWithStop retries the given callback at max the given number of times.
 // It stops only when `stop` is called.
+// If the maximum number of retries is reached and the last attempt returned an error, an *Error wrapping the last
+// error is returned.
 func RetryWithStop(numTimes int, cb func(stop func()) error) error {
-	var err error
-	var cancelled bool
-	stop := func() {
-		cancelled = true
-	}
-	for i := 0; i <= numTimes; i++ {
-		if cancelled {
-			break
-		}
-		err = cb(stop)
-	}
-	return err
+	return RetryWithStopCtx(context.Background(), numTimes, cb)
 }
 
 // RetryWithStopCtx retries the given callback at max the given number of times.
 // It stops only when `stop` is called.
+// If the maximum number of retries is reached and the last attempt returned an error, an *Error wrapping the last
+// error is returned.
 func RetryWithStopCtx(ctx context.Context, numTimes int, cb func(stop func()) error) error {
-	return RetryWithStop(numTimes, func(stop func()) error {
-		if ctx.Err() != nil {
+	return retryLoop(ctx, &config{}, numTimes, true, NoBackoff, cb)
+}
+
+// RetryWithAbort retries the given callback at max the given number of times.
+// It stops as soon as a `nil` error is returned, or when `abort` is called, in which case the error passed to `abort`
+// is returned, regardless of what the callback returns.
+// If the maximum number of retries is reached, an *Error wrapping the last error is returned.
+func RetryWithAbort(numTimes int, cb func(abort func(err error)) error) error {
+	return RetryWithAbortCtx(context.Background(), numTimes, cb)
+}
+
+// RetryWithAbortCtx retries the given callback at max the given number of times.
+// It stops as soon as a `nil` error is returned, or when `abort` is called, in which case the error passed to `abort`
+// is returned, regardless of what the callback returns.
+// If the maximum number of retries is reached, an *Error wrapping the last error is returned.
+func RetryWithAbortCtx(ctx context.Context, numTimes int, cb func(abort func(err error)) error) error {
+	return retryLoop(ctx, &config{}, numTimes, false, NoBackoff, withAbort(cb))
+}
+
+// withAbort turns a callback that receives `abort` into one that receives `stop`. Calling `abort` stops the loop and
+// makes the attempt return the given error.
+func withAbort(cb func(abort func(err error)) error) func(stop func()) error {
+	return func(stop func()) error {
+		var aborted bool
+		var abortErr error
+		err := cb(func(err error) {
+			aborted = true
+			abortErr = err
+		})
+		if aborted {
 			stop()
-			return ctx.Err()
+			return abortErr
 		}
-		return cb(stop)
-	})
+		return err
+	}
 }
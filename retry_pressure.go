@@ -0,0 +1,125 @@
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+// numPressureBuckets is the number of buckets the window of a retry pressure gauge is divided into.
+const numPressureBuckets = 10
+
+// RetryPressure measures the retry pressure of operations over a sliding window: the ratio of attempts that were
+// retries to all attempts. A rising retry pressure indicates that a downstream is in distress before hard failures
+// appear, which makes it a useful input for autoscaling and alerting.
+//
+// Attempts are grouped by the operation name set using WithName. It is safe for concurrent use, and is meant to be
+// shared by all retriers of an application.
+type RetryPressure struct {
+	bucketDur time.Duration
+	epoch     time.Time // The creation time, from which bucket indexes are measured using the monotonic clock.
+
+	mu         sync.Mutex
+	operations map[string]*[numPressureBuckets]pressureBucket
+}
+
+type pressureBucket struct {
+	index    int64 // The number of bucket durations between the epoch and the start of the bucket.
+	attempts int
+	retries  int
+}
+
+// RetryPressureStats contains the retry pressure statistics of an operation.
+type RetryPressureStats struct {
+	// Attempts is the number of attempts in the current window, including retries.
+	Attempts int
+	// Retries is the number of attempts in the current window that were retries.
+	Retries int
+	// Pressure is the ratio of retries to attempts in the current window.
+	Pressure float64
+}
+
+// NewRetryPressure returns a new retry pressure gauge over the given window.
+func NewRetryPressure(window time.Duration) *RetryPressure {
+	bucketDur := window / numPressureBuckets
+	if bucketDur <= 0 {
+		bucketDur = 1
+	}
+	return &RetryPressure{
+		bucketDur:  bucketDur,
+		epoch:      time.Now(),
+		operations: make(map[string]*[numPressureBuckets]pressureBucket),
+	}
+}
+
+// Record records an attempt of the given operation, and whether it was a retry.
+func (p *RetryPressure) Record(operation string, retry bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	buckets, ok := p.operations[operation]
+	if !ok {
+		buckets = new([numPressureBuckets]pressureBucket)
+		p.operations[operation] = buckets
+	}
+	index := p.index()
+	bucket := &buckets[index%numPressureBuckets]
+	if bucket.index != index {
+		*bucket = pressureBucket{index: index}
+	}
+	bucket.attempts++
+	if retry {
+		bucket.retries++
+	}
+}
+
+// Stats returns the current statistics of the given operation.
+func (p *RetryPressure) Stats(operation string) RetryPressureStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stats(p.operations[operation])
+}
+
+// AllStats returns the current statistics of all operations, by operation name.
+func (p *RetryPressure) AllStats() map[string]RetryPressureStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make(map[string]RetryPressureStats, len(p.operations))
+	for operation, buckets := range p.operations {
+		stats[operation] = p.stats(buckets)
+	}
+	return stats
+}
+
+// stats returns the statistics of the given buckets, which may be nil. p.mu must be held.
+func (p *RetryPressure) stats(buckets *[numPressureBuckets]pressureBucket) RetryPressureStats {
+	var stats RetryPressureStats
+	if buckets == nil {
+		return stats
+	}
+	first := p.index() - numPressureBuckets + 1
+	for _, bucket := range buckets {
+		if bucket.index >= first {
+			stats.Attempts += bucket.attempts
+			stats.Retries += bucket.retries
+		}
+	}
+	if stats.Attempts > 0 {
+		stats.Pressure = float64(stats.Retries) / float64(stats.Attempts)
+	}
+	return stats
+}
+
+// index returns the index of the current bucket. It is measured using the monotonic clock, so that wall clock jumps
+// don't corrupt the window.
+func (p *RetryPressure) index() int64 {
+	return int64(time.Since(p.epoch) / p.bucketDur)
+}
+
+// WithRetryPressure makes the retrier record every attempt in the given retry pressure gauge, under the operation name
+// set using WithName.
+func WithRetryPressure(pressure *RetryPressure) Option {
+	return func(cfg *config) {
+		cfg.pressure = pressure
+	}
+}
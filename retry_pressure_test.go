@@ -0,0 +1,54 @@
+package retry
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_RetryPressure(t *testing.T) {
+	Convey("*RetryPressure", t, func() {
+		pressure := NewRetryPressure(time.Minute)
+
+		Convey("Measures the ratio of retries to attempts per operation", func() {
+			pressure.Record("foo", false)
+			pressure.Record("foo", true)
+			pressure.Record("foo", true)
+			pressure.Record("foo", false)
+			pressure.Record("bar", false)
+
+			So(pressure.Stats("foo"), ShouldResemble, RetryPressureStats{Attempts: 4, Retries: 2, Pressure: 0.5})
+			So(pressure.AllStats(), ShouldResemble, map[string]RetryPressureStats{
+				"foo": {Attempts: 4, Retries: 2, Pressure: 0.5},
+				"bar": {Attempts: 1, Retries: 0, Pressure: 0},
+			})
+		})
+
+		Convey("Unknown operations have no pressure", func() {
+			So(pressure.Stats("foo"), ShouldResemble, RetryPressureStats{})
+		})
+
+		Convey("Attempts outside of the window are forgotten", func() {
+			pressure := NewRetryPressure(20 * time.Millisecond)
+			pressure.Record("foo", true)
+			time.Sleep(30 * time.Millisecond)
+			So(pressure.Stats("foo").Attempts, ShouldEqual, 0)
+		})
+
+		Convey("WithRetryPressure() records the attempts of a retrier under its operation name", func() {
+			retrier := NewNoDelayRetrier(WithName("charge-card"), WithRetryPressure(pressure))
+			var numCalled int
+			err := retrier.Retry(3, func() error {
+				numCalled++
+				if numCalled < 3 {
+					return errors.New("foo")
+				}
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(pressure.Stats("charge-card"), ShouldResemble, RetryPressureStats{Attempts: 3, Retries: 2, Pressure: 2.0 / 3})
+		})
+	})
+}
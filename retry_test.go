@@ -41,7 +41,8 @@ func TestRetry(t *testing.T) {
 				return expectedErr
 			})
 			So(err, ShouldNotBeNil)
-			So(err, ShouldEqual, expectedErr)
+			So(err, ShouldWrap, expectedErr)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
 			So(numCalled, ShouldEqual, 2)
 		})
 	})
@@ -79,7 +80,8 @@ func TestRetryCtx(t *testing.T) {
 				return expectedErr
 			})
 			So(err, ShouldNotBeNil)
-			So(err, ShouldEqual, expectedErr)
+			So(err, ShouldWrap, expectedErr)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
 			So(numCalled, ShouldEqual, 2)
 		})
 
@@ -95,6 +97,33 @@ func TestRetryCtx(t *testing.T) {
 			So(err, ShouldEqual, context.Canceled)
 			So(numCalled, ShouldEqual, 0)
 		})
+
+		Convey("If the context is canceled with a cause, returns the cause", func() {
+			cause := errors.New("shutting down")
+
+			Convey("Before an attempt", func() {
+				ctx, cancel := context.WithCancelCause(context.Background())
+				cancel(cause)
+				err := RetryCtx(ctx, 10, func() error {
+					numCalled++
+					return nil
+				})
+				So(err, ShouldEqual, cause)
+				So(numCalled, ShouldEqual, 0)
+			})
+
+			Convey("While sleeping", func() {
+				ctx, cancel := context.WithCancelCause(context.Background())
+				err := NewConstantDelayRetrier(time.Hour).RetryCtx(ctx, 10, func() error {
+					go func() {
+						time.Sleep(time.Millisecond)
+						cancel(cause)
+					}()
+					return errors.New("foo")
+				})
+				So(err, ShouldEqual, cause)
+			})
+		})
 	})
 }
 
@@ -136,7 +165,8 @@ func TestRetryWithDelay(t *testing.T) {
 				return expectedErr
 			})
 			So(err, ShouldNotBeNil)
-			So(err, ShouldEqual, expectedErr)
+			So(err, ShouldWrap, expectedErr)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
 			So(numCalled, ShouldEqual, 2)
 			So(time.Since(startTime), ShouldBeGreaterThan, 2*delay)
 			So(time.Since(startTime), ShouldBeLessThan, 3*delay)
@@ -182,7 +212,8 @@ func TestRetryWithDelayCtx(t *testing.T) {
 				return expectedErr
 			})
 			So(err, ShouldNotBeNil)
-			So(err, ShouldEqual, expectedErr)
+			So(err, ShouldWrap, expectedErr)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
 			So(numCalled, ShouldEqual, 2)
 			So(time.Since(startTime), ShouldBeGreaterThan, 2*delay)
 			So(time.Since(startTime), ShouldBeLessThan, 3*delay)
@@ -223,7 +254,8 @@ func TestRetryWithStop(t *testing.T) {
 				return expectedErr
 			})
 			So(err, ShouldNotBeNil)
-			So(err, ShouldEqual, expectedErr)
+			So(err, ShouldWrap, expectedErr)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
 			So(numCalled, ShouldEqual, 4)
 		})
 
@@ -263,7 +295,8 @@ func TestRetryWithStop(t *testing.T) {
 				return expectedErr
 			})
 			So(err, ShouldNotBeNil)
-			So(err, ShouldEqual, expectedErr)
+			So(err, ShouldWrap, expectedErr)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
 			So(numCalled, ShouldEqual, 2)
 		})
 	})
@@ -289,7 +322,8 @@ func TestRetryWithStopCtx(t *testing.T) {
 				return expectedErr
 			})
 			So(err, ShouldNotBeNil)
-			So(err, ShouldEqual, expectedErr)
+			So(err, ShouldWrap, expectedErr)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
 			So(numCalled, ShouldEqual, 4)
 		})
 
@@ -329,7 +363,8 @@ func TestRetryWithStopCtx(t *testing.T) {
 				return expectedErr
 			})
 			So(err, ShouldNotBeNil)
-			So(err, ShouldEqual, expectedErr)
+			So(err, ShouldWrap, expectedErr)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
 			So(numCalled, ShouldEqual, 2)
 		})
 
@@ -347,3 +382,125 @@ func TestRetryWithStopCtx(t *testing.T) {
 		})
 	})
 }
+
+func TestRetryWithAbort(t *testing.T) {
+	Convey("RetryWithAbort()", t, func() {
+		var numCalled int
+
+		Convey("Retries errors until nil is returned", func() {
+			err := RetryWithAbort(10, func(abort func(err error)) error {
+				numCalled++
+				if numCalled == 2 {
+					return nil
+				}
+				return errors.New("foo")
+			})
+			So(err, ShouldBeNil)
+			So(numCalled, ShouldEqual, 2)
+		})
+
+		Convey("If abort is called, stops right away and returns the given error", func() {
+			expectedErr := errors.New("foo")
+			err := RetryWithAbort(10, func(abort func(err error)) error {
+				numCalled++
+				abort(expectedErr)
+				return errors.New("bar")
+			})
+			So(err, ShouldEqual, expectedErr)
+			So(numCalled, ShouldEqual, 1)
+		})
+
+		Convey("If abort is called with nil, stops right away and returns nil", func() {
+			err := RetryWithAbort(10, func(abort func(err error)) error {
+				numCalled++
+				abort(nil)
+				return errors.New("bar")
+			})
+			So(err, ShouldBeNil)
+			So(numCalled, ShouldEqual, 1)
+		})
+
+		Convey("If the maximum number of tries is reached, returns err", func() {
+			expectedErr := errors.New("foo")
+			err := RetryWithAbort(1, func(abort func(err error)) error {
+				numCalled++
+				return expectedErr
+			})
+			So(err, ShouldWrap, expectedErr)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(numCalled, ShouldEqual, 2)
+		})
+	})
+}
+
+func TestRetryWithAbortCtx(t *testing.T) {
+	Convey("RetryWithAbortCtx()", t, func() {
+		Convey("If the context has an error, returns it without calling the callback", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			var numCalled int
+			err := RetryWithAbortCtx(ctx, 10, func(abort func(err error)) error {
+				numCalled++
+				return nil
+			})
+			So(err, ShouldEqual, context.Canceled)
+			So(numCalled, ShouldEqual, 0)
+		})
+	})
+}
+
+func TestRetryForever(t *testing.T) {
+	Convey("RetryForever()", t, func() {
+		var numCalled int
+
+		Convey("Retries until nil is returned", func() {
+			err := RetryForever(context.Background(), func() error {
+				numCalled++
+				if numCalled == 1000 {
+					return nil
+				}
+				return errors.New("foo")
+			})
+			So(err, ShouldBeNil)
+			So(numCalled, ShouldEqual, 1000)
+		})
+
+		Convey("Retries until the context is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			err := RetryForever(ctx, func() error {
+				numCalled++
+				if numCalled == 1000 {
+					cancel()
+				}
+				return errors.New("foo")
+			})
+			So(err, ShouldEqual, context.Canceled)
+			So(numCalled, ShouldEqual, 1000)
+		})
+
+		Convey("A negative number of times also retries forever", func() {
+			err := RetryWithStop(-5, func(stop func()) error {
+				numCalled++
+				if numCalled == 1000 {
+					stop()
+				}
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(numCalled, ShouldEqual, 1000)
+		})
+
+		Convey("Only keeps the most recent errors", func() {
+			err := NewBackOffRetrier(0, 1, WithCostBudget(1000, func(int, error) float64 {
+				return 1
+			})).RetryForever(context.Background(), func() error {
+				numCalled++
+				return errors.New("foo")
+			})
+			var retryErr *Error
+			So(errors.As(err, &retryErr), ShouldBeTrue)
+			So(retryErr.NumAttempts, ShouldEqual, 1000)
+			So(retryErr.Errors, ShouldHaveLength, maxForeverErrors)
+		})
+	})
+}
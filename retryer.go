@@ -0,0 +1,29 @@
+package retry
+
+import (
+	"context"
+)
+
+// Retryer retries callbacks. Accept a Retryer instead of a concrete retrier to let callers decide how to retry, and
+// to be able to substitute a fake in tests.
+type Retryer interface {
+	// Retry retries the given callback at max the given number of times.
+	// It stops as soon as a `nil` error is returned.
+	Retry(numTimes int, cb func() error) error
+	// RetryCtx retries the given callback at max the given number of times.
+	// It stops as soon as a `nil` error is returned.
+	RetryCtx(ctx context.Context, numTimes int, cb func() error) error
+	// RetryWithStop retries the given callback at max the given number of times.
+	// It stops only when `stop` is called.
+	RetryWithStop(numTimes int, cb func(stop func()) error) error
+	// RetryWithStopCtx retries the given callback at max the given number of times.
+	// It stops only when `stop` is called.
+	RetryWithStopCtx(ctx context.Context, numTimes int, cb func(stop func()) error) error
+}
+
+var (
+	_ Retryer = (*BackOffRetrier)(nil)
+	_ Retryer = (*StrategyRetrier)(nil)
+	_ Retryer = (*ConstantDelayRetrier)(nil)
+	_ Retryer = (*AdaptiveBackOffRetrier)(nil)
+)
@@ -0,0 +1,142 @@
+// Package retryexec runs commands, retrying them based on their exit codes and output.
+package retryexec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// Retrier retries a callback until stop is called. It is implemented by the retriers of the retry package, like
+// *retry.BackOffRetrier.
+type Retrier interface {
+	RetryWithStopCtx(ctx context.Context, numTimes int, cb func(stop func()) error) error
+}
+
+// Result is the result of the last attempt to run a command.
+type Result struct {
+	// Stdout contains the standard output of the last attempt.
+	Stdout []byte
+	// Stderr contains the standard error of the last attempt.
+	Stderr []byte
+	// ExitCode is the exit code of the last attempt, or -1 if the command did not exit normally (e.g. because it
+	// could not be started or was killed).
+	ExitCode int
+	// Attempts is the number of attempts that were made.
+	Attempts int
+}
+
+// Option configures how a command is run.
+type Option func(*config)
+
+type config struct {
+	exitCodes      []int
+	stderrPatterns []*regexp.Regexp
+	attemptTimeout time.Duration
+	configure      func(cmd *exec.Cmd)
+}
+
+// RetryOnExitCodes makes only failures with one of the given exit codes retried, instead of all failures.
+func RetryOnExitCodes(codes ...int) Option {
+	return func(cfg *config) {
+		cfg.exitCodes = append(cfg.exitCodes, codes...)
+	}
+}
+
+// RetryOnStderr makes only failures with a standard error matching one of the given patterns retried, instead of all
+// failures. If combined with RetryOnExitCodes, failures matching either are retried.
+func RetryOnStderr(patterns ...*regexp.Regexp) Option {
+	return func(cfg *config) {
+		cfg.stderrPatterns = append(cfg.stderrPatterns, patterns...)
+	}
+}
+
+// WithAttemptTimeout kills an attempt if it takes longer than the given timeout. Attempts that time out are always
+// retried.
+func WithAttemptTimeout(timeout time.Duration) Option {
+	return func(cfg *config) {
+		cfg.attemptTimeout = timeout
+	}
+}
+
+// WithCmd calls the given function with the command of every attempt before it is started, to set e.g. its
+// directory, environment or standard input. Standard output and standard error must not be set; they are captured.
+func WithCmd(configure func(cmd *exec.Cmd)) Option {
+	return func(cfg *config) {
+		cfg.configure = configure
+	}
+}
+
+// Run runs the command with the given name and arguments, retrying it at max the given number of times using the
+// given retrier. By default, all failures are retried, except for commands that cannot be started.
+// It returns the result of the last attempt, even if the command failed.
+func Run(ctx context.Context, retrier Retrier, numTimes int, name string, args []string, opts ...Option) (*Result, error) {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	res := &Result{}
+	err := retrier.RetryWithStopCtx(ctx, numTimes, func(stop func()) error {
+		res.Attempts++
+		timedOut, err := cfg.runAttempt(ctx, res, name, args)
+		if err == nil || !(timedOut || cfg.retryable(res)) {
+			stop()
+		}
+		return err
+	})
+	return res, err
+}
+
+// runAttempt runs the command once, storing its output and exit code in res. It returns whether the attempt timed out
+// and the error of the command.
+func (cfg *config) runAttempt(ctx context.Context, res *Result, name string, args []string) (bool, error) {
+	attemptCtx := ctx
+	if cfg.attemptTimeout > 0 {
+		var cancel context.CancelFunc
+		attemptCtx, cancel = context.WithTimeout(ctx, cfg.attemptTimeout)
+		defer cancel()
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(attemptCtx, name, args...)
+	if cfg.configure != nil {
+		cfg.configure(cmd)
+	}
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	res.Stdout = stdout.Bytes()
+	res.Stderr = stderr.Bytes()
+	res.ExitCode = -1
+	if cmd.ProcessState != nil {
+		res.ExitCode = cmd.ProcessState.ExitCode()
+	}
+	timedOut := err != nil && ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded)
+	return timedOut, err
+}
+
+// retryable reports whether the failed attempt with the given result should be retried.
+func (cfg *config) retryable(res *Result) bool {
+	if res.ExitCode == -1 {
+		// The command could not be started, or was killed.
+		return false
+	}
+	if len(cfg.exitCodes) == 0 && len(cfg.stderrPatterns) == 0 {
+		return true
+	}
+	if slices.Contains(cfg.exitCodes, res.ExitCode) {
+		return true
+	}
+	for _, pattern := range cfg.stderrPatterns {
+		if pattern.Match(res.Stderr) {
+			return true
+		}
+	}
+	return false
+}
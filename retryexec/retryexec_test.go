@@ -0,0 +1,99 @@
+package retryexec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/minitauros/go-retry"
+)
+
+// flakyScript returns the arguments for a shell script that fails with the given exit code and standard error until
+// it has been run the given number of times, using a counter file in the given directory.
+func flakyScript(dir string, succeedOnAttempt, exitCode int, stderr string) []string {
+	counter := filepath.Join(dir, "counter")
+	script := fmt.Sprintf(
+		`n=$(cat %[1]s 2>/dev/null || echo 0); n=$((n+1)); echo $n > %[1]s; echo "attempt $n"; `+
+			`if [ $n -lt %[2]d ]; then echo "%[4]s" >&2; exit %[3]d; fi`,
+		counter, succeedOnAttempt, exitCode, stderr,
+	)
+	return []string{"-c", script}
+}
+
+func TestRun(t *testing.T) {
+	Convey("Run()", t, func() {
+		retrier := retry.NewBackOffRetrier(0, 1)
+		dir := t.TempDir()
+		ctx := context.Background()
+
+		Convey("Retries failures until the command succeeds", func() {
+			res, err := Run(ctx, retrier, 5, "sh", flakyScript(dir, 3, 1, "oops"))
+			So(err, ShouldBeNil)
+			So(res.Attempts, ShouldEqual, 3)
+			So(res.ExitCode, ShouldEqual, 0)
+			So(string(res.Stdout), ShouldEqual, "attempt 3\n")
+			So(string(res.Stderr), ShouldEqual, "")
+		})
+
+		Convey("If the maximum number of tries is reached, returns the result of the last attempt", func() {
+			res, err := Run(ctx, retrier, 1, "sh", flakyScript(dir, 10, 2, "oops"))
+			So(err, ShouldWrap, retry.ErrMaxRetriesExceeded)
+			var exitErr *exec.ExitError
+			So(errors.As(err, &exitErr), ShouldBeTrue)
+			So(res.Attempts, ShouldEqual, 2)
+			So(res.ExitCode, ShouldEqual, 2)
+			So(string(res.Stderr), ShouldEqual, "oops\n")
+		})
+
+		Convey("Only retries the given exit codes", func() {
+			res, err := Run(ctx, retrier, 5, "sh", flakyScript(dir, 3, 75, "oops"), RetryOnExitCodes(75))
+			So(err, ShouldBeNil)
+			So(res.Attempts, ShouldEqual, 3)
+
+			res, err = Run(ctx, retrier, 5, "sh", flakyScript(t.TempDir(), 3, 1, "oops"), RetryOnExitCodes(75))
+			So(err, ShouldNotBeNil)
+			So(res.Attempts, ShouldEqual, 1)
+		})
+
+		Convey("Only retries failures with standard error matching the given patterns", func() {
+			pattern := regexp.MustCompile(`connection refused`)
+			res, err := Run(ctx, retrier, 5, "sh", flakyScript(dir, 3, 1, "dial: connection refused"), RetryOnStderr(pattern))
+			So(err, ShouldBeNil)
+			So(res.Attempts, ShouldEqual, 3)
+
+			res, err = Run(ctx, retrier, 5, "sh", flakyScript(t.TempDir(), 3, 1, "permission denied"), RetryOnStderr(pattern))
+			So(err, ShouldNotBeNil)
+			So(res.Attempts, ShouldEqual, 1)
+		})
+
+		Convey("Kills and retries attempts that time out", func() {
+			startTime := time.Now()
+			res, err := Run(ctx, retrier, 1, "sleep", []string{"10"}, WithAttemptTimeout(20*time.Millisecond))
+			So(err, ShouldNotBeNil)
+			So(res.Attempts, ShouldEqual, 2)
+			So(res.ExitCode, ShouldEqual, -1)
+			So(time.Since(startTime), ShouldBeLessThan, 5*time.Second)
+		})
+
+		Convey("Does not retry commands that cannot be started", func() {
+			res, err := Run(ctx, retrier, 5, "this-command-does-not-exist", nil)
+			So(err, ShouldWrap, exec.ErrNotFound)
+			So(res.Attempts, ShouldEqual, 1)
+		})
+
+		Convey("Configures every command using WithCmd()", func() {
+			res, err := Run(ctx, retrier, 0, "pwd", nil, WithCmd(func(cmd *exec.Cmd) {
+				cmd.Dir = dir
+			}))
+			So(err, ShouldBeNil)
+			So(string(res.Stdout), ShouldContainSubstring, filepath.Base(dir))
+		})
+	})
+}
@@ -0,0 +1,132 @@
+// Package retrygroup runs tasks in an errgroup.Group, retrying every task according to a retry policy of its own.
+package retrygroup
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/minitauros/go-retry"
+	"golang.org/x/sync/errgroup"
+)
+
+// Group is a collection of goroutines working on tasks of a common job, like an errgroup.Group, where every task is
+// retried according to a retry policy of its own. The first task that fails permanently, i.e. whose retries are
+// exhausted or were aborted, cancels the context of the group, which cancels the retries of the remaining tasks.
+type Group struct {
+	group *errgroup.Group
+	ctx   context.Context
+
+	mu    sync.Mutex
+	tasks []TaskResult
+	first int // The index of the task that failed first, or -1 if none did.
+}
+
+// TaskResult describes the outcome of a task.
+type TaskResult struct {
+	// Name is the name of the task.
+	Name string
+	// NumAttempts is the number of attempts made for the task.
+	NumAttempts int
+	// Err is the error returned by the retry policy of the task, or nil if it succeeded. It is context.Canceled for
+	// tasks whose retries were cancelled because another task failed.
+	Err error
+}
+
+// WithContext returns a new group and a derived context, which is cancelled the first time a task fails permanently
+// or Wait returns, whichever occurs first.
+func WithContext(ctx context.Context) (*Group, context.Context) {
+	group, ctx := errgroup.WithContext(ctx)
+	return &Group{group: group, ctx: ctx, first: -1}, ctx
+}
+
+// SetLimit limits the number of tasks running at once to the given number. A negative number means no limit. See
+// errgroup.Group.SetLimit.
+func (g *Group) SetLimit(n int) {
+	g.group.SetLimit(n)
+}
+
+// Go runs the given task in a new goroutine, retrying it according to the given policy, which can carry a classifier
+// of its own using retry.WithClassifier. Every attempt receives the context of the group, carrying the attempt as
+// can be retrieved using retry.AttemptFromContext. The name identifies the task in the error returned by Wait.
+func (g *Group) Go(name string, policy retry.Policy, task func(ctx context.Context) error) {
+	g.mu.Lock()
+	index := len(g.tasks)
+	g.tasks = append(g.tasks, TaskResult{Name: name})
+	g.mu.Unlock()
+
+	g.group.Go(func() error {
+		var numAttempts int
+		err := policy.Do(g.ctx, func(ctx context.Context) error {
+			numAttempts++
+			return task(ctx)
+		})
+
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		if g.first >= 0 && err != nil && err == context.Cause(g.ctx) {
+			// The cause of the context is the error of the task that failed first.
+			err = context.Canceled
+		}
+		g.tasks[index].NumAttempts = numAttempts
+		g.tasks[index].Err = err
+		if err != nil && g.first < 0 {
+			g.first = index
+		}
+		return err
+	})
+}
+
+// Wait blocks until all tasks are done. It returns nil if all succeeded, and an *Error describing the outcome of every
+// task otherwise.
+func (g *Group) Wait() error {
+	if g.group.Wait() == nil {
+		return nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return &Error{Tasks: append([]TaskResult(nil), g.tasks...), first: g.first}
+}
+
+// Error is the error returned by Group.Wait when a task failed. It preserves the outcome of every task.
+//
+// It unwraps to the errors of all tasks that failed, starting with the task that failed first, so errors.Is and
+// errors.As match any of them, like the *retry.Error carrying the attempts of a task.
+type Error struct {
+	// Tasks contains the outcome of every task, in the order they were started in.
+	Tasks []TaskResult
+
+	first int
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	first := e.Tasks[e.first]
+	msg := fmt.Sprintf("task %q failed after %d attempts: %s", first.Name, first.NumAttempts, first.Err)
+	if numFailed := len(e.Failed()); numFailed > 1 {
+		msg += fmt.Sprintf(" (and %d more tasks failed)", numFailed-1)
+	}
+	return msg
+}
+
+// Unwrap returns the errors of all tasks that failed, starting with the task that failed first.
+func (e *Error) Unwrap() []error {
+	errs := []error{e.Tasks[e.first].Err}
+	for i, task := range e.Tasks {
+		if task.Err != nil && i != e.first {
+			errs = append(errs, task.Err)
+		}
+	}
+	return errs
+}
+
+// Failed returns the outcome of the tasks that failed, in the order they were started in.
+func (e *Error) Failed() []TaskResult {
+	var failed []TaskResult
+	for _, task := range e.Tasks {
+		if task.Err != nil {
+			failed = append(failed, task)
+		}
+	}
+	return failed
+}
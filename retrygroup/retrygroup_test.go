@@ -0,0 +1,133 @@
+package retrygroup
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/minitauros/go-retry"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGroup(t *testing.T) {
+	Convey("Group", t, func() {
+		group, _ := WithContext(context.Background())
+		policy := retry.NewPolicy().InitialDelay(0).MaxAttempts(3).Build()
+		expectedErr := errors.New("foo")
+
+		Convey("Retries every task according to its own policy", func() {
+			var numA, numB atomic.Int32
+			group.Go("a", policy, func(ctx context.Context) error {
+				if numA.Add(1) < 3 {
+					return expectedErr
+				}
+				return nil
+			})
+			group.Go("b", policy.With(retry.WithMaxAttempts(1)), func(ctx context.Context) error {
+				numB.Add(1)
+				return nil
+			})
+			So(group.Wait(), ShouldBeNil)
+			So(numA.Load(), ShouldEqual, 3)
+			So(numB.Load(), ShouldEqual, 1)
+		})
+
+		Convey("Passes the attempt in the context of the group", func() {
+			type key struct{}
+			group, _ := WithContext(context.WithValue(context.Background(), key{}, "foo"))
+			var attempt retry.Attempt
+			var ok bool
+			var value any
+			group.Go("a", policy, func(attemptCtx context.Context) error {
+				attempt, ok = retry.AttemptFromContext(attemptCtx)
+				value = attemptCtx.Value(key{})
+				return nil
+			})
+			So(group.Wait(), ShouldBeNil)
+			So(ok, ShouldBeTrue)
+			So(attempt.Number, ShouldEqual, 1)
+			So(value, ShouldEqual, "foo")
+		})
+
+		Convey("Applies the classifier of a task", func() {
+			var numCalled atomic.Int32
+			abort := retry.WithClassifier(retry.ClassifierFunc(func(error) retry.Action {
+				return retry.ActionAbort
+			}))
+			group.Go("a", policy.With(abort), func(ctx context.Context) error {
+				numCalled.Add(1)
+				return expectedErr
+			})
+			err := group.Wait()
+			So(err, ShouldWrap, expectedErr)
+			So(numCalled.Load(), ShouldEqual, 1)
+		})
+
+		Convey("Cancels the retries of the other tasks once a task fails permanently", func() {
+			slow := retry.NewPolicy().InitialDelay(time.Hour).MaxAttempts(3).Build()
+			slowStarted, okDone := make(chan struct{}), make(chan struct{})
+			group.Go("failing", policy, func(ctx context.Context) error {
+				<-slowStarted
+				<-okDone
+				return expectedErr
+			})
+			var once sync.Once
+			group.Go("slow", slow, func(ctx context.Context) error {
+				once.Do(func() { close(slowStarted) })
+				return errors.New("bar")
+			})
+			group.Go("ok", policy, func(ctx context.Context) error {
+				close(okDone)
+				return nil
+			})
+
+			done := make(chan error)
+			go func() {
+				done <- group.Wait()
+			}()
+			var err error
+			select {
+			case err = <-done:
+			case <-time.After(time.Second):
+				t.Fatal("the retries of the slow task were not cancelled")
+			}
+
+			Convey("Returning an error that preserves the outcome of every task", func() {
+				var groupErr *Error
+				So(errors.As(err, &groupErr), ShouldBeTrue)
+				So(groupErr.Tasks, ShouldHaveLength, 3)
+				So(groupErr.Tasks[0].Name, ShouldEqual, "failing")
+				So(groupErr.Tasks[0].NumAttempts, ShouldEqual, 3)
+				So(groupErr.Tasks[0].Err, ShouldWrap, retry.ErrMaxRetriesExceeded)
+				So(groupErr.Tasks[1].NumAttempts, ShouldEqual, 1)
+				So(groupErr.Tasks[1].Err, ShouldEqual, context.Canceled)
+				So(groupErr.Tasks[2], ShouldResemble, TaskResult{Name: "ok", NumAttempts: 1})
+				So(groupErr.Failed(), ShouldHaveLength, 2)
+
+				So(err, ShouldWrap, expectedErr)
+				So(err, ShouldWrap, context.Canceled)
+				So(err.Error(), ShouldEqual, `task "failing" failed after 3 attempts: max retries exceeded: foo (and 1 more tasks failed)`)
+			})
+		})
+
+		Convey("SetLimit() limits the number of tasks running at once", func() {
+			group.SetLimit(1)
+			var running, maxRunning atomic.Int32
+			for i := 0; i < 5; i++ {
+				group.Go("task", policy, func(ctx context.Context) error {
+					if n := running.Add(1); n > maxRunning.Load() {
+						maxRunning.Store(n)
+					}
+					time.Sleep(time.Millisecond)
+					running.Add(-1)
+					return nil
+				})
+			}
+			So(group.Wait(), ShouldBeNil)
+			So(maxRunning.Load(), ShouldEqual, 1)
+		})
+	})
+}
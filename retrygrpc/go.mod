@@ -0,0 +1,23 @@
+module github.com/minitauros/go-retry/retrygrpc
+
+go 1.23.3
+
+require (
+	github.com/minitauros/go-retry v0.0.0-20261014131146-506aa7fe2e9d
+	github.com/smartystreets/goconvey v1.8.1
+	google.golang.org/grpc v1.70.0
+)
+
+require (
+	github.com/gopherjs/gopherjs v1.17.2 // indirect
+	github.com/jtolds/gls v4.20.0+incompatible // indirect
+	github.com/smarty/assertions v1.15.0 // indirect
+	golang.org/x/net v0.32.0 // indirect
+	golang.org/x/sys v0.29.0 // indirect
+	golang.org/x/text v0.21.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20241202173237-19429a94021a // indirect
+	google.golang.org/protobuf v1.35.2 // indirect
+)
+
+// Build against the root module of this repository during development. Consumers get the required version.
+replace github.com/minitauros/go-retry => ../
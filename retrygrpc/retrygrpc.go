@@ -0,0 +1,60 @@
+// Package retrygrpc converts the backoff settings of the retry package to the backoff settings of gRPC, so that the
+// reconnection backoff of gRPC channels and the retries of the application can be tuned from one definition.
+package retrygrpc
+
+import (
+	"time"
+
+	"github.com/minitauros/go-retry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+)
+
+// BackoffConfig returns the gRPC backoff config equivalent to the given policy.
+//
+// gRPC requires a maximum delay, so if the policy has none, the maximum delay of backoff.DefaultConfig is used. gRPC
+// only supports randomizing delays by a factor in either direction, so jitter modes are converted to the factor that
+// spreads delays down to the same minimum: 1 for FullJitter and 0.5 for EqualJitter.
+func BackoffConfig(p retry.Policy) backoff.Config {
+	return backoffConfig(p.InitialDelay(), p.Multiplier(), p.MaxDelay(), jitterFactor(p.Jitter()))
+}
+
+// RetrierBackoffConfig returns the gRPC backoff config equivalent to the given retrier, like BackoffConfig. The
+// randomization factor of the retrier is used as is, unless its jitter mode spreads delays further.
+func RetrierBackoffConfig(r *retry.BackOffRetrier) backoff.Config {
+	return backoffConfig(r.InitialDelay(), r.BackOffCoefficient(), r.MaxDelay(), max(r.RandomizationFactor(), jitterFactor(r.Jitter())))
+}
+
+// ConnectParams returns the gRPC connect params that make a channel back off between reconnects like the given
+// policy. Pass them to grpc.WithConnectParams.
+func ConnectParams(p retry.Policy, minConnectTimeout time.Duration) grpc.ConnectParams {
+	return grpc.ConnectParams{
+		Backoff:           BackoffConfig(p),
+		MinConnectTimeout: minConnectTimeout,
+	}
+}
+
+// backoffConfig returns a gRPC backoff config with the given settings, defaulting the maximum delay.
+func backoffConfig(initialDelay time.Duration, multiplier float64, maxDelay time.Duration, jitter float64) backoff.Config {
+	if maxDelay <= 0 {
+		maxDelay = backoff.DefaultConfig.MaxDelay
+	}
+	return backoff.Config{
+		BaseDelay:  initialDelay,
+		Multiplier: multiplier,
+		Jitter:     jitter,
+		MaxDelay:   maxDelay,
+	}
+}
+
+// jitterFactor returns the randomization factor that spreads delays down to the same minimum as the given jitter mode.
+func jitterFactor(mode retry.JitterMode) float64 {
+	switch mode {
+	case retry.FullJitter:
+		return 1
+	case retry.EqualJitter:
+		return 0.5
+	default:
+		return 0
+	}
+}
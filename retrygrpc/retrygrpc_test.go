@@ -0,0 +1,62 @@
+package retrygrpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/minitauros/go-retry"
+	. "github.com/smartystreets/goconvey/convey"
+	"google.golang.org/grpc/backoff"
+)
+
+func TestBackoffConfig(t *testing.T) {
+	Convey("BackoffConfig()", t, func() {
+		Convey("Converts the settings of the policy", func() {
+			p := retry.NewPolicy().InitialDelay(time.Second).Multiplier(1.5).MaxDelay(time.Minute).Jitter(retry.EqualJitter).Build()
+			So(BackoffConfig(p), ShouldResemble, backoff.Config{
+				BaseDelay:  time.Second,
+				Multiplier: 1.5,
+				Jitter:     0.5,
+				MaxDelay:   time.Minute,
+			})
+		})
+
+		Convey("Defaults the maximum delay", func() {
+			p := retry.NewPolicy().Build()
+			So(BackoffConfig(p).MaxDelay, ShouldEqual, backoff.DefaultConfig.MaxDelay)
+		})
+
+		Convey("Converts full jitter", func() {
+			p := retry.NewPolicy().Jitter(retry.FullJitter).Build()
+			So(BackoffConfig(p).Jitter, ShouldEqual, 1)
+		})
+	})
+}
+
+func TestRetrierBackoffConfig(t *testing.T) {
+	Convey("RetrierBackoffConfig()", t, func() {
+		Convey("Converts the settings of the retrier", func() {
+			r := retry.NewBackOffRetrier(time.Second, 2, retry.WithMaxDelay(time.Minute), retry.WithRandomizationFactor(0.2))
+			So(RetrierBackoffConfig(r), ShouldResemble, backoff.Config{
+				BaseDelay:  time.Second,
+				Multiplier: 2,
+				Jitter:     0.2,
+				MaxDelay:   time.Minute,
+			})
+		})
+
+		Convey("Uses the jitter mode if it spreads delays further than the randomization factor", func() {
+			r := retry.NewBackOffRetrier(time.Second, 2, retry.WithJitter(retry.FullJitter), retry.WithRandomizationFactor(0.2))
+			So(RetrierBackoffConfig(r).Jitter, ShouldEqual, 1)
+		})
+	})
+}
+
+func TestConnectParams(t *testing.T) {
+	Convey("ConnectParams()", t, func() {
+		p := retry.NewPolicy().InitialDelay(time.Second).Build()
+		params := ConnectParams(p, 5*time.Second)
+		So(params.Backoff, ShouldResemble, BackoffConfig(p))
+		So(params.MinConnectTimeout, ShouldEqual, 5*time.Second)
+	})
+}
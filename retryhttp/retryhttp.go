@@ -0,0 +1,116 @@
+// Package retryhttp tags retried HTTP requests with their attempt number, and measures the retry pressure a server
+// receives from its clients.
+package retryhttp
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// AttemptHeader is the header that carries the attempt number of a request, counting from 1.
+const AttemptHeader = "Retry-Attempt"
+
+// SetAttempt sets the attempt number of the given request, counting from 1, so that the server can tell retries from
+// first attempts. Call it before sending every attempt.
+func SetAttempt(req *http.Request, attempt int) {
+	req.Header.Set(AttemptHeader, strconv.Itoa(attempt))
+}
+
+// Attempt returns the attempt number of the given request, or 1 if the request carries no valid attempt number.
+func Attempt(req *http.Request) int {
+	attempt, err := strconv.Atoi(req.Header.Get(AttemptHeader))
+	if err != nil || attempt < 1 {
+		return 1
+	}
+	return attempt
+}
+
+// ServerStats are the statistics of the requests received by a server.
+type ServerStats struct {
+	// Requests is the number of requests received, including retries.
+	Requests int64
+	// Retries is the number of requests that were retries, i.e. had an attempt number greater than 1.
+	Retries int64
+	// RetryDuration is the total time spent handling retries, which is work that clients already requested before.
+	RetryDuration time.Duration
+	// Attempts contains the number of requests received per attempt number. Attempt numbers from the maximum tracked
+	// attempt on are counted together under the maximum, so that clients can't grow the map by sending arbitrary
+	// attempt numbers.
+	Attempts map[int]int64
+}
+
+// RetryRatio returns the fraction of requests that were retries, which is the retry pressure received from clients.
+func (s ServerStats) RetryRatio() float64 {
+	if s.Requests == 0 {
+		return 0
+	}
+	return float64(s.Retries) / float64(s.Requests)
+}
+
+// ServerMetricsOption configures server metrics.
+type ServerMetricsOption func(*ServerMetrics)
+
+// WithMaxTrackedAttempt sets the attempt number from which requests are counted together in ServerStats.Attempts, which
+// is at least 2. Defaults to 10.
+func WithMaxTrackedAttempt(attempt int) ServerMetricsOption {
+	return func(m *ServerMetrics) {
+		m.maxAttempt = max(attempt, 2)
+	}
+}
+
+// ServerMetrics measures the retries received by a server. It is safe for concurrent use.
+type ServerMetrics struct {
+	maxAttempt int
+
+	mu    sync.Mutex
+	stats ServerStats
+}
+
+// NewServerMetrics returns new server metrics.
+func NewServerMetrics(opts ...ServerMetricsOption) *ServerMetrics {
+	m := &ServerMetrics{
+		maxAttempt: 10,
+		stats:      ServerStats{Attempts: make(map[int]int64)},
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Middleware returns a handler that records the attempt number of every request in the metrics, and then calls the
+// given handler.
+func (m *ServerMetrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		attempt := Attempt(req)
+		start := time.Now()
+		next.ServeHTTP(w, req)
+		m.record(attempt, time.Since(start))
+	})
+}
+
+// Stats returns a snapshot of the statistics.
+func (m *ServerMetrics) Stats() ServerStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := m.stats
+	stats.Attempts = make(map[int]int64, len(m.stats.Attempts))
+	for attempt, n := range m.stats.Attempts {
+		stats.Attempts[attempt] = n
+	}
+	return stats
+}
+
+// record records a handled request with the given attempt number and duration.
+func (m *ServerMetrics) record(attempt int, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stats.Requests++
+	m.stats.Attempts[min(attempt, m.maxAttempt)]++
+	if attempt > 1 {
+		m.stats.Retries++
+		m.stats.RetryDuration += d
+	}
+}
@@ -0,0 +1,97 @@
+package retryhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAttempt(t *testing.T) {
+	Convey("SetAttempt() and Attempt()", t, func() {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		Convey("Without a header, the request is the first attempt", func() {
+			So(Attempt(req), ShouldEqual, 1)
+		})
+
+		Convey("Attempt() returns the attempt number set using SetAttempt()", func() {
+			SetAttempt(req, 3)
+			So(req.Header.Get(AttemptHeader), ShouldEqual, "3")
+			So(Attempt(req), ShouldEqual, 3)
+		})
+
+		Convey("Invalid attempt numbers are treated as the first attempt", func() {
+			req.Header.Set(AttemptHeader, "foo")
+			So(Attempt(req), ShouldEqual, 1)
+			req.Header.Set(AttemptHeader, "0")
+			So(Attempt(req), ShouldEqual, 1)
+		})
+	})
+}
+
+func TestServerMetrics(t *testing.T) {
+	Convey("*ServerMetrics", t, func() {
+		metrics := NewServerMetrics()
+		var numHandled int
+		handler := metrics.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			numHandled++
+			if Attempt(req) > 1 {
+				time.Sleep(5 * time.Millisecond)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		serve := func(attempt int) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if attempt > 0 {
+				SetAttempt(req, attempt)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			return rec
+		}
+
+		Convey("Passes requests on to the next handler", func() {
+			So(serve(0).Code, ShouldEqual, http.StatusNoContent)
+			So(numHandled, ShouldEqual, 1)
+		})
+
+		Convey("Records the retries received", func() {
+			serve(0)
+			serve(1)
+			serve(2)
+			serve(3)
+
+			stats := metrics.Stats()
+			So(stats.Requests, ShouldEqual, 4)
+			So(stats.Retries, ShouldEqual, 2)
+			So(stats.RetryRatio(), ShouldEqual, 0.5)
+			So(stats.RetryDuration, ShouldBeGreaterThanOrEqualTo, 10*time.Millisecond)
+			So(stats.Attempts, ShouldResemble, map[int]int64{1: 2, 2: 1, 3: 1})
+
+			Convey("Stats() returns a snapshot", func() {
+				stats.Attempts[1] = 100
+				So(metrics.Stats().Attempts[1], ShouldEqual, 2)
+			})
+		})
+
+		Convey("Counts attempt numbers from the maximum tracked attempt on together", func() {
+			serve(9)
+			serve(10)
+			serve(1 << 40)
+			So(metrics.Stats().Attempts, ShouldResemble, map[int]int64{9: 1, 10: 2})
+
+			metrics = NewServerMetrics(WithMaxTrackedAttempt(3))
+			handler = metrics.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+			serve(2)
+			serve(5)
+			So(metrics.Stats().Attempts, ShouldResemble, map[int]int64{2: 1, 3: 1})
+		})
+
+		Convey("Without requests, the retry ratio is 0", func() {
+			So(metrics.Stats().RetryRatio(), ShouldEqual, 0)
+		})
+	})
+}
@@ -0,0 +1,19 @@
+module github.com/minitauros/go-retry/retrylogrus
+
+go 1.23.3
+
+require (
+	github.com/minitauros/go-retry v0.0.0-20261014131146-506aa7fe2e9d
+	github.com/sirupsen/logrus v1.9.3
+	github.com/smartystreets/goconvey v1.8.1
+)
+
+require (
+	github.com/gopherjs/gopherjs v1.17.2 // indirect
+	github.com/jtolds/gls v4.20.0+incompatible // indirect
+	github.com/smarty/assertions v1.15.0 // indirect
+	golang.org/x/sys v0.29.0 // indirect
+)
+
+// Build against the root module of this repository during development. Consumers get the required version.
+replace github.com/minitauros/go-retry => ../
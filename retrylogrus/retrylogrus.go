@@ -0,0 +1,72 @@
+// Package retrylogrus provides a retry.LogHook that logs the events of retry loops using logrus.
+package retrylogrus
+
+import (
+	"github.com/minitauros/go-retry"
+	"github.com/sirupsen/logrus"
+)
+
+// Levels are the levels at which the events of retry loops are logged.
+type Levels struct {
+	// Retry is the level of failed attempts that are retried.
+	Retry logrus.Level
+	// Success is the level of retry loops ending without an error.
+	Success logrus.Level
+	// Error is the level of retry loops ending with an error.
+	Error logrus.Level
+}
+
+// DefaultLevels are the default levels, matching retry.DefaultLogLevels.
+var DefaultLevels = Levels{
+	Retry:   logrus.WarnLevel,
+	Success: logrus.DebugLevel,
+	Error:   logrus.ErrorLevel,
+}
+
+// Hook is a retry.LogHook that logs using a logrus.FieldLogger, with fields for the operation, attempt, delay and
+// error.
+type Hook struct {
+	logger logrus.FieldLogger
+	levels Levels
+}
+
+var _ retry.LogHook = (*Hook)(nil)
+
+// NewHook returns a new log hook that logs using the given logger at the given levels. Pass it to retry.WithLogHook.
+func NewHook(logger logrus.FieldLogger, levels Levels) *Hook {
+	return &Hook{logger: logger, levels: levels}
+}
+
+// LogRetry implements retry.LogHook.
+func (h *Hook) LogRetry(event retry.RetryEvent) {
+	h.log(h.levels.Retry, "attempt failed, retrying", logrus.Fields{
+		"operation":     event.Operation,
+		"attempt":       event.Attempt,
+		"delay":         event.NextDelay,
+		logrus.ErrorKey: event.Err,
+	})
+}
+
+// LogSuccess implements retry.LogHook.
+func (h *Hook) LogSuccess(event retry.SuccessEvent) {
+	h.log(h.levels.Success, "succeeded", logrus.Fields{
+		"operation": event.Operation,
+		"attempts":  event.NumAttempts,
+		"elapsed":   event.Elapsed,
+	})
+}
+
+// LogError implements retry.LogHook.
+func (h *Hook) LogError(event retry.ErrorEvent) {
+	h.log(h.levels.Error, "failed", logrus.Fields{
+		"operation":     event.Operation,
+		"attempts":      event.NumAttempts,
+		"elapsed":       event.Elapsed,
+		logrus.ErrorKey: event.Err,
+	})
+}
+
+// log logs the given message with the given fields at the given level.
+func (h *Hook) log(level logrus.Level, msg string, fields logrus.Fields) {
+	h.logger.WithFields(fields).Log(level, msg)
+}
@@ -0,0 +1,68 @@
+package retrylogrus
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/minitauros/go-retry"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHook(t *testing.T) {
+	Convey("Hook", t, func() {
+		logger, logs := test.NewNullLogger()
+		logger.SetLevel(logrus.DebugLevel)
+		hook := NewHook(logger, DefaultLevels)
+		expectedErr := errors.New("foo")
+
+		Convey("Logs retries and successes", func() {
+			var numCalled int
+			retrier := retry.NewNoDelayRetrier(retry.WithName("op"), retry.WithLogHook(hook))
+			err := retrier.Retry(3, func() error {
+				numCalled++
+				if numCalled < 2 {
+					return expectedErr
+				}
+				return nil
+			})
+			So(err, ShouldBeNil)
+
+			entries := logs.AllEntries()
+			So(entries, ShouldHaveLength, 2)
+			So(entries[0].Level, ShouldEqual, logrus.WarnLevel)
+			So(entries[0].Message, ShouldEqual, "attempt failed, retrying")
+			So(entries[0].Data, ShouldResemble, logrus.Fields{
+				"operation":     "op",
+				"attempt":       1,
+				"delay":         time.Duration(0),
+				logrus.ErrorKey: expectedErr,
+			})
+			So(entries[1].Level, ShouldEqual, logrus.DebugLevel)
+			So(entries[1].Message, ShouldEqual, "succeeded")
+			So(entries[1].Data["attempts"], ShouldEqual, 2)
+		})
+
+		Convey("Logs errors", func() {
+			retrier := retry.NewNoDelayRetrier(retry.WithLogHook(hook))
+			err := retrier.Retry(0, func() error {
+				return expectedErr
+			})
+			entries := logs.AllEntries()
+			So(entries, ShouldHaveLength, 1)
+			So(entries[0].Level, ShouldEqual, logrus.ErrorLevel)
+			So(entries[0].Data[logrus.ErrorKey], ShouldEqual, err)
+		})
+
+		Convey("Logs at the given levels", func() {
+			hook := NewHook(logger.WithField("component", "sync"), Levels{Success: logrus.InfoLevel})
+			_ = retry.NewNoDelayRetrier(retry.WithLogHook(hook)).Retry(0, func() error {
+				return nil
+			})
+			So(logs.LastEntry().Level, ShouldEqual, logrus.InfoLevel)
+			So(logs.LastEntry().Data["component"], ShouldEqual, "sync")
+		})
+	})
+}
@@ -0,0 +1,26 @@
+module github.com/minitauros/go-retry/retryotel
+
+go 1.23.3
+
+require (
+	github.com/minitauros/go-retry v0.0.0-20261014131146-506aa7fe2e9d
+	github.com/smartystreets/goconvey v1.8.1
+	go.opentelemetry.io/otel v1.34.0
+	go.opentelemetry.io/otel/sdk v1.34.0
+	go.opentelemetry.io/otel/trace v1.34.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/gopherjs/gopherjs v1.17.2 // indirect
+	github.com/jtolds/gls v4.20.0+incompatible // indirect
+	github.com/smarty/assertions v1.15.0 // indirect
+	go.opentelemetry.io/auto/sdk v1.1.0 // indirect
+	go.opentelemetry.io/otel/metric v1.34.0 // indirect
+	golang.org/x/sys v0.29.0 // indirect
+)
+
+// Build against the root module of this repository during development. Consumers get the required version.
+replace github.com/minitauros/go-retry => ../
@@ -0,0 +1,102 @@
+// Package retryotel integrates the retry package with OpenTelemetry.
+package retryotel
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// BaggageKeyAttempt is the baggage key under which the attempt number is propagated, counting from 1.
+	// Downstream services can use it to see they are handling a retry, and prioritize or shed load accordingly.
+	BaggageKeyAttempt = "retry.attempt"
+	// AttributeAttempt is the span attribute containing the attempt number, counting from 1.
+	AttributeAttempt = attribute.Key("retry.attempt")
+	// AttributeDelay is the span attribute containing the time between the end of the previous attempt and the start
+	// of the attempt, in seconds. It is not set on the first attempt.
+	AttributeDelay = attribute.Key("retry.delay")
+)
+
+// instrumentationName is the name of the tracer created if no tracer provider is given.
+const instrumentationName = "github.com/minitauros/go-retry/retryotel"
+
+// Option configures the tracing of attempts.
+type Option func(*config)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+}
+
+// WithTracerProvider makes attempts create spans using the given tracer provider, instead of the global one.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(cfg *config) {
+		cfg.tracerProvider = provider
+	}
+}
+
+// Trace returns a callback to pass to a retrier, which calls the given callback in a span of its own for every
+// attempt. The spans are children of the span in the given context, and every span links to the span of the
+// previous attempt. Spans have the attempt number and the delay since the previous attempt as attributes, and the
+// status of failed attempts is set to error. The attempt number is added to the baggage of the context passed to the
+// callback, so it is propagated to downstream services.
+//
+// The returned callback must only be used for a single retry loop.
+func Trace(ctx context.Context, spanName string, cb func(ctx context.Context) error, opts ...Option) func() error {
+	cfg := config{tracerProvider: otel.GetTracerProvider()}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	tracer := cfg.tracerProvider.Tracer(instrumentationName)
+
+	var attempt int
+	var prev trace.SpanContext
+	var prevEnd time.Time
+	return func() error {
+		attempt++
+
+		spanOpts := []trace.SpanStartOption{
+			trace.WithAttributes(AttributeAttempt.Int(attempt)),
+		}
+		if prev.IsValid() {
+			spanOpts = append(spanOpts,
+				trace.WithLinks(trace.Link{SpanContext: prev}),
+				trace.WithAttributes(AttributeDelay.Float64(time.Since(prevEnd).Seconds())),
+			)
+		}
+		attemptCtx, span := tracer.Start(ctx, spanName, spanOpts...)
+		defer func() {
+			span.End()
+			prevEnd = time.Now()
+		}()
+		prev = span.SpanContext()
+
+		attemptCtx = withAttemptBaggage(attemptCtx, attempt)
+		err := cb(attemptCtx)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return err
+	}
+}
+
+// withAttemptBaggage returns a copy of the given context with the given attempt number added to its baggage.
+// If the attempt number cannot be added, the context is returned as is.
+func withAttemptBaggage(ctx context.Context, attempt int) context.Context {
+	member, err := baggage.NewMember(BaggageKeyAttempt, strconv.Itoa(attempt))
+	if err != nil {
+		return ctx
+	}
+	bag, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		return ctx
+	}
+	return baggage.ContextWithBaggage(ctx, bag)
+}
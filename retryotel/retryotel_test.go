@@ -0,0 +1,85 @@
+package retryotel
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/minitauros/go-retry"
+)
+
+func TestTrace(t *testing.T) {
+	Convey("Trace()", t, func() {
+		recorder := tracetest.NewSpanRecorder()
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		var numCalled int
+		var attempts []string
+
+		parentCtx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+		retrier := retry.NewConstantDelayRetrier(5 * time.Millisecond)
+		err := retrier.Retry(2, Trace(parentCtx, "fetch", func(ctx context.Context) error {
+			numCalled++
+			attempts = append(attempts, baggage.FromContext(ctx).Member(BaggageKeyAttempt).Value())
+			if numCalled == 3 {
+				return nil
+			}
+			return errors.New("foo")
+		}, WithTracerProvider(provider)))
+		So(err, ShouldBeNil)
+		parent.End()
+		spans := recorder.Ended()[:3]
+
+		Convey("Creates a span per attempt", func() {
+			So(spans, ShouldHaveLength, 3)
+			for i, span := range spans {
+				So(span.Name(), ShouldEqual, "fetch")
+				So(span.Attributes(), ShouldContain, AttributeAttempt.Int(i+1))
+			}
+		})
+
+		Convey("Creates the spans as children of the span in the given context", func() {
+			for _, span := range spans {
+				So(span.Parent().SpanID(), ShouldEqual, parent.SpanContext().SpanID())
+			}
+		})
+
+		Convey("Sets the delay since the previous attempt", func() {
+			hasDelay := func(span sdktrace.ReadOnlySpan) bool {
+				for _, attr := range span.Attributes() {
+					if attr.Key == AttributeDelay {
+						So(attr.Value.AsFloat64(), ShouldBeGreaterThanOrEqualTo, 0.005)
+						return true
+					}
+				}
+				return false
+			}
+			So(hasDelay(spans[0]), ShouldBeFalse)
+			So(hasDelay(spans[1]), ShouldBeTrue)
+			So(hasDelay(spans[2]), ShouldBeTrue)
+		})
+
+		Convey("Links every span to the span of the previous attempt", func() {
+			So(spans[0].Links(), ShouldBeEmpty)
+			So(spans[1].Links(), ShouldHaveLength, 1)
+			So(spans[1].Links()[0].SpanContext.SpanID(), ShouldEqual, spans[0].SpanContext().SpanID())
+			So(spans[2].Links()[0].SpanContext.SpanID(), ShouldEqual, spans[1].SpanContext().SpanID())
+		})
+
+		Convey("Marks failed attempts as errors", func() {
+			So(spans[0].Status().Code, ShouldEqual, codes.Error)
+			So(spans[1].Status().Code, ShouldEqual, codes.Error)
+			So(spans[2].Status().Code, ShouldEqual, codes.Unset)
+		})
+
+		Convey("Propagates the attempt number as baggage", func() {
+			So(attempts, ShouldResemble, []string{"1", "2", "3"})
+		})
+	})
+}
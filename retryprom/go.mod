@@ -0,0 +1,27 @@
+module github.com/minitauros/go-retry/retryprom
+
+go 1.23.3
+
+require (
+	github.com/minitauros/go-retry v0.0.0-20261014131146-506aa7fe2e9d
+	github.com/prometheus/client_golang v1.20.5
+	github.com/smartystreets/goconvey v1.8.1
+)
+
+require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/gopherjs/gopherjs v1.17.2 // indirect
+	github.com/jtolds/gls v4.20.0+incompatible // indirect
+	github.com/kylelemons/godebug v1.1.0 // indirect
+	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
+	github.com/prometheus/client_model v0.6.1 // indirect
+	github.com/prometheus/common v0.55.0 // indirect
+	github.com/prometheus/procfs v0.15.1 // indirect
+	github.com/smarty/assertions v1.15.0 // indirect
+	golang.org/x/sys v0.29.0 // indirect
+	google.golang.org/protobuf v1.35.2 // indirect
+)
+
+// Build against the root module of this repository during development. Consumers get the required version.
+replace github.com/minitauros/go-retry => ../
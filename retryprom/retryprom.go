@@ -0,0 +1,126 @@
+// Package retryprom provides a Prometheus collector for the events of retry loops.
+package retryprom
+
+import (
+	"errors"
+
+	"github.com/minitauros/go-retry"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Option configures a collector.
+type Option func(*config)
+
+type config struct {
+	namespace string
+	buckets   []float64
+}
+
+// WithNamespace prefixes the names of the metrics with the given namespace, e.g. "myapp" for
+// "myapp_retry_attempts_total".
+func WithNamespace(namespace string) Option {
+	return func(cfg *config) {
+		cfg.namespace = namespace
+	}
+}
+
+// WithDelayBuckets sets the buckets of the delay histogram, in seconds. Defaults to prometheus.DefBuckets.
+func WithDelayBuckets(buckets []float64) Option {
+	return func(cfg *config) {
+		cfg.buckets = buckets
+	}
+}
+
+// Collector is a prometheus.Collector that counts the attempts, successes and exhaustions of retry loops, and observes
+// the delays before retries. All metrics have a "name" label containing the name of the retrier, as set using
+// retry.WithName. Register it on any prometheus.Registerer, and pass the options returned by Options to the retriers to
+// measure.
+type Collector struct {
+	attempts    *prometheus.CounterVec
+	successes   *prometheus.CounterVec
+	exhaustions *prometheus.CounterVec
+	delays      *prometheus.HistogramVec
+}
+
+var _ prometheus.Collector = (*Collector)(nil)
+
+// NewCollector returns a new collector.
+func NewCollector(opts ...Option) *Collector {
+	cfg := config{buckets: prometheus.DefBuckets}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	labels := []string{"name"}
+	return &Collector{
+		attempts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.namespace,
+			Subsystem: "retry",
+			Name:      "attempts_total",
+			Help:      "Number of attempts made by retry loops.",
+		}, labels),
+		successes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.namespace,
+			Subsystem: "retry",
+			Name:      "successes_total",
+			Help:      "Number of retry loops that ended without an error.",
+		}, labels),
+		exhaustions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.namespace,
+			Subsystem: "retry",
+			Name:      "exhaustions_total",
+			Help:      "Number of retry loops that gave up, e.g. because the maximum number of retries was reached.",
+		}, labels),
+		delays: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: cfg.namespace,
+			Subsystem: "retry",
+			Name:      "delay_seconds",
+			Help:      "Delays before retries.",
+			Buckets:   cfg.buckets,
+		}, labels),
+	}
+}
+
+// Options returns the options that make a retrier report to the collector. They add hooks using retry.WithHooks, so
+// they don't replace hooks set using retry.WithOnRetry, retry.WithOnSuccess and retry.WithOnError.
+func (c *Collector) Options() []retry.Option {
+	return []retry.Option{
+		retry.WithHooks(retry.Hooks{
+			OnRetry:   c.observeRetry,
+			OnSuccess: c.observeSuccess,
+			OnError:   c.observeError,
+		}),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.attempts.Describe(ch)
+	c.successes.Describe(ch)
+	c.exhaustions.Describe(ch)
+	c.delays.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.attempts.Collect(ch)
+	c.successes.Collect(ch)
+	c.exhaustions.Collect(ch)
+	c.delays.Collect(ch)
+}
+
+func (c *Collector) observeRetry(event retry.RetryEvent) {
+	c.delays.WithLabelValues(event.Operation).Observe(event.NextDelay.Seconds())
+}
+
+func (c *Collector) observeSuccess(event retry.SuccessEvent) {
+	c.attempts.WithLabelValues(event.Operation).Add(float64(event.NumAttempts))
+	c.successes.WithLabelValues(event.Operation).Inc()
+}
+
+func (c *Collector) observeError(event retry.ErrorEvent) {
+	c.attempts.WithLabelValues(event.Operation).Add(float64(event.NumAttempts))
+	var retryErr *retry.Error
+	if errors.As(event.Err, &retryErr) {
+		c.exhaustions.WithLabelValues(event.Operation).Inc()
+	}
+}
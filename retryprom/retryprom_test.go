@@ -0,0 +1,89 @@
+package retryprom
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/minitauros/go-retry"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCollector(t *testing.T) {
+	Convey("Collector", t, func() {
+		collector := NewCollector(WithDelayBuckets([]float64{0.001, 0.01}))
+		registry := prometheus.NewRegistry()
+		So(registry.Register(collector), ShouldBeNil)
+		expectedErr := errors.New("foo")
+
+		Convey("Counts attempts and successes, and observes delays", func() {
+			var numCalled int
+			retrier := retry.NewConstantDelayRetrier(time.Millisecond, append(collector.Options(), retry.WithName("op"))...)
+			err := retrier.Retry(5, func() error {
+				numCalled++
+				if numCalled < 3 {
+					return expectedErr
+				}
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(testutil.ToFloat64(collector.attempts.WithLabelValues("op")), ShouldEqual, 3)
+			So(testutil.ToFloat64(collector.successes.WithLabelValues("op")), ShouldEqual, 1)
+			So(testutil.ToFloat64(collector.exhaustions.WithLabelValues("op")), ShouldEqual, 0)
+			So(testutil.CollectAndCount(collector.delays), ShouldEqual, 1)
+
+			families, err := registry.Gather()
+			So(err, ShouldBeNil)
+			for _, family := range families {
+				if family.GetName() == "retry_delay_seconds" {
+					So(family.GetMetric()[0].GetHistogram().GetSampleCount(), ShouldEqual, 2)
+				}
+			}
+		})
+
+		Convey("Counts exhaustions, keyed by name", func() {
+			retrier := retry.NewNoDelayRetrier(append(collector.Options(), retry.WithName("other"))...)
+			_ = retrier.Retry(1, func() error {
+				return expectedErr
+			})
+			So(testutil.ToFloat64(collector.attempts.WithLabelValues("other")), ShouldEqual, 2)
+			So(testutil.ToFloat64(collector.exhaustions.WithLabelValues("other")), ShouldEqual, 1)
+			So(testutil.ToFloat64(collector.successes.WithLabelValues("other")), ShouldEqual, 0)
+		})
+
+		Convey("Keeps the hooks of the retrier", func() {
+			var numRetries, numErrors int
+			opts := []retry.Option{
+				retry.WithOnRetry(func(retry.RetryEvent) { numRetries++ }),
+				retry.WithOnError(func(retry.ErrorEvent) { numErrors++ }),
+			}
+			retrier := retry.NewNoDelayRetrier(append(opts, collector.Options()...)...)
+			_ = retrier.Retry(1, func() error {
+				return expectedErr
+			})
+			So(numRetries, ShouldEqual, 1)
+			So(numErrors, ShouldEqual, 1)
+			So(testutil.ToFloat64(collector.exhaustions.WithLabelValues("")), ShouldEqual, 1)
+		})
+
+		Convey("Does not count errors that aren't exhaustions as such", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_ = retry.NewNoDelayRetrier(collector.Options()...).RetryCtx(ctx, 1, func() error {
+				return nil
+			})
+			So(testutil.ToFloat64(collector.exhaustions.WithLabelValues("")), ShouldEqual, 0)
+		})
+
+		Convey("WithNamespace() prefixes the metric names", func() {
+			collector := NewCollector(WithNamespace("app"))
+			_ = retry.NewNoDelayRetrier(collector.Options()...).Retry(0, func() error {
+				return nil
+			})
+			So(testutil.CollectAndCount(collector, "app_retry_successes_total"), ShouldEqual, 1)
+		})
+	})
+}
@@ -0,0 +1,23 @@
+module github.com/minitauros/go-retry/retryredis
+
+go 1.23.3
+
+require (
+	github.com/alicebob/miniredis/v2 v2.33.0
+	github.com/minitauros/go-retry v0.0.0-20261014131146-506aa7fe2e9d
+	github.com/redis/go-redis/v9 v9.7.0
+	github.com/smartystreets/goconvey v1.8.1
+)
+
+require (
+	github.com/alicebob/gopher-json v0.0.0-20200520072559-a9ecdc9d1d3a // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/gopherjs/gopherjs v1.17.2 // indirect
+	github.com/jtolds/gls v4.20.0+incompatible // indirect
+	github.com/smarty/assertions v1.15.0 // indirect
+	github.com/yuin/gopher-lua v1.1.1 // indirect
+)
+
+// Build against the root module of this repository during development. Consumers get the required version.
+replace github.com/minitauros/go-retry => ../
@@ -0,0 +1,206 @@
+// Package retryredis provides a Redis-backed store that coordinates the retriers of multiple processes: it shares
+// retry budgets, backoff states and dependency marks, so that a fleet doesn't retry-storm a dependency that is down.
+package retryredis
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/minitauros/go-retry"
+	"github.com/redis/go-redis/v9"
+)
+
+// Option configures a store.
+type Option func(*Store)
+
+// WithPrefix prefixes all keys of the store with the given prefix. Defaults to "retry:".
+func WithPrefix(prefix string) Option {
+	return func(s *Store) {
+		s.prefix = prefix
+	}
+}
+
+// WithTTL makes budgets and backoff states expire when they weren't updated for the given duration, so that the keys
+// of dependencies that are no longer used don't pile up. An expired budget starts full again. A TTL of 0 or less makes
+// them never expire. Defaults to 24 hours.
+func WithTTL(ttl time.Duration) Option {
+	return func(s *Store) {
+		s.ttl = ttl
+	}
+}
+
+// Store is a retry.BudgetStore, retry.BackoffStore and retry.CoordinationStore that keeps its state in Redis. Every
+// update is made by a single script, so that concurrent updates by multiple processes don't get lost.
+type Store struct {
+	client redis.Cmdable
+	prefix string
+	ttl    time.Duration
+}
+
+var (
+	_ retry.BudgetStore       = (*Store)(nil)
+	_ retry.BackoffStore      = (*Store)(nil)
+	_ retry.CoordinationStore = (*Store)(nil)
+)
+
+// New returns a new store using the given client, like a *redis.Client or a *redis.ClusterClient.
+func New(client redis.Cmdable, opts ...Option) *Store {
+	s := &Store{
+		client: client,
+		prefix: "retry:",
+		ttl:    24 * time.Hour,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// depositScript adds ARGV[1] tokens to the budget at KEYS[1], up to ARGV[2] tokens, expiring it after ARGV[3]
+// milliseconds unless that is 0. Numbers are returned as strings, because Redis truncates Lua numbers to integers.
+var depositScript = redis.NewScript(`
+local tokens = tonumber(redis.call('GET', KEYS[1])) or tonumber(ARGV[2])
+tokens = math.min(tokens + tonumber(ARGV[1]), tonumber(ARGV[2]))
+if tonumber(ARGV[3]) > 0 then
+	redis.call('SET', KEYS[1], tostring(tokens), 'PX', ARGV[3])
+else
+	redis.call('SET', KEYS[1], tostring(tokens))
+end
+return tostring(tokens)
+`)
+
+// withdrawScript withdraws a token from the budget at KEYS[1], which holds at max ARGV[1] tokens, if it holds at least
+// one, expiring it after ARGV[2] milliseconds unless that is 0.
+var withdrawScript = redis.NewScript(`
+local tokens = tonumber(redis.call('GET', KEYS[1])) or tonumber(ARGV[1])
+local ok = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	ok = 1
+end
+if tonumber(ARGV[2]) > 0 then
+	redis.call('SET', KEYS[1], tostring(tokens), 'PX', ARGV[2])
+else
+	redis.call('SET', KEYS[1], tostring(tokens))
+end
+return {tostring(tokens), ok}
+`)
+
+// saveBackoffScript saves ARGV[1] failures and a delay of ARGV[2] to the backoff state at KEYS[1], unless it has more
+// failures already, expiring it after ARGV[3] milliseconds unless that is 0.
+var saveBackoffScript = redis.NewScript(`
+local failures = tonumber(redis.call('HGET', KEYS[1], 'failures'))
+if failures == nil or tonumber(ARGV[1]) > failures then
+	redis.call('HSET', KEYS[1], 'failures', ARGV[1], 'delay', ARGV[2])
+end
+if tonumber(ARGV[3]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+end
+return 0
+`)
+
+// markDownScript marks the dependency at KEYS[1] down until the Unix time ARGV[1], in milliseconds, unless it is
+// marked down longer already, expiring the mark after ARGV[2] milliseconds.
+var markDownScript = redis.NewScript(`
+local marked = tonumber(redis.call('GET', KEYS[1]))
+if marked == nil or tonumber(ARGV[1]) > marked then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+end
+return 0
+`)
+
+// Deposit implements retry.BudgetStore.
+func (s *Store) Deposit(ctx context.Context, key string, tokens, maxTokens float64) (float64, error) {
+	res, err := depositScript.Run(ctx, s.client, []string{s.key("budget", key)}, tokens, maxTokens, s.ttlMillis()).Text()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(res, 64)
+}
+
+// Withdraw implements retry.BudgetStore.
+func (s *Store) Withdraw(ctx context.Context, key string, maxTokens float64) (float64, bool, error) {
+	res, err := withdrawScript.Run(ctx, s.client, []string{s.key("budget", key)}, maxTokens, s.ttlMillis()).Slice()
+	if err != nil {
+		return 0, false, err
+	}
+	if len(res) != 2 {
+		return 0, false, errors.New("unexpected reply of withdraw script")
+	}
+	text, _ := res[0].(string)
+	tokens, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, false, err
+	}
+	ok, _ := res[1].(int64)
+	return tokens, ok == 1, nil
+}
+
+// Load implements retry.BackoffStore.
+func (s *Store) Load(ctx context.Context, key string) (retry.BackoffState, error) {
+	res, err := s.client.HMGet(ctx, s.key("backoff", key), "failures", "delay").Result()
+	if err != nil {
+		return retry.BackoffState{}, err
+	}
+	var state retry.BackoffState
+	if failures, ok := res[0].(string); ok {
+		if state.Failures, err = strconv.Atoi(failures); err != nil {
+			return retry.BackoffState{}, err
+		}
+	}
+	if delay, ok := res[1].(string); ok {
+		nanos, err := strconv.ParseInt(delay, 10, 64)
+		if err != nil {
+			return retry.BackoffState{}, err
+		}
+		state.Delay = time.Duration(nanos)
+	}
+	return state, nil
+}
+
+// Save implements retry.BackoffStore.
+func (s *Store) Save(ctx context.Context, key string, state retry.BackoffState) error {
+	return saveBackoffScript.Run(ctx, s.client, []string{s.key("backoff", key)}, state.Failures, int64(state.Delay), s.ttlMillis()).Err()
+}
+
+// Reset implements retry.BackoffStore.
+func (s *Store) Reset(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.key("backoff", key)).Err()
+}
+
+// MarkDown implements retry.CoordinationStore. The mark expires once it has passed.
+func (s *Store) MarkDown(ctx context.Context, dependency string, until time.Time) error {
+	ttl := time.Until(until).Milliseconds()
+	if ttl <= 0 {
+		return nil
+	}
+	return markDownScript.Run(ctx, s.client, []string{s.key("down", dependency)}, until.UnixMilli(), ttl).Err()
+}
+
+// DownUntil implements retry.CoordinationStore. It returns false for marks that have passed.
+func (s *Store) DownUntil(ctx context.Context, dependency string) (time.Time, bool, error) {
+	millis, err := s.client.Get(ctx, s.key("down", dependency)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return time.UnixMilli(millis), true, nil
+}
+
+// ttlMillis returns the TTL of the store in milliseconds, rounded up so that short TTLs don't become 0, or 0 if keys
+// never expire.
+func (s *Store) ttlMillis() int64 {
+	if s.ttl <= 0 {
+		return 0
+	}
+	return int64((s.ttl + time.Millisecond - 1) / time.Millisecond)
+}
+
+// key returns the Redis key of the given kind of state of the given key.
+func (s *Store) key(kind, key string) string {
+	return s.prefix + kind + ":" + key
+}
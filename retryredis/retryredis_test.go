@@ -0,0 +1,116 @@
+package retryredis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/minitauros/go-retry"
+	"github.com/redis/go-redis/v9"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestStore(t *testing.T) {
+	Convey("Store", t, func() {
+		ctx := context.Background()
+		server := miniredis.RunT(t)
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		defer client.Close()
+		store := New(client, WithPrefix("test:"), WithTTL(time.Minute))
+		expectedErr := errors.New("foo")
+		var numCalled int
+		failing := func() error {
+			numCalled++
+			return expectedErr
+		}
+
+		Convey("Shares retry budgets", func() {
+			tokens, err := store.Deposit(ctx, "db", 0.5, 2)
+			So(err, ShouldBeNil)
+			So(tokens, ShouldEqual, 2)
+
+			a := retry.NewNoDelayRetrier(retry.WithBudget(retry.NewSharedBudget(store, "db", 0.5, 2)))
+			b := retry.NewNoDelayRetrier(retry.WithBudget(retry.NewSharedBudget(store, "db", 0.5, 2)))
+			_ = a.Retry(1, failing)
+			So(b.Retry(5, failing), ShouldEqual, expectedErr)
+			So(numCalled, ShouldEqual, 4)
+
+			tokens, ok, err := store.Withdraw(ctx, "db", 2)
+			So(err, ShouldBeNil)
+			So(ok, ShouldBeFalse)
+			So(tokens, ShouldEqual, 0.5)
+			So(server.TTL("test:budget:db"), ShouldEqual, time.Minute)
+		})
+
+		Convey("Shares backoff states", func() {
+			state, err := store.Load(ctx, "db")
+			So(err, ShouldBeNil)
+			So(state, ShouldResemble, retry.BackoffState{})
+
+			So(store.Save(ctx, "db", retry.BackoffState{Failures: 3, Delay: time.Second}), ShouldBeNil)
+			So(store.Save(ctx, "db", retry.BackoffState{Failures: 2, Delay: time.Minute}), ShouldBeNil)
+			state, err = store.Load(ctx, "db")
+			So(err, ShouldBeNil)
+			So(state, ShouldResemble, retry.BackoffState{Failures: 3, Delay: time.Second})
+			So(server.TTL("test:backoff:db"), ShouldEqual, time.Minute)
+
+			retrier := retry.NewBackOffRetrier(0, 2, retry.WithSharedBackoff(store, "db"))
+			_ = retrier.Retry(1, failing)
+			state, _ = store.Load(ctx, "db")
+			So(state, ShouldResemble, retry.BackoffState{Failures: 4, Delay: 2 * time.Second})
+
+			So(store.Reset(ctx, "db"), ShouldBeNil)
+			state, _ = store.Load(ctx, "db")
+			So(state, ShouldResemble, retry.BackoffState{})
+		})
+
+		Convey("Marks dependencies down", func() {
+			_, ok, err := store.DownUntil(ctx, "db")
+			So(err, ShouldBeNil)
+			So(ok, ShouldBeFalse)
+
+			until := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+			So(store.MarkDown(ctx, "db", until), ShouldBeNil)
+			So(store.MarkDown(ctx, "db", until.Add(-time.Minute)), ShouldBeNil)
+			downUntil, ok, err := store.DownUntil(ctx, "db")
+			So(err, ShouldBeNil)
+			So(ok, ShouldBeTrue)
+			So(downUntil.Equal(until), ShouldBeTrue)
+
+			retrier := retry.NewNoDelayRetrier(retry.WithCoordination(store, "db", time.Minute))
+			So(retrier.Retry(1, failing), ShouldEqual, retry.ErrDependencyDown)
+			So(numCalled, ShouldEqual, 0)
+
+			server.FastForward(time.Hour)
+			_, ok, _ = store.DownUntil(ctx, "db")
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("Never expires keys if the TTL is 0 or less", func() {
+			for _, ttl := range []time.Duration{0, -time.Second} {
+				store := New(client, WithPrefix("forever:"), WithTTL(ttl))
+				_, err := store.Deposit(ctx, "db", 0.5, 2)
+				So(err, ShouldBeNil)
+				_, _, err = store.Withdraw(ctx, "db", 2)
+				So(err, ShouldBeNil)
+				So(store.Save(ctx, "db", retry.BackoffState{Failures: 1, Delay: time.Second}), ShouldBeNil)
+				So(server.TTL("forever:budget:db"), ShouldEqual, 0)
+				So(server.TTL("forever:backoff:db"), ShouldEqual, 0)
+			}
+		})
+
+		Convey("Returns the errors of Redis", func() {
+			server.Close()
+			_, err := store.Deposit(ctx, "db", 0.5, 2)
+			So(err, ShouldNotBeNil)
+			_, _, err = store.Withdraw(ctx, "db", 2)
+			So(err, ShouldNotBeNil)
+			_, err = store.Load(ctx, "db")
+			So(err, ShouldNotBeNil)
+			_, _, err = store.DownUntil(ctx, "db")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
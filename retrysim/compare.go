@@ -0,0 +1,168 @@
+package retrysim
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/minitauros/go-retry"
+)
+
+// ErrNoCandidate is returned by Recommend when none of the policies it compares meets the constraints.
+var ErrNoCandidate = errors.New("retrysim: no policy meets the constraints")
+
+// errUnavailable is the error of attempts made before the dependency recovered.
+var errUnavailable = errors.New("retrysim: unavailable")
+
+// Distribution returns a random recovery time: the time it takes a dependency to recover from an outage.
+type Distribution func(r *rand.Rand) time.Duration
+
+// FixedRecovery returns a distribution of which every outage takes the given time to recover.
+func FixedRecovery(d time.Duration) Distribution {
+	return func(*rand.Rand) time.Duration {
+		return d
+	}
+}
+
+// UniformRecovery returns a distribution of recovery times spread evenly between the given minimum and maximum.
+func UniformRecovery(minimum, maximum time.Duration) Distribution {
+	return func(r *rand.Rand) time.Duration {
+		return minimum + time.Duration(r.Int64N(int64(maximum-minimum)+1))
+	}
+}
+
+// ExponentialRecovery returns a distribution of recovery times with the given mean, in which most outages are brief and
+// a few take very long.
+func ExponentialRecovery(mean time.Duration) Distribution {
+	return func(r *rand.Rand) time.Duration {
+		return time.Duration(r.ExpFloat64() * float64(mean))
+	}
+}
+
+// Candidate is a policy to compare.
+type Candidate struct {
+	// Name describes the policy in the results.
+	Name string
+	// Policy is the policy to compare.
+	Policy retry.Policy
+}
+
+// Result describes how a policy performed across simulated outages.
+type Result struct {
+	Candidate
+	// SuccessRate is the ratio of outages the policy outlasted, instead of giving up before the dependency recovered.
+	SuccessRate float64
+	// MeanTimeToSuccess is the mean time between the first attempt and the successful attempt, over the outages the
+	// policy outlasted.
+	MeanTimeToSuccess time.Duration
+	// MeanAttempts is the mean number of attempts per outage, including the first one.
+	MeanAttempts float64
+}
+
+// ExtraLoad returns the mean number of retries per outage: the load the policy adds to the dependency on top of the
+// first attempts.
+func (r Result) ExtraLoad() float64 {
+	return r.MeanAttempts - 1
+}
+
+// Compare simulates the given number of outages with recovery times from the given distribution, seeded with the
+// given seed, and returns how each of the given policies performed, in order. During an outage, attempts fail until
+// the recovery time has passed since the first attempt, and take no time themselves.
+func Compare(recovery Distribution, numOutages int, seed uint64, candidates ...Candidate) []Result {
+	r := rand.New(rand.NewPCG(seed, seed))
+	recoveries := make([]time.Duration, numOutages)
+	for i := range recoveries {
+		recoveries[i] = recovery(r)
+	}
+
+	results := make([]Result, len(candidates))
+	for i, candidate := range candidates {
+		results[i] = simulateOutages(candidate, recoveries)
+	}
+	return results
+}
+
+// Constraints are the constraints that Recommend recommends a policy within.
+type Constraints struct {
+	// MaxAttempts is the maximum number of attempts of the policies to compare. Defaults to 10.
+	MaxAttempts int
+	// MaxExtraLoad is the maximum mean number of retries per outage. 0 means no maximum.
+	MaxExtraLoad float64
+	// MinSuccessRate is the minimum ratio of outages that the policy must outlast, from 0 to 1.
+	MinSuccessRate float64
+}
+
+// recommendInitialDelays and recommendMultipliers span the policies that Recommend compares.
+var (
+	recommendInitialDelays = []time.Duration{
+		10 * time.Millisecond, 50 * time.Millisecond, 100 * time.Millisecond, 250 * time.Millisecond,
+		500 * time.Millisecond, time.Second, 2 * time.Second, 5 * time.Second, 10 * time.Second,
+	}
+	recommendMultipliers = []float64{1, 1.5, 2, 3}
+)
+
+// Recommend compares exponential backoff policies with a range of initial delays and multipliers, like Compare, and
+// returns the one with the lowest mean time to success that meets the given constraints. Ties are broken by the
+// lowest extra load. If no policy meets the constraints, ErrNoCandidate is returned.
+func Recommend(recovery Distribution, constraints Constraints, numOutages int, seed uint64) (Result, error) {
+	maxAttempts := constraints.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 10
+	}
+	var candidates []Candidate
+	for _, initialDelay := range recommendInitialDelays {
+		for _, multiplier := range recommendMultipliers {
+			candidates = append(candidates, Candidate{
+				Name:   fmt.Sprintf("initial delay %s, multiplier %g", initialDelay, multiplier),
+				Policy: retry.NewPolicy().MaxAttempts(maxAttempts).InitialDelay(initialDelay).Multiplier(multiplier).Build(),
+			})
+		}
+	}
+
+	results := slices.DeleteFunc(Compare(recovery, numOutages, seed, candidates...), func(result Result) bool {
+		return result.SuccessRate < constraints.MinSuccessRate ||
+			(constraints.MaxExtraLoad > 0 && result.ExtraLoad() > constraints.MaxExtraLoad)
+	})
+	if len(results) == 0 {
+		return Result{}, ErrNoCandidate
+	}
+	return slices.MinFunc(results, func(a, b Result) int {
+		return cmp.Or(cmp.Compare(a.MeanTimeToSuccess, b.MeanTimeToSuccess), cmp.Compare(a.MeanAttempts, b.MeanAttempts))
+	}), nil
+}
+
+// simulateOutages runs the policy of the given candidate in virtual time against outages with the given recovery
+// times.
+func simulateOutages(candidate Candidate, recoveries []time.Duration) Result {
+	result := Result{Candidate: candidate}
+	if len(recoveries) == 0 {
+		return result
+	}
+	var numRecovered, numAttempts int
+	var timeToSuccess time.Duration
+	for _, recovery := range recoveries {
+		clock := &virtualClock{now: time.Unix(0, 0)}
+		start := clock.Now()
+		err := candidate.Policy.With(retry.WithClock(clock)).RetryCtx(context.Background(), func() error {
+			numAttempts++
+			if clock.Now().Sub(start) < recovery {
+				return errUnavailable
+			}
+			return nil
+		})
+		if err == nil {
+			numRecovered++
+			timeToSuccess += clock.Now().Sub(start)
+		}
+	}
+	result.SuccessRate = float64(numRecovered) / float64(len(recoveries))
+	result.MeanAttempts = float64(numAttempts) / float64(len(recoveries))
+	if numRecovered > 0 {
+		result.MeanTimeToSuccess = timeToSuccess / time.Duration(numRecovered)
+	}
+	return result
+}
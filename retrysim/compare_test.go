@@ -0,0 +1,90 @@
+package retrysim
+
+import (
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/minitauros/go-retry"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDistributions(t *testing.T) {
+	Convey("Distributions", t, func() {
+		r := rand.New(rand.NewPCG(1, 1))
+
+		Convey("FixedRecovery() always returns the given recovery time", func() {
+			So(FixedRecovery(time.Second)(r), ShouldEqual, time.Second)
+		})
+
+		Convey("UniformRecovery() returns recovery times between the minimum and maximum", func() {
+			for i := 0; i < 100; i++ {
+				So(UniformRecovery(time.Second, 2*time.Second)(r), ShouldBeBetweenOrEqual, time.Second, 2*time.Second)
+			}
+		})
+
+		Convey("ExponentialRecovery() returns recovery times with the given mean", func() {
+			var total time.Duration
+			for i := 0; i < 10000; i++ {
+				total += ExponentialRecovery(time.Second)(r)
+			}
+			So(total/10000, ShouldBeBetween, 900*time.Millisecond, 1100*time.Millisecond)
+		})
+	})
+}
+
+func TestCompare(t *testing.T) {
+	Convey("Compare()", t, func() {
+		fast := Candidate{Name: "fast", Policy: retry.NewPolicy().MaxAttempts(5).InitialDelay(time.Second).Multiplier(1).Build()}
+		slow := Candidate{Name: "slow", Policy: retry.NewPolicy().MaxAttempts(3).InitialDelay(10 * time.Second).Multiplier(1).Build()}
+
+		Convey("Returns how every policy performed", func() {
+			results := Compare(FixedRecovery(2500*time.Millisecond), 10, 1, fast, slow)
+			So(results, ShouldHaveLength, 2)
+
+			So(results[0].Name, ShouldEqual, "fast")
+			So(results[0].SuccessRate, ShouldEqual, 1)
+			So(results[0].MeanTimeToSuccess, ShouldEqual, 3*time.Second)
+			So(results[0].MeanAttempts, ShouldEqual, 4)
+			So(results[0].ExtraLoad(), ShouldEqual, 3)
+
+			So(results[1].Name, ShouldEqual, "slow")
+			So(results[1].SuccessRate, ShouldEqual, 1)
+			So(results[1].MeanTimeToSuccess, ShouldEqual, 10*time.Second)
+			So(results[1].MeanAttempts, ShouldEqual, 2)
+		})
+
+		Convey("Counts the outages that the policy gave up on", func() {
+			results := Compare(UniformRecovery(0, 10*time.Second), 1000, 1, fast)
+			So(results[0].SuccessRate, ShouldBeBetween, 0.35, 0.45) // Gives up after 4s.
+			So(results[0].MeanTimeToSuccess, ShouldBeLessThanOrEqualTo, 4*time.Second)
+		})
+
+		Convey("Is reproducible using the seed", func() {
+			So(Compare(ExponentialRecovery(time.Second), 100, 1, fast), ShouldResemble, Compare(ExponentialRecovery(time.Second), 100, 1, fast))
+		})
+	})
+}
+
+func TestRecommend(t *testing.T) {
+	Convey("Recommend()", t, func() {
+		Convey("Recommends the fastest policy within the constraints", func() {
+			result, err := Recommend(FixedRecovery(time.Second), Constraints{MinSuccessRate: 1}, 10, 1)
+			So(err, ShouldBeNil)
+			So(result.SuccessRate, ShouldEqual, 1)
+			So(result.MeanTimeToSuccess, ShouldEqual, time.Second)
+			So(result.Policy.InitialDelay(), ShouldEqual, time.Second) // The fewest attempts to get there.
+		})
+
+		Convey("Respects the maximum extra load", func() {
+			result, err := Recommend(FixedRecovery(time.Second), Constraints{MinSuccessRate: 1, MaxExtraLoad: 2}, 10, 1)
+			So(err, ShouldBeNil)
+			So(result.ExtraLoad(), ShouldBeLessThanOrEqualTo, 2)
+		})
+
+		Convey("Returns ErrNoCandidate if no policy meets the constraints", func() {
+			_, err := Recommend(FixedRecovery(time.Hour), Constraints{MaxAttempts: 2, MinSuccessRate: 1}, 10, 1)
+			So(err, ShouldEqual, ErrNoCandidate)
+		})
+	})
+}
@@ -0,0 +1,172 @@
+// Package retrysim runs retry policies against scripted attempt outcomes in virtual time, to find out what a policy
+// would have done in a given situation, like a past outage, without waiting for it.
+package retrysim
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minitauros/go-retry"
+)
+
+// Outcome is the scripted outcome of an attempt.
+type Outcome struct {
+	// Err is the error the attempt returns. nil makes the attempt succeed.
+	Err error
+	// Latency is how long the attempt takes.
+	Latency time.Duration
+}
+
+// EventKind is the kind of an event in a timeline.
+type EventKind int
+
+const (
+	// EventAttempt is an attempt.
+	EventAttempt EventKind = iota
+	// EventSleep is a sleep between attempts.
+	EventSleep
+)
+
+// String implements fmt.Stringer.
+func (k EventKind) String() string {
+	switch k {
+	case EventAttempt:
+		return "attempt"
+	case EventSleep:
+		return "sleep"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is something that happened during a simulation.
+type Event struct {
+	// Kind is the kind of event.
+	Kind EventKind
+	// At is the virtual time at which the event started, relative to the start of the simulation.
+	At time.Duration
+	// Duration is how long the event took.
+	Duration time.Duration
+	// Attempt is the number of the attempt, counting from 1, or of the attempt before the sleep.
+	Attempt int
+	// Err is the error returned by the attempt. It is always nil for sleeps.
+	Err error
+}
+
+// Timeline is the complete timeline of a simulation.
+type Timeline struct {
+	// Events contains the attempts and sleeps, in order.
+	Events []Event
+	// Err is the error returned by the policy.
+	Err error
+	// Elapsed is the virtual time the simulation took.
+	Elapsed time.Duration
+}
+
+// NumAttempts returns the number of attempts that were made.
+func (t *Timeline) NumAttempts() int {
+	var n int
+	for _, event := range t.Events {
+		if event.Kind == EventAttempt {
+			n++
+		}
+	}
+	return n
+}
+
+// String returns the timeline as a table, one event per line.
+func (t *Timeline) String() string {
+	var sb strings.Builder
+	for _, event := range t.Events {
+		fmt.Fprintf(&sb, "%12s  %-7s  #%-3d  %12s", event.At, event.Kind, event.Attempt, event.Duration)
+		if event.Err != nil {
+			fmt.Fprintf(&sb, "  %s", event.Err)
+		}
+		sb.WriteString("\n")
+	}
+	fmt.Fprintf(&sb, "%12s  done     after %d attempts", t.Elapsed, t.NumAttempts())
+	if t.Err != nil {
+		fmt.Fprintf(&sb, ": %s", t.Err)
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// Simulate runs the given policy in virtual time against the given script, which contains the outcomes of the
+// attempts in order. Once the script is exhausted, attempts succeed without latency.
+// Sleeps take no real time, so long outages can be simulated in an instant. Apart from the clock, the policy runs as
+// usual, so options like WithPacer that wait in real time still do.
+func Simulate(policy retry.Policy, script []Outcome) *Timeline {
+	clock := &virtualClock{now: time.Unix(0, 0)}
+	start := clock.Now()
+	timeline := &Timeline{}
+	clock.onSleep = func(at time.Time, d time.Duration) {
+		timeline.Events = append(timeline.Events, Event{
+			Kind:     EventSleep,
+			At:       at.Sub(start),
+			Duration: d,
+			Attempt:  timeline.NumAttempts(),
+		})
+	}
+
+	var attempt int
+	timeline.Err = policy.With(retry.WithClock(clock)).RetryCtx(context.Background(), func() error {
+		var outcome Outcome
+		if attempt < len(script) {
+			outcome = script[attempt]
+		}
+		attempt++
+		at := clock.Now()
+		clock.advance(outcome.Latency)
+		timeline.Events = append(timeline.Events, Event{
+			Kind:     EventAttempt,
+			At:       at.Sub(start),
+			Duration: outcome.Latency,
+			Attempt:  attempt,
+			Err:      outcome.Err,
+		})
+		return outcome.Err
+	})
+	timeline.Elapsed = clock.Now().Sub(start)
+	return timeline
+}
+
+// virtualClock is a clock of which the time only moves when it sleeps or is advanced. If onSleep is set, it is called
+// before every sleep.
+type virtualClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	onSleep func(at time.Time, d time.Duration)
+}
+
+// Now implements retry.Clock.
+func (c *virtualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Sleep implements retry.Clock.
+func (c *virtualClock) Sleep(ctx context.Context, d time.Duration) error {
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+	if d <= 0 {
+		return nil
+	}
+	if c.onSleep != nil {
+		c.onSleep(c.Now(), d)
+	}
+	c.advance(d)
+	return nil
+}
+
+// advance moves the time of the clock forward by the given duration.
+func (c *virtualClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
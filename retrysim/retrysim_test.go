@@ -0,0 +1,81 @@
+package retrysim
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/minitauros/go-retry"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSimulate(t *testing.T) {
+	Convey("Simulate()", t, func() {
+		errUnavailable := errors.New("unavailable")
+		policy := retry.NewPolicy().MaxAttempts(4).InitialDelay(time.Second).Multiplier(2).Build()
+
+		Convey("Outputs the complete timeline in virtual time", func() {
+			start := time.Now()
+			timeline := Simulate(policy, []Outcome{
+				{Err: errUnavailable, Latency: 100 * time.Millisecond},
+				{Err: errUnavailable, Latency: 200 * time.Millisecond},
+				{Latency: 50 * time.Millisecond},
+			})
+			So(time.Since(start), ShouldBeLessThan, 100*time.Millisecond)
+
+			So(timeline.Err, ShouldBeNil)
+			So(timeline.NumAttempts(), ShouldEqual, 3)
+			So(timeline.Elapsed, ShouldEqual, 3350*time.Millisecond)
+			So(timeline.Events, ShouldResemble, []Event{
+				{Kind: EventAttempt, At: 0, Duration: 100 * time.Millisecond, Attempt: 1, Err: errUnavailable},
+				{Kind: EventSleep, At: 100 * time.Millisecond, Duration: time.Second, Attempt: 1},
+				{Kind: EventAttempt, At: 1100 * time.Millisecond, Duration: 200 * time.Millisecond, Attempt: 2, Err: errUnavailable},
+				{Kind: EventSleep, At: 1300 * time.Millisecond, Duration: 2 * time.Second, Attempt: 2},
+				{Kind: EventAttempt, At: 3300 * time.Millisecond, Duration: 50 * time.Millisecond, Attempt: 3},
+			})
+		})
+
+		Convey("Shows when the policy gives up", func() {
+			outage := make([]Outcome, 10)
+			for i := range outage {
+				outage[i] = Outcome{Err: errUnavailable, Latency: time.Second}
+			}
+			timeline := Simulate(policy, outage)
+			So(timeline.Err, ShouldWrap, retry.ErrMaxRetriesExceeded)
+			So(timeline.Err, ShouldWrap, errUnavailable)
+			So(timeline.NumAttempts(), ShouldEqual, 4)
+			// 4 attempts of 1s, and sleeps of 1s, 2s and 4s.
+			So(timeline.Elapsed, ShouldEqual, 11*time.Second)
+
+			var retryErr *retry.Error
+			So(errors.As(timeline.Err, &retryErr), ShouldBeTrue)
+			So(retryErr.TotalElapsed, ShouldEqual, 11*time.Second)
+			So(retryErr.TotalSlept, ShouldEqual, 7*time.Second)
+		})
+
+		Convey("Honors options that depend on time", func() {
+			timeline := Simulate(policy.With(retry.WithMaxElapsedTime(5*time.Second)), []Outcome{
+				{Err: errUnavailable, Latency: time.Second},
+				{Err: errUnavailable, Latency: time.Second},
+				{Err: errUnavailable, Latency: time.Second},
+			})
+			So(timeline.Err, ShouldWrap, retry.ErrMaxElapsedTimeExceeded)
+			So(timeline.NumAttempts(), ShouldEqual, 2)
+		})
+
+		Convey("Once the script is exhausted, attempts succeed", func() {
+			timeline := Simulate(policy, nil)
+			So(timeline.Err, ShouldBeNil)
+			So(timeline.NumAttempts(), ShouldEqual, 1)
+		})
+
+		Convey("String() returns the timeline as a table", func() {
+			timeline := Simulate(policy, []Outcome{{Err: errUnavailable, Latency: time.Second}})
+			So(timeline.String(), ShouldEqual, ""+
+				"          0s  attempt  #1              1s  unavailable\n"+
+				"          1s  sleep    #1              1s\n"+
+				"          2s  attempt  #2              0s\n"+
+				"          2s  done     after 2 attempts\n")
+		})
+	})
+}
@@ -0,0 +1,168 @@
+package retrytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minitauros/go-retry"
+)
+
+// numConformanceRetries is the number of retries for which TestStrategy checks the delays of a strategy.
+const numConformanceRetries = 1000
+
+// Bounds are the bounds every delay of a backoff strategy must be within.
+type Bounds struct {
+	// Min is the minimum delay.
+	Min time.Duration
+	// Max is the maximum delay, at which the strategy must plateau. 0 means no maximum.
+	Max time.Duration
+}
+
+// TestStrategy checks that the given backoff strategy conforms to the invariants of the retry package:
+//   - Delays are never negative, even after many retries, which catches overflows.
+//   - Delays are within the given bounds. For strategies with jitter, these are the bounds of the jitter.
+//   - The strategy is safe for concurrent use. Run the tests with -race to check this.
+func TestStrategy(t *testing.T, strategy retry.BackoffStrategy, bounds Bounds) {
+	t.Helper()
+	for _, problem := range checkStrategy(strategy, bounds) {
+		t.Error(problem)
+	}
+}
+
+// checkStrategy returns the problems found while checking the given strategy.
+func checkStrategy(strategy retry.BackoffStrategy, bounds Bounds) []string {
+	var problems []string
+	var prev time.Duration
+	for i := 1; i <= numConformanceRetries; i++ {
+		delay := strategy.Delay(i, prev)
+		switch {
+		case delay < 0:
+			problems = append(problems, fmt.Sprintf("delay before retry %d is negative: %s", i, delay))
+		case delay < bounds.Min:
+			problems = append(problems, fmt.Sprintf("delay before retry %d is less than the minimum of %s: %s", i, bounds.Min, delay))
+		case bounds.Max > 0 && delay > bounds.Max:
+			problems = append(problems, fmt.Sprintf("delay before retry %d exceeds the maximum of %s: %s", i, bounds.Max, delay))
+		}
+		if len(problems) > 0 {
+			// One problem usually causes many more. Only report the first.
+			return problems
+		}
+		prev = delay
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var prev time.Duration
+			for retry := 1; retry <= 100; retry++ {
+				prev = strategy.Delay(retry, prev)
+			}
+		}()
+	}
+	wg.Wait()
+	return problems
+}
+
+// TestRetryer checks that the given retryer conforms to the behavior of the retriers of the retry package:
+//   - Retry and RetryCtx stop as soon as `nil` is returned.
+//   - RetryWithStop and RetryWithStopCtx stop only when stop is called.
+//   - All methods make at max numTimes + 1 attempts, and then return an error matching both
+//     retry.ErrMaxRetriesExceeded and the error of the last attempt.
+//   - The ctx methods return the context error without making an attempt if the context is done.
+//
+// The retryer should be configured with short delays, as the checks make several retries.
+func TestRetryer(t *testing.T, retryer retry.Retryer) {
+	t.Helper()
+	for _, problem := range checkRetryer(retryer) {
+		t.Error(problem)
+	}
+}
+
+// checkRetryer returns the problems found while checking the given retryer.
+func checkRetryer(retryer retry.Retryer) []string {
+	var problems []string
+	check := func(method string, numCalled, expectedNumCalled int, err error, expectedErrs ...error) {
+		if numCalled != expectedNumCalled {
+			problems = append(problems, fmt.Sprintf("%s made %d attempts, expected %d", method, numCalled, expectedNumCalled))
+		}
+		if len(expectedErrs) == 0 && err != nil {
+			problems = append(problems, fmt.Sprintf("%s returned error %q, expected nil", method, err))
+		}
+		for _, expectedErr := range expectedErrs {
+			if !errors.Is(err, expectedErr) {
+				problems = append(problems, fmt.Sprintf("%s returned error %v, expected it to match %q", method, err, expectedErr))
+			}
+		}
+	}
+
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for name, retryFunc := range map[string]func(ctx context.Context, numTimes int, cb func() error) error{
+		"Retry": func(_ context.Context, numTimes int, cb func() error) error {
+			return retryer.Retry(numTimes, cb)
+		},
+		"RetryCtx": retryer.RetryCtx,
+	} {
+		var numCalled int
+		failUntil3 := FailUntilAttempt(3)
+		err := retryFunc(context.Background(), 5, func() error {
+			numCalled++
+			return failUntil3()
+		})
+		check(name, numCalled, 3, err)
+
+		numCalled = 0
+		err = retryFunc(context.Background(), 2, func() error {
+			numCalled++
+			return ErrInjected
+		})
+		check(name, numCalled, 3, err, retry.ErrMaxRetriesExceeded, ErrInjected)
+	}
+
+	for name, retryFunc := range map[string]func(ctx context.Context, numTimes int, cb func(stop func()) error) error{
+		"RetryWithStop": func(_ context.Context, numTimes int, cb func(stop func()) error) error {
+			return retryer.RetryWithStop(numTimes, cb)
+		},
+		"RetryWithStopCtx": retryer.RetryWithStopCtx,
+	} {
+		var numCalled int
+		err := retryFunc(context.Background(), 5, func(stop func()) error {
+			numCalled++
+			if numCalled == 3 {
+				stop()
+			}
+			return nil
+		})
+		check(name, numCalled, 3, err)
+
+		numCalled = 0
+		err = retryFunc(context.Background(), 2, func(stop func()) error {
+			numCalled++
+			return ErrInjected
+		})
+		check(name, numCalled, 3, err, retry.ErrMaxRetriesExceeded, ErrInjected)
+	}
+
+	var numCalled int
+	err := retryer.RetryCtx(cancelledCtx, 5, func() error {
+		numCalled++
+		return nil
+	})
+	check("RetryCtx with a cancelled context", numCalled, 0, err, context.Canceled)
+
+	numCalled = 0
+	err = retryer.RetryWithStopCtx(cancelledCtx, 5, func(stop func()) error {
+		numCalled++
+		return nil
+	})
+	check("RetryWithStopCtx with a cancelled context", numCalled, 0, err, context.Canceled)
+
+	return problems
+}
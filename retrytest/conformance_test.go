@@ -0,0 +1,87 @@
+package retrytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/minitauros/go-retry"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTestStrategy(t *testing.T) {
+	TestStrategy(t, retry.ConstantBackoff(time.Second), Bounds{Min: time.Second, Max: time.Second})
+	TestStrategy(t, retry.NoBackoff, Bounds{})
+	TestStrategy(t, retry.CompositeBackoff(
+		retry.Phase{Retries: 3, Strategy: retry.NoBackoff},
+		retry.Phase{Retries: 5, Strategy: retry.ExponentialBackoff(time.Second, 2)},
+		retry.Phase{Strategy: retry.ConstantBackoff(30 * time.Second)},
+	), Bounds{Max: 32 * time.Second})
+	TestStrategy(t, retry.PolynomialBackoff(time.Second, 3), Bounds{Min: time.Second})
+	TestStrategy(t, retry.DecorrelatedJitter(time.Second, time.Minute), Bounds{Min: time.Second, Max: time.Minute})
+	TestStrategy(t, retry.BackoffFunc(func(_ int, prev time.Duration) time.Duration {
+		return min(2*prev+time.Second, time.Minute)
+	}), Bounds{Max: time.Minute})
+}
+
+func TestCheckStrategy(t *testing.T) {
+	Convey("checkStrategy()", t, func() {
+		Convey("Finds no problems for a conforming strategy", func() {
+			So(checkStrategy(retry.ConstantBackoff(time.Second), Bounds{Min: time.Second, Max: time.Second}), ShouldBeEmpty)
+		})
+
+		Convey("Reports negative delays, such as those caused by an overflow", func() {
+			doubling := retry.BackoffFunc(func(_ int, prev time.Duration) time.Duration {
+				if prev == 0 {
+					return time.Second
+				}
+				return prev * 2
+			})
+			problems := checkStrategy(doubling, Bounds{})
+			So(problems, ShouldHaveLength, 1)
+			So(problems[0], ShouldContainSubstring, "negative")
+		})
+
+		Convey("Reports delays outside the bounds", func() {
+			problems := checkStrategy(retry.ConstantBackoff(time.Second), Bounds{Max: time.Millisecond})
+			So(problems, ShouldHaveLength, 1)
+			So(problems[0], ShouldContainSubstring, "exceeds the maximum")
+
+			problems = checkStrategy(retry.ConstantBackoff(time.Millisecond), Bounds{Min: time.Second})
+			So(problems, ShouldHaveLength, 1)
+			So(problems[0], ShouldContainSubstring, "less than the minimum")
+		})
+	})
+}
+
+func TestTestRetryer(t *testing.T) {
+	TestRetryer(t, retry.NewNoDelayRetrier())
+	TestRetryer(t, retry.NewBackOffRetrier(time.Microsecond, 2))
+}
+
+// stopIgnoringRetryer is a retryer that ignores the context and keeps retrying after `nil` is returned.
+type stopIgnoringRetryer struct {
+	retry.Retryer
+}
+
+func (r stopIgnoringRetryer) RetryCtx(_ context.Context, numTimes int, cb func() error) error {
+	return r.Retryer.RetryWithStop(numTimes, func(func()) error {
+		return cb()
+	})
+}
+
+func TestCheckRetryer(t *testing.T) {
+	Convey("checkRetryer()", t, func() {
+		Convey("Finds no problems for a conforming retryer", func() {
+			So(checkRetryer(retry.NewNoDelayRetrier()), ShouldBeEmpty)
+		})
+
+		Convey("Reports deviating behavior", func() {
+			problems := checkRetryer(stopIgnoringRetryer{retry.NewNoDelayRetrier()})
+			So(problems, ShouldNotBeEmpty)
+			for _, problem := range problems {
+				So(problem, ShouldStartWith, "RetryCtx")
+			}
+		})
+	})
+}
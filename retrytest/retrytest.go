@@ -0,0 +1,48 @@
+// Package retrytest provides helpers for testing code that uses the retry package.
+package retrytest
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrInjected is the error returned by the callbacks of this package when an attempt is scripted to fail.
+var ErrInjected = errors.New("retrytest: injected failure")
+
+// FailUntilAttempt returns a callback that fails until the given attempt is reached.
+// Attempts are counted from 1, so FailUntilAttempt(3) fails twice and then keeps succeeding.
+func FailUntilAttempt(n int) func() error {
+	var attempt atomic.Int64
+	return func() error {
+		if attempt.Add(1) < int64(n) {
+			return ErrInjected
+		}
+		return nil
+	}
+}
+
+// FailWith returns a callback that returns the given errors in sequence, one per attempt.
+// A `nil` in the sequence makes that attempt succeed. Once the sequence is exhausted, the callback keeps returning
+// `nil`.
+func FailWith(sequence ...error) func() error {
+	var attempt atomic.Int64
+	return func() error {
+		i := attempt.Add(1) - 1
+		if i < int64(len(sequence)) {
+			return sequence[i]
+		}
+		return nil
+	}
+}
+
+// SucceedEvery returns a callback that succeeds on every kth attempt and fails on all others.
+// SucceedEvery(3) fails on attempts 1 and 2, succeeds on attempt 3, fails on 4 and 5, etc.
+func SucceedEvery(k int) func() error {
+	var attempt atomic.Int64
+	return func() error {
+		if k > 0 && attempt.Add(1)%int64(k) == 0 {
+			return nil
+		}
+		return ErrInjected
+	}
+}
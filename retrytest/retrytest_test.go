@@ -0,0 +1,62 @@
+package retrytest
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFailUntilAttempt(t *testing.T) {
+	Convey("FailUntilAttempt()", t, func() {
+		Convey("Fails until the given attempt, then succeeds", func() {
+			cb := FailUntilAttempt(3)
+			So(cb(), ShouldEqual, ErrInjected)
+			So(cb(), ShouldEqual, ErrInjected)
+			So(cb(), ShouldBeNil)
+			So(cb(), ShouldBeNil)
+		})
+
+		Convey("If the attempt is 1 or lower, succeeds right away", func() {
+			So(FailUntilAttempt(1)(), ShouldBeNil)
+			So(FailUntilAttempt(0)(), ShouldBeNil)
+		})
+	})
+}
+
+func TestFailWith(t *testing.T) {
+	Convey("FailWith()", t, func() {
+		Convey("Returns the errors in sequence, then nil", func() {
+			errFoo := errors.New("foo")
+			errBar := errors.New("bar")
+			cb := FailWith(errFoo, nil, errBar)
+			So(cb(), ShouldEqual, errFoo)
+			So(cb(), ShouldBeNil)
+			So(cb(), ShouldEqual, errBar)
+			So(cb(), ShouldBeNil)
+			So(cb(), ShouldBeNil)
+		})
+
+		Convey("If no errors are given, always succeeds", func() {
+			So(FailWith()(), ShouldBeNil)
+		})
+	})
+}
+
+func TestSucceedEvery(t *testing.T) {
+	Convey("SucceedEvery()", t, func() {
+		Convey("Only succeeds on every kth attempt", func() {
+			cb := SucceedEvery(2)
+			So(cb(), ShouldEqual, ErrInjected)
+			So(cb(), ShouldBeNil)
+			So(cb(), ShouldEqual, ErrInjected)
+			So(cb(), ShouldBeNil)
+		})
+
+		Convey("If k is 0, never succeeds", func() {
+			cb := SucceedEvery(0)
+			So(cb(), ShouldEqual, ErrInjected)
+			So(cb(), ShouldEqual, ErrInjected)
+		})
+	})
+}
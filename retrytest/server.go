@@ -0,0 +1,163 @@
+package retrytest
+
+import (
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ServerOption configures a FlakyServer.
+type ServerOption func(*FlakyServer)
+
+// WithFailures makes the first n requests fail.
+func WithFailures(n int) ServerOption {
+	return func(s *FlakyServer) {
+		s.numFailures = n
+	}
+}
+
+// WithOutage makes all requests fail until the given recovery time has passed since the first request.
+func WithOutage(recovery time.Duration) ServerOption {
+	return func(s *FlakyServer) {
+		s.recovery = recovery
+	}
+}
+
+// WithErrorRate makes the given fraction of requests fail at random, from 0 to 1.
+func WithErrorRate(rate float64) ServerOption {
+	return func(s *FlakyServer) {
+		s.errorRate = rate
+	}
+}
+
+// WithSeed makes the random failures of WithErrorRate reproducible, by seeding them with the given seed.
+func WithSeed(seed uint64) ServerOption {
+	return func(s *FlakyServer) {
+		s.rand = rand.New(rand.NewPCG(seed, seed))
+	}
+}
+
+// WithLatency makes the server wait for the given latency before responding to every request.
+func WithLatency(latency time.Duration) ServerOption {
+	return func(s *FlakyServer) {
+		s.latency = latency
+	}
+}
+
+// WithStatusCode makes failed requests respond with the given status code. Defaults to 503 Service Unavailable.
+func WithStatusCode(code int) ServerOption {
+	return func(s *FlakyServer) {
+		s.statusCode = code
+	}
+}
+
+// WithRetryAfter makes failed requests respond with a Retry-After header asking to retry after the given delay,
+// rounded up to whole seconds.
+func WithRetryAfter(delay time.Duration) ServerOption {
+	return func(s *FlakyServer) {
+		s.retryAfter = delay
+	}
+}
+
+// WithHandler makes the given handler handle the requests that don't fail. Defaults to responding 200 OK.
+func WithHandler(h http.Handler) ServerOption {
+	return func(s *FlakyServer) {
+		s.next = h
+	}
+}
+
+// FlakyServer is an HTTP test server that simulates a flaky dependency, failing requests according to a script of
+// failures, outages, error rates and latency, so that retry configurations can be tested end-to-end against realistic
+// failure patterns. Requests fail if any of the options makes them fail.
+type FlakyServer struct {
+	*httptest.Server
+
+	numFailures int
+	recovery    time.Duration
+	errorRate   float64
+	latency     time.Duration
+	statusCode  int
+	retryAfter  time.Duration
+	next        http.Handler
+
+	mu          sync.Mutex
+	rand        *rand.Rand
+	start       time.Time // When the first request was received.
+	numRequests int
+	numFailed   int
+}
+
+// NewFlakyServer starts and returns a new flaky server. Close it when done.
+func NewFlakyServer(opts ...ServerOption) *FlakyServer {
+	s := &FlakyServer{
+		statusCode: http.StatusServiceUnavailable,
+		next: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+		rand: rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Requests returns the number of requests received.
+func (s *FlakyServer) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.numRequests
+}
+
+// Failures returns the number of requests that failed.
+func (s *FlakyServer) Failures() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.numFailed
+}
+
+// serveHTTP responds to the given request, failing it if the script says so.
+func (s *FlakyServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	fail := s.record()
+	if s.latency > 0 {
+		timer := time.NewTimer(s.latency)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if !fail {
+		s.next.ServeHTTP(w, r)
+		return
+	}
+	if s.retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(s.retryAfter.Seconds()))))
+	}
+	w.WriteHeader(s.statusCode)
+}
+
+// record records a request, and reports whether it should fail.
+func (s *FlakyServer) record() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.numRequests == 0 {
+		s.start = now
+	}
+	s.numRequests++
+	fail := s.numRequests <= s.numFailures ||
+		now.Sub(s.start) < s.recovery ||
+		(s.errorRate > 0 && s.rand.Float64() < s.errorRate)
+	if fail {
+		s.numFailed++
+	}
+	return fail
+}
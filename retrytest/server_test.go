@@ -0,0 +1,93 @@
+package retrytest
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/minitauros/go-retry"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// get sends a GET request to the given server, and returns an error if it doesn't respond 200 OK.
+func get(s *FlakyServer) (*http.Response, error) {
+	resp, err := http.Get(s.URL)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return resp, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+func TestFlakyServer(t *testing.T) {
+	Convey("FlakyServer", t, func() {
+		Convey("Succeeds by default", func() {
+			s := NewFlakyServer()
+			defer s.Close()
+			_, err := get(s)
+			So(err, ShouldBeNil)
+			So(s.Requests(), ShouldEqual, 1)
+			So(s.Failures(), ShouldEqual, 0)
+		})
+
+		Convey("WithFailures() fails the first requests", func() {
+			s := NewFlakyServer(WithFailures(2), WithStatusCode(http.StatusBadGateway), WithRetryAfter(1500*time.Millisecond))
+			defer s.Close()
+			resp, err := get(s)
+			So(err, ShouldNotBeNil)
+			So(resp.StatusCode, ShouldEqual, http.StatusBadGateway)
+			So(resp.Header.Get("Retry-After"), ShouldEqual, "2")
+
+			err = retry.Retry(5, func() error {
+				_, err := get(s)
+				return err
+			})
+			So(err, ShouldBeNil)
+			So(s.Requests(), ShouldEqual, 3)
+			So(s.Failures(), ShouldEqual, 2)
+		})
+
+		Convey("WithOutage() fails all requests until the server recovers", func() {
+			s := NewFlakyServer(WithOutage(20 * time.Millisecond))
+			defer s.Close()
+			err := retry.NewConstantDelayRetrier(5*time.Millisecond).Retry(20, func() error {
+				_, err := get(s)
+				return err
+			})
+			So(err, ShouldBeNil)
+			So(s.Failures(), ShouldBeGreaterThan, 1)
+			So(s.Requests(), ShouldEqual, s.Failures()+1)
+		})
+
+		Convey("WithErrorRate() fails the given fraction of requests", func() {
+			s := NewFlakyServer(WithErrorRate(0.5), WithSeed(1))
+			defer s.Close()
+			for i := 0; i < 200; i++ {
+				_, _ = get(s)
+			}
+			So(s.Failures(), ShouldBeBetween, 60, 140)
+		})
+
+		Convey("WithLatency() delays every response", func() {
+			s := NewFlakyServer(WithLatency(10 * time.Millisecond))
+			defer s.Close()
+			start := time.Now()
+			_, err := get(s)
+			So(err, ShouldBeNil)
+			So(time.Since(start), ShouldBeGreaterThanOrEqualTo, 10*time.Millisecond)
+		})
+
+		Convey("WithHandler() handles the requests that don't fail", func() {
+			s := NewFlakyServer(WithHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+			})))
+			defer s.Close()
+			resp, _ := get(s)
+			So(resp.StatusCode, ShouldEqual, http.StatusTeapot)
+		})
+	})
+}
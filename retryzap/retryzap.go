@@ -0,0 +1,68 @@
+// Package retryzap provides a retry.LogHook that logs the events of retry loops using zap.
+package retryzap
+
+import (
+	"github.com/minitauros/go-retry"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Levels are the levels at which the events of retry loops are logged.
+type Levels struct {
+	// Retry is the level of failed attempts that are retried.
+	Retry zapcore.Level
+	// Success is the level of retry loops ending without an error.
+	Success zapcore.Level
+	// Error is the level of retry loops ending with an error.
+	Error zapcore.Level
+}
+
+// DefaultLevels are the default levels, matching retry.DefaultLogLevels.
+var DefaultLevels = Levels{
+	Retry:   zapcore.WarnLevel,
+	Success: zapcore.DebugLevel,
+	Error:   zapcore.ErrorLevel,
+}
+
+// Hook is a retry.LogHook that logs using a *zap.Logger, with structured fields for the operation, attempt, delay and
+// error.
+type Hook struct {
+	logger *zap.Logger
+	levels Levels
+}
+
+var _ retry.LogHook = (*Hook)(nil)
+
+// NewHook returns a new log hook that logs using the given logger at the given levels. Pass it to retry.WithLogHook.
+func NewHook(logger *zap.Logger, levels Levels) *Hook {
+	return &Hook{logger: logger, levels: levels}
+}
+
+// LogRetry implements retry.LogHook.
+func (h *Hook) LogRetry(event retry.RetryEvent) {
+	h.logger.Log(h.levels.Retry, "attempt failed, retrying",
+		zap.String("operation", event.Operation),
+		zap.Int("attempt", event.Attempt),
+		zap.Duration("delay", event.NextDelay),
+		zap.Error(event.Err),
+	)
+}
+
+// LogSuccess implements retry.LogHook.
+func (h *Hook) LogSuccess(event retry.SuccessEvent) {
+	h.logger.Log(h.levels.Success, "succeeded",
+		zap.String("operation", event.Operation),
+		zap.Int("attempts", event.NumAttempts),
+		zap.Duration("elapsed", event.Elapsed),
+	)
+}
+
+// LogError implements retry.LogHook.
+func (h *Hook) LogError(event retry.ErrorEvent) {
+	h.logger.Log(h.levels.Error, "failed",
+		zap.String("operation", event.Operation),
+		zap.Int("attempts", event.NumAttempts),
+		zap.Duration("elapsed", event.Elapsed),
+		zap.Error(event.Err),
+	)
+}
@@ -0,0 +1,67 @@
+package retryzap
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/minitauros/go-retry"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestHook(t *testing.T) {
+	Convey("Hook", t, func() {
+		core, logs := observer.New(zapcore.DebugLevel)
+		hook := NewHook(zap.New(core), DefaultLevels)
+		expectedErr := errors.New("foo")
+
+		Convey("Logs retries and successes", func() {
+			var numCalled int
+			retrier := retry.NewNoDelayRetrier(retry.WithName("op"), retry.WithLogHook(hook))
+			err := retrier.Retry(3, func() error {
+				numCalled++
+				if numCalled < 2 {
+					return expectedErr
+				}
+				return nil
+			})
+			So(err, ShouldBeNil)
+
+			entries := logs.AllUntimed()
+			So(entries, ShouldHaveLength, 2)
+			So(entries[0].Level, ShouldEqual, zapcore.WarnLevel)
+			So(entries[0].Message, ShouldEqual, "attempt failed, retrying")
+			So(entries[0].ContextMap(), ShouldResemble, map[string]any{
+				"operation": "op",
+				"attempt":   int64(1),
+				"delay":     time.Duration(0),
+				"error":     "foo",
+			})
+			So(entries[1].Level, ShouldEqual, zapcore.DebugLevel)
+			So(entries[1].Message, ShouldEqual, "succeeded")
+			So(entries[1].ContextMap()["attempts"], ShouldEqual, 2)
+		})
+
+		Convey("Logs errors", func() {
+			retrier := retry.NewNoDelayRetrier(retry.WithLogHook(hook))
+			_ = retrier.Retry(0, func() error {
+				return expectedErr
+			})
+			entries := logs.AllUntimed()
+			So(entries, ShouldHaveLength, 1)
+			So(entries[0].Level, ShouldEqual, zapcore.ErrorLevel)
+			So(entries[0].ContextMap()["error"], ShouldEqual, "max retries exceeded: foo")
+		})
+
+		Convey("Logs at the given levels", func() {
+			hook := NewHook(zap.New(core), Levels{Success: zapcore.InfoLevel})
+			_ = retry.NewNoDelayRetrier(retry.WithLogHook(hook)).Retry(0, func() error {
+				return nil
+			})
+			So(logs.AllUntimed()[0].Level, ShouldEqual, zapcore.InfoLevel)
+		})
+	})
+}
@@ -0,0 +1,62 @@
+package retry
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// durationRing is a ring buffer of the most recently recorded durations. It is not safe for concurrent use.
+type durationRing struct {
+	durations []time.Duration
+	next      int  // Index in durations to write the next duration to.
+	full      bool // Whether durations has wrapped around at least once.
+}
+
+// newDurationRing returns a new ring buffer holding the given number of most recent durations, which is at least 1.
+func newDurationRing(size int) durationRing {
+	return durationRing{durations: make([]time.Duration, max(size, 1))}
+}
+
+// record records the given duration, replacing the oldest one if the ring is full.
+func (r *durationRing) record(d time.Duration) {
+	r.durations[r.next] = d
+	r.next++
+	if r.next == len(r.durations) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+// len returns the number of recorded durations.
+func (r *durationRing) len() int {
+	if r.full {
+		return len(r.durations)
+	}
+	return r.next
+}
+
+// mean returns the mean of the recorded durations, or 0 if none were recorded.
+func (r *durationRing) mean() time.Duration {
+	n := r.len()
+	if n == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, d := range r.durations[:n] {
+		total += d
+	}
+	return total / time.Duration(n)
+}
+
+// percentile returns the given percentile, between 0 and 1, of the recorded durations, or 0 if none were recorded.
+func (r *durationRing) percentile(p float64) time.Duration {
+	n := r.len()
+	if n == 0 {
+		return 0
+	}
+	sorted := slices.Clone(r.durations[:n])
+	slices.Sort(sorted)
+	i := int(math.Ceil(p*float64(n))) - 1
+	return sorted[min(max(i, 0), n-1)]
+}
@@ -0,0 +1,39 @@
+package retry
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDurationRing(t *testing.T) {
+	Convey("durationRing", t, func() {
+		ring := newDurationRing(3)
+
+		Convey("Returns 0 if nothing was recorded", func() {
+			So(ring.len(), ShouldEqual, 0)
+			So(ring.mean(), ShouldEqual, 0)
+			So(ring.percentile(0.5), ShouldEqual, 0)
+		})
+
+		Convey("Keeps only the most recent durations", func() {
+			for _, d := range []time.Duration{100, 1, 2, 3} {
+				ring.record(d)
+			}
+			So(ring.len(), ShouldEqual, 3)
+			So(ring.mean(), ShouldEqual, 2)
+			So(ring.percentile(0), ShouldEqual, 1)
+			So(ring.percentile(0.5), ShouldEqual, 2)
+			So(ring.percentile(1), ShouldEqual, 3)
+		})
+
+		Convey("Holds at least 1 duration", func() {
+			ring := newDurationRing(0)
+			ring.record(5)
+			ring.record(7)
+			So(ring.len(), ShouldEqual, 1)
+			So(ring.mean(), ShouldEqual, 7)
+		})
+	})
+}
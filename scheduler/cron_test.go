@@ -0,0 +1,67 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParse(t *testing.T) {
+	Convey("Parse()", t, func() {
+		from := time.Date(2024, time.January, 15, 10, 30, 0, 0, time.UTC) // Monday.
+
+		next := func(spec string) time.Time {
+			schedule, err := Parse(spec)
+			So(err, ShouldBeNil)
+			return schedule.Next(from)
+		}
+
+		Convey("Parses wildcards", func() {
+			So(next("* * * * *"), ShouldEqual, from.Add(time.Minute))
+		})
+
+		Convey("Parses numbers", func() {
+			So(next("45 * * * *"), ShouldEqual, time.Date(2024, time.January, 15, 10, 45, 0, 0, time.UTC))
+			So(next("15 * * * *"), ShouldEqual, time.Date(2024, time.January, 15, 11, 15, 0, 0, time.UTC))
+			So(next("0 9 * * *"), ShouldEqual, time.Date(2024, time.January, 16, 9, 0, 0, 0, time.UTC))
+		})
+
+		Convey("Parses steps, ranges and lists", func() {
+			So(next("*/20 * * * *"), ShouldEqual, time.Date(2024, time.January, 15, 10, 40, 0, 0, time.UTC))
+			So(next("0 1-3 * * *"), ShouldEqual, time.Date(2024, time.January, 16, 1, 0, 0, 0, time.UTC))
+			So(next("0 12,18 * * *"), ShouldEqual, time.Date(2024, time.January, 15, 12, 0, 0, 0, time.UTC))
+			So(next("0 0 1-31/10 * *"), ShouldEqual, time.Date(2024, time.January, 21, 0, 0, 0, 0, time.UTC))
+		})
+
+		Convey("Parses days of the week", func() {
+			So(next("0 0 * * 0"), ShouldEqual, time.Date(2024, time.January, 21, 0, 0, 0, 0, time.UTC))
+			So(next("0 0 * * 7"), ShouldEqual, time.Date(2024, time.January, 21, 0, 0, 0, 0, time.UTC))
+		})
+
+		Convey("If both the day of month and the day of week are restricted, matches either", func() {
+			So(next("0 0 1 * 3"), ShouldEqual, time.Date(2024, time.January, 17, 0, 0, 0, 0, time.UTC))
+			So(next("0 0 16 * 5"), ShouldEqual, time.Date(2024, time.January, 16, 0, 0, 0, 0, time.UTC))
+		})
+
+		Convey("Parses descriptors", func() {
+			So(next("@hourly"), ShouldEqual, time.Date(2024, time.January, 15, 11, 0, 0, 0, time.UTC))
+			So(next("@daily"), ShouldEqual, time.Date(2024, time.January, 16, 0, 0, 0, 0, time.UTC))
+			So(next("@weekly"), ShouldEqual, time.Date(2024, time.January, 21, 0, 0, 0, 0, time.UTC))
+			So(next("@monthly"), ShouldEqual, time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC))
+			So(next("@yearly"), ShouldEqual, time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC))
+			So(next("@every 90s"), ShouldEqual, from.Add(90*time.Second))
+		})
+
+		Convey("If no time matches, returns the zero time", func() {
+			So(next("0 0 30 2 *"), ShouldEqual, time.Time{})
+		})
+
+		Convey("Returns an error for invalid specs", func() {
+			for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "5-1 * * * *", "*/0 * * * *", "a * * * *", "@every foo", "@every -1s"} {
+				_, err := Parse(spec)
+				So(err, ShouldNotBeNil)
+			}
+		})
+	})
+}
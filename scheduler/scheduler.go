@@ -0,0 +1,349 @@
+// Package scheduler runs jobs on cron schedules, retrying every run according to a per-job retry policy.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/minitauros/go-retry"
+)
+
+// Overlap decides what happens when a job is due while its previous run is still running.
+type Overlap int
+
+const (
+	// OverlapSkip skips the run.
+	OverlapSkip Overlap = iota
+	// OverlapQueue starts the run as soon as the previous run finishes. At most one run is queued; runs that are due
+	// while a run is already queued are skipped.
+	OverlapQueue
+	// OverlapConcurrent starts the run right away, concurrently with the previous run.
+	OverlapConcurrent
+)
+
+// Timing decides when the next run of a job is due, relative to the previous one.
+type Timing int
+
+const (
+	// FixedRate makes the next run due on the schedule, relative to when the previous run was due, regardless of
+	// how long it took. Time spent retrying compresses the interval until the next run. Runs that are due while the
+	// previous run is still running are handled according to the overlap policy.
+	FixedRate Timing = iota
+	// FixedDelay makes the next run due on the schedule, relative to when the previous run finished, including its
+	// retries. Time spent retrying pushes the next run out, and runs never overlap.
+	FixedDelay
+)
+
+// Retrier retries a callback. It is implemented by the retriers of the retry package, like *retry.BackOffRetrier.
+type Retrier interface {
+	RetryCtx(ctx context.Context, numTimes int, cb func() error) error
+}
+
+// JobStats contains statistics of a job.
+type JobStats struct {
+	// Runs is the number of runs that were started.
+	Runs int
+	// Successes is the number of runs that succeeded.
+	Successes int
+	// Failures is the number of runs that failed, after retrying.
+	Failures int
+	// Skipped is the number of runs that were skipped because of the overlap policy.
+	Skipped int
+	// Attempts is the number of attempts of all runs together.
+	Attempts int
+	// LastRun is the time the last run started.
+	LastRun time.Time
+	// LastErr is the error of the last run that finished, `nil` if it succeeded.
+	LastErr error
+}
+
+// JobOption configures a job.
+type JobOption func(*job)
+
+// WithRetry makes every run of the job retry at max the given number of times using the given retrier.
+// By default, runs are not retried.
+func WithRetry(retrier Retrier, numTimes int) JobOption {
+	return func(j *job) {
+		j.retrier = retrier
+		j.numTimes = numTimes
+	}
+}
+
+// WithPolicy makes every run of the job retry according to the given policy, instead of a retrier set using WithRetry.
+// Every attempt receives a context carrying the attempt, which can be retrieved using retry.AttemptFromContext.
+func WithPolicy(policy retry.Policy) JobOption {
+	return func(j *job) {
+		j.policy = &policy
+	}
+}
+
+// WithStartJitter delays the schedule of the job by a random duration up to the given maximum when it starts, so that
+// the jobs of processes that start at the same time don't run in lockstep.
+func WithStartJitter(maxJitter time.Duration) JobOption {
+	return func(j *job) {
+		j.startJitter = maxJitter
+	}
+}
+
+// WithOverlap sets what happens when the job is due while its previous run is still running.
+// The default is OverlapSkip.
+func WithOverlap(overlap Overlap) JobOption {
+	return func(j *job) {
+		j.overlap = overlap
+	}
+}
+
+// WithTiming sets when the next run of the job is due, relative to the previous one. The default is FixedRate.
+func WithTiming(timing Timing) JobOption {
+	return func(j *job) {
+		j.timing = timing
+	}
+}
+
+// Scheduler runs jobs on their schedules.
+type Scheduler struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	jobs    map[string]*job
+	started bool
+}
+
+// New returns a new scheduler. It does not run any jobs until Start is called.
+func New() *Scheduler {
+	return newScheduler(context.Background())
+}
+
+// RunEvery runs the given function at the given interval until the given context is done, and then returns the cause
+// of the context. It's the short form of a scheduler running a single job, and takes the same options, e.g. to retry
+// every run according to a policy. The context passed to the function is derived from the given context.
+func RunEvery(ctx context.Context, interval time.Duration, fn func(ctx context.Context) error, opts ...JobOption) error {
+	if interval <= 0 {
+		return fmt.Errorf("scheduler: interval %s must be positive", interval)
+	}
+	s := newScheduler(ctx)
+	if err := s.AddSchedule("", Every(interval), fn, opts...); err != nil {
+		s.Stop()
+		return err
+	}
+	s.Start()
+	<-ctx.Done()
+	s.Stop()
+	return context.Cause(ctx)
+}
+
+// newScheduler returns a new scheduler, whose runs receive a context derived from the given context.
+func newScheduler(parent context.Context) *Scheduler {
+	ctx, cancel := context.WithCancel(parent)
+	return &Scheduler{
+		ctx:    ctx,
+		cancel: cancel,
+		jobs:   make(map[string]*job),
+	}
+}
+
+// Add adds a job with the given name that runs the given function on the given schedule spec, which is parsed using
+// Parse. The context passed to the function is cancelled when the scheduler is stopped.
+// Jobs can be added before and after the scheduler is started.
+func (s *Scheduler) Add(name, spec string, fn func(ctx context.Context) error, opts ...JobOption) error {
+	schedule, err := Parse(spec)
+	if err != nil {
+		return err
+	}
+	return s.AddSchedule(name, schedule, fn, opts...)
+}
+
+// AddSchedule adds a job with the given name that runs the given function on the given schedule. Schedules returned
+// by Every must have a positive interval.
+func (s *Scheduler) AddSchedule(name string, schedule Schedule, fn func(ctx context.Context) error, opts ...JobOption) error {
+	if every, ok := schedule.(everySchedule); ok && every <= 0 {
+		return fmt.Errorf("scheduler: interval %s of job %q must be positive", time.Duration(every), name)
+	}
+	j := &job{name: name, schedule: schedule, fn: fn}
+	for _, opt := range opts {
+		opt(j)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[name]; ok {
+		return fmt.Errorf("scheduler: job %q already exists", name)
+	}
+	s.jobs[name] = j
+	if s.started {
+		s.startJob(j)
+	}
+	return nil
+}
+
+// Start starts running the jobs on their schedules. It does not block.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+	for _, j := range s.jobs {
+		s.startJob(j)
+	}
+}
+
+// Stop stops the scheduler, cancels the context of all running runs and waits for them to return.
+// A stopped scheduler cannot be started again.
+func (s *Scheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// Stats returns the statistics of the job with the given name, and whether the job exists.
+func (s *Scheduler) Stats(name string) (JobStats, bool) {
+	s.mu.Lock()
+	j, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return JobStats{}, false
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.stats, true
+}
+
+// startJob starts waiting for the given job to be due. s.mu must be held.
+func (s *Scheduler) startJob(j *job) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.schedule(j)
+	}()
+}
+
+// schedule dispatches the runs of the given job when they are due, until the scheduler is stopped.
+func (s *Scheduler) schedule(j *job) {
+	if j.startJitter > 0 {
+		timer := time.NewTimer(rand.N(j.startJitter))
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+	due := time.Now()
+	for {
+		next := j.schedule.Next(due)
+		if next.IsZero() {
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		due = next
+		done := s.dispatch(j)
+		if j.timing == FixedDelay && done != nil {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-done:
+			}
+			due = time.Now()
+		}
+	}
+}
+
+// dispatch starts, queues or skips a run of the given job, according to its overlap policy. It returns a channel
+// that is closed when the run finishes, or nil if the run was not started.
+func (s *Scheduler) dispatch(j *job) <-chan struct{} {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.running > 0 && j.overlap != OverlapConcurrent {
+		if j.overlap == OverlapQueue && !j.queued {
+			j.queued = true
+		} else {
+			j.stats.Skipped++
+		}
+		return nil
+	}
+	return s.startRun(j)
+}
+
+// startRun starts a run of the given job, and returns a channel that is closed when it finishes. j.mu must be held.
+func (s *Scheduler) startRun(j *job) <-chan struct{} {
+	j.running++
+	j.stats.Runs++
+	j.stats.LastRun = time.Now()
+
+	done := make(chan struct{})
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer close(done)
+		err := j.run(s.ctx)
+
+		j.mu.Lock()
+		defer j.mu.Unlock()
+
+		j.running--
+		j.stats.LastErr = err
+		if err != nil {
+			j.stats.Failures++
+		} else {
+			j.stats.Successes++
+		}
+		if j.queued && s.ctx.Err() == nil {
+			j.queued = false
+			s.startRun(j)
+		}
+	}()
+	return done
+}
+
+type job struct {
+	name     string
+	schedule Schedule
+	fn       func(ctx context.Context) error
+	retrier  Retrier
+	numTimes int
+	policy   *retry.Policy
+	overlap  Overlap
+	timing   Timing
+
+	startJitter time.Duration
+
+	mu      sync.Mutex
+	running int  // The number of runs that are running.
+	queued  bool // Whether a run is queued.
+	stats   JobStats
+}
+
+// run runs the job once, retrying it if it has a policy or a retrier.
+func (j *job) run(ctx context.Context) error {
+	attempt := func(ctx context.Context) error {
+		j.mu.Lock()
+		j.stats.Attempts++
+		j.mu.Unlock()
+		return j.fn(ctx)
+	}
+	if j.policy != nil {
+		return j.policy.Do(ctx, attempt)
+	}
+	if j.retrier == nil {
+		return attempt(ctx)
+	}
+	return j.retrier.RetryCtx(ctx, j.numTimes, func() error {
+		return attempt(ctx)
+	})
+}
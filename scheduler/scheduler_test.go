@@ -0,0 +1,243 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/minitauros/go-retry"
+)
+
+func TestScheduler(t *testing.T) {
+	Convey("Scheduler", t, func() {
+		s := New()
+		defer s.Stop()
+
+		Convey("Runs jobs on their schedule", func() {
+			var numCalled atomic.Int64
+			err := s.Add("job", "@every 10ms", func(ctx context.Context) error {
+				numCalled.Add(1)
+				return nil
+			})
+			So(err, ShouldBeNil)
+			s.Start()
+			time.Sleep(55 * time.Millisecond)
+			s.Stop()
+
+			So(numCalled.Load(), ShouldBeBetweenOrEqual, 3, 5)
+			stats, ok := s.Stats("job")
+			So(ok, ShouldBeTrue)
+			So(stats.Runs, ShouldEqual, numCalled.Load())
+			So(stats.Successes, ShouldEqual, numCalled.Load())
+			So(stats.Attempts, ShouldEqual, numCalled.Load())
+			So(stats.LastErr, ShouldBeNil)
+		})
+
+		Convey("Retries runs using the retrier of the job", func() {
+			var numCalled atomic.Int64
+			err := s.Add("job", "@every 10ms", func(ctx context.Context) error {
+				if numCalled.Add(1)%2 == 1 {
+					return errors.New("foo")
+				}
+				return nil
+			}, WithRetry(retry.NewBackOffRetrier(0, 1), 3))
+			So(err, ShouldBeNil)
+			s.Start()
+			time.Sleep(25 * time.Millisecond)
+			s.Stop()
+
+			stats, _ := s.Stats("job")
+			So(stats.Runs, ShouldBeGreaterThanOrEqualTo, 1)
+			So(stats.Successes, ShouldEqual, stats.Runs)
+			So(stats.Attempts, ShouldEqual, 2*stats.Runs)
+		})
+
+		Convey("Retries runs according to the policy of the job", func() {
+			var mu sync.Mutex
+			var attempts []int
+			err := s.Add("job", "@every 10ms", func(ctx context.Context) error {
+				attempt, _ := retry.AttemptFromContext(ctx)
+				mu.Lock()
+				defer mu.Unlock()
+				attempts = append(attempts, attempt.Number)
+				if attempt.Number < 2 {
+					return errors.New("foo")
+				}
+				return nil
+			}, WithPolicy(retry.NewPolicy().InitialDelay(0).MaxAttempts(3).Build()))
+			So(err, ShouldBeNil)
+			s.Start()
+			time.Sleep(15 * time.Millisecond)
+			s.Stop()
+
+			stats, _ := s.Stats("job")
+			So(stats.Runs, ShouldEqual, 1)
+			So(stats.Successes, ShouldEqual, 1)
+			So(stats.Attempts, ShouldEqual, 2)
+			So(attempts, ShouldResemble, []int{1, 2})
+		})
+
+		Convey("Delays the start of jobs by the start jitter", func() {
+			start := time.Now()
+			ran := make(chan time.Time, 1)
+			err := s.AddSchedule("job", Every(10*time.Millisecond), func(ctx context.Context) error {
+				select {
+				case ran <- time.Now():
+				default:
+				}
+				return nil
+			}, WithStartJitter(50*time.Millisecond))
+			So(err, ShouldBeNil)
+			s.Start()
+			select {
+			case first := <-ran:
+				So(first.Sub(start), ShouldBeBetween, 10*time.Millisecond, 60*time.Millisecond+50*time.Millisecond)
+			case <-time.After(time.Second):
+				So("job did not run", ShouldBeEmpty)
+			}
+		})
+
+		Convey("Records failed runs", func() {
+			expectedErr := errors.New("foo")
+			err := s.Add("job", "@every 10ms", func(ctx context.Context) error {
+				return expectedErr
+			}, WithRetry(retry.NewBackOffRetrier(0, 1), 1))
+			So(err, ShouldBeNil)
+			s.Start()
+			time.Sleep(15 * time.Millisecond)
+			s.Stop()
+
+			stats, _ := s.Stats("job")
+			So(stats.Failures, ShouldEqual, 1)
+			So(stats.Attempts, ShouldEqual, 2)
+			So(stats.LastErr, ShouldWrap, expectedErr)
+		})
+
+		Convey("Applies the overlap policy", func() {
+			run := func(overlap Overlap) (JobStats, int64) {
+				var maxRunning, running atomic.Int64
+				err := s.Add("job", "@every 10ms", func(ctx context.Context) error {
+					n := running.Add(1)
+					defer running.Add(-1)
+					if n > maxRunning.Load() {
+						maxRunning.Store(n)
+					}
+					time.Sleep(35 * time.Millisecond)
+					return nil
+				}, WithOverlap(overlap))
+				So(err, ShouldBeNil)
+				s.Start()
+				time.Sleep(55 * time.Millisecond)
+				s.Stop()
+				stats, _ := s.Stats("job")
+				return stats, maxRunning.Load()
+			}
+
+			Convey("Skip", func() {
+				stats, maxRunning := run(OverlapSkip)
+				So(maxRunning, ShouldEqual, 1)
+				So(stats.Runs, ShouldEqual, 2)
+				So(stats.Skipped, ShouldBeGreaterThanOrEqualTo, 2)
+			})
+
+			Convey("Queue", func() {
+				stats, maxRunning := run(OverlapQueue)
+				So(maxRunning, ShouldEqual, 1)
+				So(stats.Runs, ShouldEqual, 2)
+				So(stats.Skipped, ShouldBeGreaterThanOrEqualTo, 1)
+			})
+
+			Convey("Concurrent", func() {
+				stats, maxRunning := run(OverlapConcurrent)
+				So(maxRunning, ShouldBeGreaterThan, 1)
+				So(stats.Runs, ShouldBeGreaterThanOrEqualTo, 4)
+				So(stats.Skipped, ShouldEqual, 0)
+			})
+		})
+
+		Convey("Applies the timing", func() {
+			run := func(timing Timing) []time.Time {
+				var mu sync.Mutex
+				var starts []time.Time
+				err := s.AddSchedule("job", Every(20*time.Millisecond), func(ctx context.Context) error {
+					mu.Lock()
+					starts = append(starts, time.Now())
+					mu.Unlock()
+					time.Sleep(20 * time.Millisecond)
+					return nil
+				}, WithTiming(timing), WithOverlap(OverlapConcurrent))
+				So(err, ShouldBeNil)
+				s.Start()
+				time.Sleep(110 * time.Millisecond)
+				s.Stop()
+				mu.Lock()
+				defer mu.Unlock()
+				return starts
+			}
+
+			Convey("FixedRate runs relative to when the previous run was due", func() {
+				starts := run(FixedRate)
+				So(len(starts), ShouldBeBetweenOrEqual, 4, 5)
+				So(starts[len(starts)-1].Sub(starts[0]), ShouldBeLessThan, time.Duration(len(starts)-1)*35*time.Millisecond)
+			})
+
+			Convey("FixedDelay runs relative to when the previous run finished", func() {
+				starts := run(FixedDelay)
+				So(len(starts), ShouldBeBetweenOrEqual, 2, 3)
+				for i := 1; i < len(starts); i++ {
+					So(starts[i].Sub(starts[i-1]), ShouldBeGreaterThanOrEqualTo, 40*time.Millisecond)
+				}
+			})
+		})
+
+		Convey("Returns an error for duplicate jobs and invalid specs", func() {
+			So(s.Add("job", "@hourly", nil), ShouldBeNil)
+			So(s.Add("job", "@hourly", nil), ShouldNotBeNil)
+			So(s.Add("other", "foo", nil), ShouldNotBeNil)
+			So(s.AddSchedule("other", Every(0), nil), ShouldNotBeNil)
+			So(s.AddSchedule("other", Every(-time.Second), nil), ShouldNotBeNil)
+		})
+
+		Convey("Returns false for stats of unknown jobs", func() {
+			_, ok := s.Stats("foo")
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
+
+func TestRunEvery(t *testing.T) {
+	Convey("RunEvery()", t, func() {
+		Convey("Runs the function at the interval until the context is done", func() {
+			type key struct{}
+			ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), key{}, "value"), 35*time.Millisecond)
+			defer cancel()
+			var numCalled atomic.Int64
+			var value atomic.Value
+			err := RunEvery(ctx, 10*time.Millisecond, func(ctx context.Context) error {
+				value.Store(ctx.Value(key{}))
+				if numCalled.Add(1) == 1 {
+					return errors.New("foo")
+				}
+				return nil
+			}, WithPolicy(retry.NewPolicy().InitialDelay(0).MaxAttempts(2).Build()))
+			So(err, ShouldEqual, context.DeadlineExceeded)
+			So(numCalled.Load(), ShouldBeBetweenOrEqual, 3, 5)
+			So(value.Load(), ShouldEqual, "value")
+		})
+
+		Convey("Returns an error for non-positive intervals", func() {
+			var numCalled int
+			err := RunEvery(context.Background(), 0, func(ctx context.Context) error {
+				numCalled++
+				return nil
+			})
+			So(err, ShouldNotBeNil)
+			So(numCalled, ShouldEqual, 0)
+		})
+	})
+}
@@ -0,0 +1,25 @@
+package retry
+
+import (
+	"context"
+)
+
+// Semaphore limits concurrency. It is implemented by *semaphore.Weighted of golang.org/x/sync/semaphore.
+type Semaphore interface {
+	// Acquire acquires the given weight, blocking until it is available or the given context is done, in which case
+	// it returns the context error.
+	Acquire(ctx context.Context, n int64) error
+	// Release releases the given weight.
+	Release(n int64)
+}
+
+// WithSemaphore makes every attempt acquire the given weight of the given semaphore before running, and release it
+// as soon as it is done. Sleeping between attempts doesn't hold any capacity. Sharing the semaphore between all
+// callers of a downstream makes retries respect its maximum number of connections, instead of competing with first
+// attempts for more.
+func WithSemaphore(sem Semaphore, weight int64) Option {
+	return func(cfg *config) {
+		cfg.sem = sem
+		cfg.semWeight = weight
+	}
+}
@@ -0,0 +1,116 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// chanSemaphore is a semaphore of weight 1 per slot, backed by a buffered channel.
+type chanSemaphore chan struct{}
+
+func (s chanSemaphore) Acquire(ctx context.Context, n int64) error {
+	for i := int64(0); i < n; i++ {
+		select {
+		case s <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (s chanSemaphore) Release(n int64) {
+	for i := int64(0); i < n; i++ {
+		<-s
+	}
+}
+
+func TestWithSemaphore(t *testing.T) {
+	Convey("WithSemaphore()", t, func() {
+		sem := make(chanSemaphore, 2)
+
+		Convey("Limits the number of concurrent attempts", func() {
+			retrier := NewConstantDelayRetrier(5*time.Millisecond, WithSemaphore(sem, 1))
+			var running, maxRunning atomic.Int64
+			var wg sync.WaitGroup
+			for i := 0; i < 5; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					var numCalled int
+					_ = retrier.Retry(3, func() error {
+						n := running.Add(1)
+						defer running.Add(-1)
+						for {
+							m := maxRunning.Load()
+							if n <= m || maxRunning.CompareAndSwap(m, n) {
+								break
+							}
+						}
+						time.Sleep(time.Millisecond)
+						numCalled++
+						if numCalled < 3 {
+							return errors.New("foo")
+						}
+						return nil
+					})
+				}()
+			}
+			wg.Wait()
+			So(maxRunning.Load(), ShouldBeBetweenOrEqual, 1, 2)
+			So(len(sem), ShouldEqual, 0)
+		})
+
+		Convey("Does not hold capacity while sleeping", func() {
+			sem := make(chanSemaphore, 1)
+			retrier := NewConstantDelayRetrier(time.Hour, WithSemaphore(sem, 1))
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			failed := make(chan struct{})
+			go func() {
+				_ = retrier.RetryCtx(ctx, 1, func() error {
+					close(failed)
+					return errors.New("foo")
+				})
+			}()
+			<-failed
+
+			ctx2, cancel2 := context.WithTimeout(context.Background(), time.Second)
+			defer cancel2()
+			err := retrier.RetryCtx(ctx2, 1, func() error {
+				return nil
+			})
+			So(err, ShouldBeNil)
+		})
+
+		Convey("Releases capacity if the callback panics", func() {
+			retrier := NewNoDelayRetrier(WithSemaphore(sem, 2))
+			So(func() {
+				_ = retrier.Retry(1, func() error {
+					panic("foo")
+				})
+			}, ShouldPanicWith, "foo")
+			So(len(sem), ShouldEqual, 0)
+		})
+
+		Convey("Returns the context error if the context is done while waiting for capacity", func() {
+			sem <- struct{}{}
+			sem <- struct{}{}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			var numCalled int
+			err := NewNoDelayRetrier(WithSemaphore(sem, 1)).RetryCtx(ctx, 3, func() error {
+				numCalled++
+				return nil
+			})
+			So(err, ShouldEqual, context.DeadlineExceeded)
+			So(numCalled, ShouldEqual, 0)
+		})
+	})
+}
@@ -0,0 +1,107 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Session is a full record of a retry loop, returned by RetrySession, for attaching to e.g. incident reports and
+// support tickets.
+type Session struct {
+	// Operation is the name of the operation, as set using WithName.
+	Operation string
+	// Start is when the retry loop started.
+	Start time.Time
+	// End is when the retry loop ended.
+	End time.Time
+	// Attempts contains a record of every attempt, in order. For retry loops that retry forever, only the most recent
+	// attempts are kept. The Fields of the attempts are only recorded if WithAttemptHistory is used.
+	Attempts []AttemptRecord
+	// Sleeps contains a record of every sleep between attempts, in order. For retry loops that retry forever, only the
+	// most recent sleeps are kept.
+	Sleeps []SleepRecord
+	// Err is the error returned by the retry loop.
+	Err error
+}
+
+// SleepRecord describes a sleep between attempts.
+type SleepRecord struct {
+	// Time is the time the sleep started.
+	Time time.Time
+	// Planned is the time the retrier planned to sleep for.
+	Planned time.Duration
+	// Duration is the time the retrier actually slept for, including waiting for a pacer. It is shorter than planned
+	// if the context was done during the sleep.
+	Duration time.Duration
+}
+
+// String returns a human-readable report of the session.
+func (s *Session) String() string {
+	var b strings.Builder
+	operation := s.Operation
+	if operation == "" {
+		operation = "retry session"
+	}
+	outcome := "succeeded"
+	if s.Err != nil {
+		outcome = fmt.Sprintf("failed: %s", s.Err)
+	}
+	fmt.Fprintf(&b, "%s from %s to %s (%s), %d attempts, %s\n", operation, s.Start.Format(time.RFC3339Nano), s.End.Format(time.RFC3339Nano), s.End.Sub(s.Start), len(s.Attempts), outcome)
+	sleeps := s.Sleeps
+	for i, attempt := range s.Attempts {
+		result := "ok"
+		if attempt.Err != nil {
+			result = attempt.Err.Error()
+		}
+		fmt.Fprintf(&b, "  attempt %d at +%s took %s: %s\n", attempt.Attempt, attempt.Offset, attempt.Duration, result)
+		// Attempts that are retried right away aren't followed by a sleep.
+		if len(sleeps) > 0 && (i == len(s.Attempts)-1 || sleeps[0].Time.Before(s.Attempts[i+1].Time)) {
+			fmt.Fprintf(&b, "  slept %s of %s\n", sleeps[0].Duration, sleeps[0].Planned)
+			sleeps = sleeps[1:]
+		}
+	}
+	return b.String()
+}
+
+type sessionKey struct{}
+
+// sessionFromContext returns the session to record in the given context, or nil if there is none.
+func sessionFromContext(ctx context.Context) *Session {
+	session, _ := ctx.Value(sessionKey{}).(*Session)
+	return session
+}
+
+// recordAttempt records the given attempt. It does nothing on a nil session.
+func (s *Session) recordAttempt(record AttemptRecord, forever bool) {
+	if s == nil {
+		return
+	}
+	if forever && len(s.Attempts) == maxForeverErrors {
+		s.Attempts = s.Attempts[1:]
+	}
+	s.Attempts = append(s.Attempts, record)
+}
+
+// recordSleep records the given sleep. It does nothing on a nil session.
+func (s *Session) recordSleep(record SleepRecord, forever bool) {
+	if s == nil {
+		return
+	}
+	if forever && len(s.Sleeps) == maxForeverErrors {
+		s.Sleeps = s.Sleeps[1:]
+	}
+	s.Sleeps = append(s.Sleeps, record)
+}
+
+// RetrySession retries the given callback at max the given number of times using the given retryer, and returns a
+// full record of the retry loop alongside its error: when it started and ended, and every attempt and sleep. It stops
+// as soon as a `nil` error is returned. Retryers that don't build on the retriers of this package only record the
+// error.
+func RetrySession(ctx context.Context, r Retryer, numTimes int, cb func() error) (*Session, error) {
+	session := &Session{}
+	err := r.RetryCtx(context.WithValue(ctx, sessionKey{}, session), numTimes, cb)
+	session.Err = err
+	return session, err
+}
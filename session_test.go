@@ -0,0 +1,70 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRetrySession(t *testing.T) {
+	Convey("RetrySession()", t, func() {
+		ctx := context.Background()
+		start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		clock := &fakeClock{now: start}
+		retrier := NewBackOffRetrier(time.Second, 2, WithClock(clock), WithName("op"))
+		expectedErr := errors.New("foo")
+
+		Convey("Records every attempt and sleep of a retry loop", func() {
+			var numCalled int
+			session, err := RetrySession(ctx, retrier, 5, func() error {
+				numCalled++
+				if numCalled < 3 {
+					return expectedErr
+				}
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(session.Operation, ShouldEqual, "op")
+			So(session.Start, ShouldEqual, start)
+			So(session.End, ShouldEqual, start.Add(3*time.Second))
+			So(session.Err, ShouldBeNil)
+			So(session.Attempts, ShouldResemble, []AttemptRecord{
+				{Attempt: 1, Time: start, Err: expectedErr},
+				{Attempt: 2, Time: start.Add(time.Second), Offset: time.Second, Err: expectedErr},
+				{Attempt: 3, Time: start.Add(3 * time.Second), Offset: 3 * time.Second},
+			})
+			So(session.Sleeps, ShouldResemble, []SleepRecord{
+				{Time: start, Planned: time.Second, Duration: time.Second},
+				{Time: start.Add(time.Second), Planned: 2 * time.Second, Duration: 2 * time.Second},
+			})
+		})
+
+		Convey("Returns the error alongside the session", func() {
+			session, err := RetrySession(ctx, NewBackOffRetrier(time.Second, 2, WithClock(clock), WithWarmUp(1)), 2, func() error {
+				return expectedErr
+			})
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(session.Err, ShouldEqual, err)
+			So(session.Attempts, ShouldHaveLength, 3)
+			So(session.Sleeps, ShouldHaveLength, 1)
+
+			Convey("String() returns a report of the session", func() {
+				So(session.String(), ShouldEqual, "retry session from 2024-01-02T03:04:05Z to 2024-01-02T03:04:06Z (1s), 3 attempts, failed: max retries exceeded: foo\n"+
+					"  attempt 1 at +0s took 0s: foo\n"+
+					"  attempt 2 at +0s took 0s: foo\n"+
+					"  slept 1s of 1s\n"+
+					"  attempt 3 at +1s took 0s: foo\n")
+			})
+		})
+
+		Convey("Does not record the sessions of other retry loops", func() {
+			session, _ := RetrySession(ctx, retrier, 0, func() error {
+				return retrier.Retry(1, func() error { return nil })
+			})
+			So(session.Attempts, ShouldHaveLength, 1)
+		})
+	})
+}
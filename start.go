@@ -0,0 +1,59 @@
+package retry
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Handle is a handle to a retry loop running in the background, as started by Start.
+type Handle struct {
+	cancel      context.CancelFunc
+	done        chan struct{}
+	numAttempts atomic.Int64
+	err         error // Set before done is closed.
+}
+
+// Start retries the given callback according to the given policy in a new goroutine, and returns a handle to it right
+// away, so that the caller can continue and check in later. It stops as soon as a `nil` error is returned. Every
+// attempt receives a child context of the given context that carries the attempt, which can be retrieved using
+// AttemptFromContext.
+func Start(ctx context.Context, policy Policy, cb func(ctx context.Context) error) *Handle {
+	ctx, cancel := context.WithCancel(ctx)
+	h := &Handle{cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer close(h.done)
+		defer cancel()
+		h.err = policy.Do(ctx, func(ctx context.Context) error {
+			h.numAttempts.Add(1)
+			return cb(ctx)
+		})
+	}()
+	return h
+}
+
+// Done returns a channel that is closed once the retry loop ended.
+func (h *Handle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Err returns the error the retry loop ended with, which is nil if it succeeded. It returns nil as long as the loop
+// did not end yet.
+func (h *Handle) Err() error {
+	select {
+	case <-h.done:
+		return h.err
+	default:
+		return nil
+	}
+}
+
+// Attempts returns the number of attempts that were started so far.
+func (h *Handle) Attempts() int {
+	return int(h.numAttempts.Load())
+}
+
+// Cancel cancels the context of the retry loop, which makes it end with context.Canceled, unless it ended already.
+// It does not wait for the loop to end: use Done for that.
+func (h *Handle) Cancel() {
+	h.cancel()
+}
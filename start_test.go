@@ -0,0 +1,75 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestStart(t *testing.T) {
+	Convey("Start()", t, func() {
+		policy := NewPolicy().InitialDelay(0).MaxAttempts(3).Build()
+		expectedErr := errors.New("foo")
+
+		Convey("Retries the callback in the background", func() {
+			proceed := make(chan struct{})
+			var numCalled int
+			handle := Start(context.Background(), policy, func(ctx context.Context) error {
+				numCalled++
+				if numCalled == 1 {
+					<-proceed
+					return expectedErr
+				}
+				return nil
+			})
+			So(handle.Err(), ShouldBeNil)
+			select {
+			case <-handle.Done():
+				So("done before the first attempt returned", ShouldBeEmpty)
+			default:
+			}
+
+			close(proceed)
+			<-handle.Done()
+			So(handle.Err(), ShouldBeNil)
+			So(handle.Attempts(), ShouldEqual, 2)
+		})
+
+		Convey("Err() returns the error the loop ended with", func() {
+			handle := Start(context.Background(), policy, func(ctx context.Context) error {
+				return expectedErr
+			})
+			<-handle.Done()
+			So(handle.Err(), ShouldWrap, ErrMaxRetriesExceeded)
+			So(handle.Err(), ShouldWrap, expectedErr)
+			So(handle.Attempts(), ShouldEqual, 3)
+		})
+
+		Convey("Cancel() cancels the loop", func() {
+			policy := NewPolicy().InitialDelay(time.Hour).MaxAttempts(3).Build()
+			handle := Start(context.Background(), policy, func(ctx context.Context) error {
+				return expectedErr
+			})
+			handle.Cancel()
+			select {
+			case <-handle.Done():
+			case <-time.After(time.Second):
+				So("not cancelled", ShouldBeEmpty)
+			}
+			So(handle.Err(), ShouldEqual, context.Canceled)
+		})
+
+		Convey("Passes the attempt in the context", func() {
+			var attempt Attempt
+			handle := Start(context.Background(), policy, func(ctx context.Context) error {
+				attempt, _ = AttemptFromContext(ctx)
+				return nil
+			})
+			<-handle.Done()
+			So(attempt.Number, ShouldEqual, 1)
+		})
+	})
+}
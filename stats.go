@@ -0,0 +1,101 @@
+package retry
+
+import (
+	"maps"
+	"sync"
+	"time"
+)
+
+// RetrierStats contains the cumulative statistics of a retrier.
+type RetrierStats struct {
+	// Attempts is the number of attempts made, including retries.
+	Attempts int
+	// Retries is the number of attempts that were retries.
+	Retries int
+	// Retrying is the number of retry loops that are currently retrying, i.e. that have made at least one retry and
+	// haven't ended yet.
+	Retrying int
+	// Successes is the number of retry loops that ended without an error.
+	Successes int
+	// Failures is the number of retry loops that ended with an error.
+	Failures int
+	// TotalSlept is the total time slept between attempts.
+	TotalSlept time.Duration
+	// ErrorClasses is the number of failed attempts per error class, if WithErrorClasses or WithErrorLabels is used.
+	ErrorClasses map[string]int
+}
+
+// retrierStats keeps the statistics of a retrier. It is safe for concurrent use.
+type retrierStats struct {
+	mu    sync.Mutex
+	stats RetrierStats
+}
+
+// recordRetry records that a retry loop is about to make a retry, which is its first one if numAttempts is 1. It does
+// nothing on nil stats.
+func (s *retrierStats) recordRetry(numAttempts int) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.Retries++
+	if numAttempts == 1 {
+		s.stats.Retrying++
+	}
+}
+
+// recordError records that an attempt failed with an error of the given class. It does nothing on nil stats.
+func (s *retrierStats) recordError(class string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stats.ErrorClasses == nil {
+		s.stats.ErrorClasses = make(map[string]int)
+	}
+	s.stats.ErrorClasses[class]++
+}
+
+// record records the outcome of a retry loop. It does nothing on nil stats.
+func (s *retrierStats) record(numAttempts int, slept time.Duration, err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.Attempts += numAttempts
+	if numAttempts > 1 {
+		s.stats.Retrying--
+	}
+	s.stats.TotalSlept += slept
+	if err == nil {
+		s.stats.Successes++
+	} else {
+		s.stats.Failures++
+	}
+}
+
+// get returns the statistics. It returns zero statistics on nil stats.
+func (s *retrierStats) get() RetrierStats {
+	if s == nil {
+		return RetrierStats{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := s.stats
+	stats.ErrorClasses = maps.Clone(stats.ErrorClasses)
+	return stats
+}
+
+// reset resets the statistics to zero, except for the number of retry loops that are currently retrying. It does
+// nothing on nil stats.
+func (s *retrierStats) reset() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats = RetrierStats{Retrying: s.stats.Retrying}
+}
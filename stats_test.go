@@ -0,0 +1,93 @@
+package retry
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestStats(t *testing.T) {
+	Convey("Stats()", t, func() {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		expectedErr := errors.New("foo")
+		var numCalled int
+		flaky := func() error {
+			numCalled++
+			if numCalled%2 == 1 {
+				return expectedErr
+			}
+			return nil
+		}
+
+		Convey("Returns the cumulative statistics of a back off retrier", func() {
+			retrier := NewBackOffRetrier(time.Second, 2, WithClock(clock))
+			So(retrier.Stats(), ShouldResemble, RetrierStats{})
+
+			So(retrier.Retry(3, flaky), ShouldBeNil)
+			So(retrier.Retry(0, flaky), ShouldNotBeNil)
+			So(retrier.Stats(), ShouldResemble, RetrierStats{Attempts: 3, Retries: 1, Successes: 1, Failures: 1, TotalSlept: time.Second})
+
+			Convey("ResetStats() resets them", func() {
+				retrier.ResetStats()
+				So(retrier.Stats(), ShouldResemble, RetrierStats{})
+			})
+		})
+
+		Convey("Counts the retry loops that are currently retrying", func() {
+			retrier := NewNoDelayRetrier()
+			var during []RetrierStats
+			var numCalled int
+			_ = retrier.Retry(2, func() error {
+				numCalled++
+				during = append(during, retrier.Stats())
+				return expectedErr
+			})
+			So(during[0].Retrying, ShouldEqual, 0)
+			So(during[1].Retrying, ShouldEqual, 1)
+			So(during[2].Retrying, ShouldEqual, 1)
+			So(retrier.Stats().Retrying, ShouldEqual, 0)
+
+			So(retrier.Retry(1, func() error {
+				retrier.ResetStats()
+				return nil
+			}), ShouldBeNil)
+			So(retrier.Stats().Retrying, ShouldEqual, 0)
+		})
+
+		Convey("Returns the statistics of strategy and constant delay retriers", func() {
+			strategyRetrier := NewStrategyRetrier(ConstantBackoff(time.Second), WithClock(clock))
+			So(strategyRetrier.Retry(3, flaky), ShouldBeNil)
+			So(strategyRetrier.Stats(), ShouldResemble, RetrierStats{Attempts: 2, Retries: 1, Successes: 1, TotalSlept: time.Second})
+			strategyRetrier.ResetStats()
+			So(strategyRetrier.Stats(), ShouldResemble, RetrierStats{})
+
+			constantRetrier := NewConstantDelayRetrier(2*time.Second, WithClock(clock))
+			So(constantRetrier.Retry(3, flaky), ShouldBeNil)
+			So(constantRetrier.Stats(), ShouldResemble, RetrierStats{Attempts: 2, Retries: 1, Successes: 1, TotalSlept: 2 * time.Second})
+			constantRetrier.ResetStats()
+			So(constantRetrier.Stats(), ShouldResemble, RetrierStats{})
+		})
+
+		Convey("Is safe for concurrent use", func() {
+			retrier := NewNoDelayRetrier()
+			var wg sync.WaitGroup
+			for i := 0; i < 50; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_ = retrier.Retry(1, func() error { return expectedErr })
+					_ = retrier.Stats()
+				}()
+			}
+			wg.Wait()
+			stats := retrier.Stats()
+			So(stats.Attempts, ShouldEqual, 100)
+			So(stats.Retries, ShouldEqual, 50)
+			So(stats.Failures, ShouldEqual, 50)
+			So(stats.Retrying, ShouldEqual, 0)
+		})
+	})
+}
@@ -0,0 +1,67 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrStopped is the cause of the context of an attempt that was cancelled because `stop` was called.
+var ErrStopped = errors.New("retry loop stopped")
+
+type stopSignalKey struct{}
+
+// stopSignal cancels the contexts passed to the attempts of a retry loop as soon as the loop decides to stop, so that
+// long-running attempts can abort cooperatively.
+type stopSignal struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+}
+
+// withStopSignal returns a context to pass to a retry loop, and the stop signal of the loop, of which the context is
+// to be passed to its attempts.
+func withStopSignal(ctx context.Context) (context.Context, *stopSignal) {
+	attemptCtx, cancel := context.WithCancelCause(ctx)
+	signal := &stopSignal{ctx: attemptCtx, cancel: cancel}
+	return context.WithValue(ctx, stopSignalKey{}, signal), signal
+}
+
+// stopSignalFromContext returns the stop signal in the given context, or nil if there is none.
+func stopSignalFromContext(ctx context.Context) *stopSignal {
+	signal, _ := ctx.Value(stopSignalKey{}).(*stopSignal)
+	return signal
+}
+
+// stop cancels the contexts of the attempts with the given cause. It does nothing on a nil signal.
+func (s *stopSignal) stop(cause error) {
+	if s == nil {
+		return
+	}
+	s.cancel(cause)
+}
+
+// stopAfter cancels the contexts of the attempts with the given cause once the given duration has passed, unless the
+// returned function is called before. It does nothing on a nil signal.
+func (s *stopSignal) stopAfter(d time.Duration, cause error) (cancel func()) {
+	if s == nil {
+		return func() {}
+	}
+	timer := time.AfterFunc(d, func() {
+		s.cancel(cause)
+	})
+	return func() {
+		timer.Stop()
+	}
+}
+
+// DoWithStop retries the given callback at max the given number of times using the given retryer.
+// It stops only when `stop` is called. Every attempt receives a child context of the given context that carries the
+// attempt, which can be retrieved using AttemptFromContext. The context is cancelled as soon as the loop decides to
+// stop, so that long-running attempts can abort cooperatively instead of running to completion: when `stop` is called,
+// e.g. by another goroutine, with ErrStopped as its cause, and when the maximum elapsed time set using
+// WithMaxElapsedTime passes, with ErrMaxElapsedTimeExceeded as its cause, unless a clock is set using WithClock.
+func DoWithStop(ctx context.Context, r Retryer, numTimes int, cb func(ctx context.Context, stop func()) error) error {
+	loopCtx, signal := withStopSignal(ctx)
+	defer signal.stop(context.Canceled)
+	return r.RetryWithStopCtx(loopCtx, numTimes, withAttemptContextStop(signal.ctx, cb))
+}
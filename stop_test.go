@@ -0,0 +1,96 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDoWithStop(t *testing.T) {
+	Convey("DoWithStop()", t, func() {
+		retrier := NewNoDelayRetrier()
+		var numCalled int
+
+		Convey("Stops only when stop is called", func() {
+			var numbers []int
+			err := DoWithStop(context.Background(), retrier, 5, func(ctx context.Context, stop func()) error {
+				numCalled++
+				attempt, _ := AttemptFromContext(ctx)
+				numbers = append(numbers, attempt.Number)
+				if numCalled == 3 {
+					stop()
+				}
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(numbers, ShouldResemble, []int{1, 2, 3})
+		})
+
+		Convey("Cancels the context of the attempt as soon as stop is called", func() {
+			err := DoWithStop(context.Background(), retrier, 5, func(ctx context.Context, stop func()) error {
+				numCalled++
+				go stop()
+				<-ctx.Done()
+				return context.Cause(ctx)
+			})
+			So(err, ShouldEqual, ErrStopped)
+			So(numCalled, ShouldEqual, 1)
+		})
+
+		Convey("Policy.DoWithStop() stops only when stop is called", func() {
+			policy := NewPolicy().InitialDelay(0).MaxAttempts(5).Build()
+			err := policy.DoWithStop(context.Background(), func(ctx context.Context, stop func()) error {
+				numCalled++
+				if numCalled == 2 {
+					stop()
+				}
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(numCalled, ShouldEqual, 2)
+		})
+	})
+}
+
+func TestDo_cancellation(t *testing.T) {
+	Convey("Do()", t, func() {
+		var numCalled int
+
+		Convey("Cancels the context of the attempt once the maximum elapsed time passes", func() {
+			retrier := NewConstantDelayRetrier(time.Millisecond, WithMaxElapsedTime(20*time.Millisecond))
+			err := Do(context.Background(), retrier, 5, func(ctx context.Context) error {
+				numCalled++
+				<-ctx.Done()
+				return context.Cause(ctx)
+			})
+			So(err, ShouldWrap, ErrMaxElapsedTimeExceeded)
+			So(numCalled, ShouldEqual, 1)
+		})
+
+		Convey("Does not cancel the context of the attempt otherwise", func() {
+			retrier := NewNoDelayRetrier(WithMaxElapsedTime(time.Hour))
+			var ctxErrs []error
+			err := Do(context.Background(), retrier, 2, func(ctx context.Context) error {
+				ctxErrs = append(ctxErrs, ctx.Err())
+				return errors.New("foo")
+			})
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(ctxErrs, ShouldResemble, []error{nil, nil, nil})
+		})
+
+		Convey("Does not pass the stop signal to nested retry loops", func() {
+			inner := NewNoDelayRetrier()
+			err := DoWithStop(context.Background(), NewNoDelayRetrier(), 0, func(ctx context.Context, stop func()) error {
+				So(stopSignalFromContext(ctx), ShouldBeNil)
+				return inner.RetryWithStopCtx(ctx, 0, func(innerStop func()) error {
+					innerStop()
+					return nil
+				})
+			})
+			So(err, ShouldBeNil)
+		})
+	})
+}
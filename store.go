@@ -0,0 +1,229 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StoredJob is a durable job of a Queue, as persisted in a Store.
+type StoredJob struct {
+	// ID identifies the job.
+	ID string
+	// Name is the name the job was enqueued with.
+	Name string
+	// Payload is the payload the job was enqueued with, which the handler of the queue performs the job for.
+	Payload []byte
+	// NumAttempts is the number of attempts that were made for the job.
+	NumAttempts int
+	// NextRun is the time at which the next attempt is due.
+	NextRun time.Time
+}
+
+// Store persists the durable jobs of a Queue, so that pending retries survive process restarts. Implementations must
+// be safe for concurrent use.
+type Store interface {
+	// Save creates or updates the given job.
+	Save(ctx context.Context, job StoredJob) error
+	// Delete deletes the job with the given ID. Deleting a job that doesn't exist is not an error.
+	Delete(ctx context.Context, id string) error
+	// List returns all jobs.
+	List(ctx context.Context) ([]StoredJob, error)
+}
+
+// MemoryStore is a Store that keeps the jobs in memory, e.g. for tests, or to let the jobs of a queue survive
+// restarting the queue, but not the process.
+type MemoryStore struct {
+	mu   sync.Mutex
+	jobs map[string]StoredJob
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore returns a new, empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{jobs: make(map[string]StoredJob)}
+}
+
+// Save implements Store.
+func (s *MemoryStore) Save(_ context.Context, job StoredJob) error {
+	job.Payload = slices.Clone(job.Payload)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = job
+	return nil
+}
+
+// Delete implements Store.
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.jobs, id)
+	return nil
+}
+
+// List implements Store. It returns the jobs ordered by their next run time, then by ID.
+func (s *MemoryStore) List(_ context.Context) ([]StoredJob, error) {
+	s.mu.Lock()
+	jobs := slices.Collect(maps.Values(s.jobs))
+	s.mu.Unlock()
+	for i := range jobs {
+		jobs[i].Payload = slices.Clone(jobs[i].Payload)
+	}
+	slices.SortFunc(jobs, func(a, b StoredJob) int {
+		if c := a.NextRun.Compare(b.NextRun); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return jobs, nil
+}
+
+// ErrNoStore is returned when enqueueing a durable job to, or resuming the jobs of, a queue without a store.
+var ErrNoStore = errors.New("queue has no store")
+
+// WithStore makes the queue persist its durable jobs in the given store. Durable jobs are retried according to the
+// given policy, by calling the given handler with the job, which carries the payload it was enqueued with. The
+// attempt count and next run time of a job are saved before every back off, and the job is deleted once it ended,
+// except when the queue is stopped: then it's kept, so that Resume picks it up again, e.g. after a restart. The
+// maximum number of attempts of the policy covers the attempts of all runs, but the back off restarts at the initial
+// delay on every run.
+func WithStore(store Store, policy Policy, handler func(ctx context.Context, job StoredJob) error) QueueOption {
+	return func(q *Queue) {
+		q.store = store
+		q.storePolicy = policy
+		q.storeHandler = handler
+	}
+}
+
+// EnqueueDurable saves a new durable job with the given name and payload to the store of the queue, and starts it. It
+// returns ErrQueueStopped if the queue was stopped or is draining, ErrNoStore if the queue has no store, or the error
+// of the store if the job could not be saved.
+func (q *Queue) EnqueueDurable(ctx context.Context, name string, payload []byte) error {
+	if q.store == nil {
+		return ErrNoStore
+	}
+	job := StoredJob{ID: uuidString(newUUID()), Name: name, Payload: payload, NextRun: time.Now()}
+	// Register the job before saving it, so that a concurrent Resume doesn't start it too.
+	if _, err := q.beginDurable(job.ID); err != nil {
+		return err
+	}
+	if err := q.store.Save(ctx, job); err != nil {
+		q.endDurable(job.ID)
+		return err
+	}
+	go q.runDurable(job)
+	return nil
+}
+
+// Resume starts the jobs in the store of the queue that are not running, e.g. those that were kept when the queue
+// was stopped, or that were left behind by a process that crashed. Jobs are started right away, and wait for their
+// next run time in the background. It returns ErrQueueStopped if the queue was stopped or is draining, ErrNoStore if
+// the queue has no store, or the error of the store if the jobs could not be listed.
+func (q *Queue) Resume(ctx context.Context) error {
+	if q.store == nil {
+		return ErrNoStore
+	}
+	jobs, err := q.store.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		started, err := q.beginDurable(job.ID)
+		if err != nil {
+			return err
+		}
+		if started {
+			go q.runDurable(job)
+		}
+	}
+	return nil
+}
+
+// beginDurable registers a new durable job with the given ID, like begin does for other jobs. It returns false if
+// the job is running already, and ErrQueueStopped if the queue is not accepting jobs.
+func (q *Queue) beginDurable(id string) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.running[id] {
+		return false, nil
+	}
+	if !q.beginLocked() {
+		return false, ErrQueueStopped
+	}
+	q.running[id] = true
+	return true, nil
+}
+
+// endDurable unregisters a durable job that ended.
+func (q *Queue) endDurable(id string) {
+	q.mu.Lock()
+	delete(q.running, id)
+	q.mu.Unlock()
+	q.end()
+}
+
+// runDurable runs a durable job that was registered using beginDurable, keeping the store up to date.
+func (q *Queue) runDurable(job StoredJob) {
+	defer q.endDurable(job.ID)
+
+	storeCtx := context.WithoutCancel(q.ctx)
+	var storeErrs []error
+	saved := true // Whether the store holds the current state of the job.
+	policy := q.storePolicy.With(WithLease(q.acquireWorker), func(cfg *config) {
+		cfg.beforeSleep = func(_ int, sleeping time.Duration) {
+			job.NextRun = time.Now().Add(sleeping)
+			if err := q.store.Save(storeCtx, job); err != nil {
+				storeErrs = append(storeErrs, err)
+				return
+			}
+			saved = true
+		}
+	})
+	if maxAttempts := q.storePolicy.MaxAttempts(); maxAttempts > 0 {
+		policy = policy.With(WithMaxAttempts(max(maxAttempts-job.NumAttempts, 1)))
+	}
+
+	err := waitUntil(q.ctx, job.NextRun)
+	if err == nil {
+		err = policy.Do(q.ctx, func(ctx context.Context) error {
+			job.NumAttempts++
+			saved = false
+			return q.storeHandler(ctx, job)
+		})
+	}
+
+	if err != nil && q.ctx.Err() != nil {
+		// The queue was stopped, so keep the job for Resume.
+		if !saved {
+			job.NextRun = time.Now()
+			storeErrs = append(storeErrs, q.store.Save(storeCtx, job))
+		}
+	} else {
+		storeErrs = append(storeErrs, q.store.Delete(storeCtx, job.ID))
+	}
+	if q.onDone != nil {
+		q.onDone(JobResult{Name: job.Name, NumAttempts: job.NumAttempts, Err: errors.Join(append([]error{err}, storeErrs...)...)})
+	}
+}
+
+// waitUntil waits until the given time, or until the given context is done, in which case it returns the cause of
+// the context.
+func waitUntil(ctx context.Context, t time.Time) error {
+	d := time.Until(t)
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
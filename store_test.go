@@ -0,0 +1,171 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMemoryStore(t *testing.T) {
+	Convey("MemoryStore", t, func() {
+		ctx := context.Background()
+		store := NewMemoryStore()
+		now := time.Now()
+		payload := []byte("payload")
+		So(store.Save(ctx, StoredJob{ID: "b", NextRun: now}), ShouldBeNil)
+		So(store.Save(ctx, StoredJob{ID: "a", NextRun: now.Add(time.Second), Payload: payload}), ShouldBeNil)
+		So(store.Save(ctx, StoredJob{ID: "c", NextRun: now}), ShouldBeNil)
+		payload[0] = 'P'
+
+		Convey("Lists the jobs by their next run time", func() {
+			jobs, err := store.List(ctx)
+			So(err, ShouldBeNil)
+			So(jobs, ShouldHaveLength, 3)
+			So([]string{jobs[0].ID, jobs[1].ID, jobs[2].ID}, ShouldResemble, []string{"b", "c", "a"})
+			So(string(jobs[2].Payload), ShouldEqual, "payload")
+		})
+
+		Convey("Updates and deletes jobs", func() {
+			So(store.Save(ctx, StoredJob{ID: "a", NumAttempts: 2}), ShouldBeNil)
+			So(store.Delete(ctx, "b"), ShouldBeNil)
+			So(store.Delete(ctx, "unknown"), ShouldBeNil)
+			jobs, err := store.List(ctx)
+			So(err, ShouldBeNil)
+			So(jobs, ShouldHaveLength, 2)
+			So(jobs[0].ID, ShouldEqual, "a")
+			So(jobs[0].NumAttempts, ShouldEqual, 2)
+		})
+	})
+}
+
+func TestQueue_Durable(t *testing.T) {
+	Convey("Durable jobs", t, func() {
+		ctx := context.Background()
+		expectedErr := errors.New("foo")
+		store := NewMemoryStore()
+		var mu sync.Mutex
+		var results []JobResult
+		var handled []StoredJob
+		onDone := WithOnJobDone(func(result JobResult) {
+			mu.Lock()
+			defer mu.Unlock()
+			results = append(results, result)
+		})
+		handler := func(failUntil int) func(ctx context.Context, job StoredJob) error {
+			return func(ctx context.Context, job StoredJob) error {
+				mu.Lock()
+				defer mu.Unlock()
+				handled = append(handled, job)
+				if job.NumAttempts < failUntil {
+					return expectedErr
+				}
+				return nil
+			}
+		}
+
+		Convey("Are retried with their payload and deleted once they ended", func() {
+			policy := NewPolicy().InitialDelay(0).MaxAttempts(3).Build()
+			queue := NewQueue(1, onDone, WithStore(store, policy, handler(2)))
+			So(queue.EnqueueDurable(ctx, "job", []byte("payload")), ShouldBeNil)
+			So(queue.Drain(ctx), ShouldBeNil)
+
+			So(results, ShouldResemble, []JobResult{{Name: "job", NumAttempts: 2}})
+			So(handled, ShouldHaveLength, 2)
+			So(string(handled[1].Payload), ShouldEqual, "payload")
+			So(handled[1].NumAttempts, ShouldEqual, 2)
+			jobs, err := store.List(ctx)
+			So(err, ShouldBeNil)
+			So(jobs, ShouldBeEmpty)
+		})
+
+		Convey("Are kept when the queue is stopped, and resumed by another queue", func() {
+			slow := NewPolicy().InitialDelay(time.Hour).MaxAttempts(3).Build()
+			queue := NewQueue(1, onDone, WithStore(store, slow, handler(10)))
+			start := time.Now()
+			So(queue.EnqueueDurable(ctx, "job", []byte("payload")), ShouldBeNil)
+			So(waitForStoredAttempts(store, 1), ShouldBeTrue)
+			queue.Stop()
+
+			jobs, err := store.List(ctx)
+			So(err, ShouldBeNil)
+			So(jobs, ShouldHaveLength, 1)
+			So(jobs[0].Name, ShouldEqual, "job")
+			So(jobs[0].NumAttempts, ShouldEqual, 1)
+			So(jobs[0].NextRun, ShouldHappenOnOrAfter, start.Add(time.Hour))
+
+			// Make the job due, and resume it with a policy that doesn't back off.
+			jobs[0].NextRun = time.Now()
+			So(store.Save(ctx, jobs[0]), ShouldBeNil)
+			quick := NewPolicy().InitialDelay(0).MaxAttempts(3).Build()
+			resumed := NewQueue(1, onDone, WithStore(store, quick, handler(10)))
+			So(resumed.Resume(ctx), ShouldBeNil)
+			So(resumed.Drain(ctx), ShouldBeNil)
+
+			So(results, ShouldHaveLength, 2)
+			So(results[1].NumAttempts, ShouldEqual, 3)
+			So(results[1].Err, ShouldWrap, expectedErr)
+			So(handled, ShouldHaveLength, 3)
+			jobs, err = store.List(ctx)
+			So(err, ShouldBeNil)
+			So(jobs, ShouldBeEmpty)
+		})
+
+		Convey("Are not resumed while they are running", func() {
+			slow := NewPolicy().InitialDelay(time.Hour).MaxAttempts(2).Build()
+			queue := NewQueue(1, onDone, WithStore(store, slow, handler(10)))
+			So(queue.EnqueueDurable(ctx, "job", nil), ShouldBeNil)
+			So(waitForStoredAttempts(store, 1), ShouldBeTrue)
+			So(queue.Resume(ctx), ShouldBeNil)
+			So(queue.Len(), ShouldEqual, 1)
+			queue.Stop()
+		})
+
+		Convey("Are started once by concurrent resumes", func() {
+			So(store.Save(ctx, StoredJob{ID: "id", Name: "job", NextRun: time.Now().Add(time.Hour)}), ShouldBeNil)
+			queue := NewQueue(1, onDone, WithStore(store, NewPolicy().Build(), handler(0)))
+			var wg sync.WaitGroup
+			errs := make([]error, 10)
+			for i := range errs {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					errs[i] = queue.Resume(ctx)
+				}()
+			}
+			wg.Wait()
+			So(errs, ShouldResemble, make([]error, 10))
+			So(queue.Len(), ShouldEqual, 1)
+			queue.Stop()
+		})
+
+		Convey("Require a store", func() {
+			queue := NewQueue(1)
+			So(queue.EnqueueDurable(ctx, "job", nil), ShouldEqual, ErrNoStore)
+			So(queue.Resume(ctx), ShouldEqual, ErrNoStore)
+		})
+
+		Convey("Are not accepted by a stopped queue", func() {
+			queue := NewQueue(1, WithStore(store, NewPolicy().Build(), handler(0)))
+			queue.Stop()
+			So(queue.EnqueueDurable(ctx, "job", nil), ShouldEqual, ErrQueueStopped)
+			jobs, err := store.List(ctx)
+			So(err, ShouldBeNil)
+			So(jobs, ShouldBeEmpty)
+		})
+	})
+}
+
+// waitForStoredAttempts waits for the only job in the given store to have the given number of attempts saved.
+func waitForStoredAttempts(store *MemoryStore, numAttempts int) bool {
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(time.Millisecond) {
+		jobs, _ := store.List(context.Background())
+		if len(jobs) == 1 && jobs[0].NumAttempts == numAttempts {
+			return true
+		}
+	}
+	return false
+}
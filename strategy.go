@@ -0,0 +1,195 @@
+package retry
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// BackoffStrategy decides how long to sleep before a retry.
+// Implementations must be safe for concurrent use, as a strategy is shared by all retry loops of a retrier.
+type BackoffStrategy interface {
+	// Delay returns the delay before the given retry, counting from 1, given the delay before the previous retry,
+	// which is 0 before the first retry.
+	Delay(retry int, prev time.Duration) time.Duration
+}
+
+// BackoffFunc is a function that implements BackoffStrategy.
+type BackoffFunc func(retry int, prev time.Duration) time.Duration
+
+// Delay calls f(retry, prev).
+func (f BackoffFunc) Delay(retry int, prev time.Duration) time.Duration {
+	return f(retry, prev)
+}
+
+// NoBackoff is a strategy that retries without delay.
+var NoBackoff BackoffStrategy = ConstantBackoff(0)
+
+// ConstantBackoff returns a strategy that always sleeps for the given delay.
+func ConstantBackoff(delay time.Duration) BackoffStrategy {
+	return BackoffFunc(func(int, time.Duration) time.Duration {
+		return delay
+	})
+}
+
+// ExponentialBackoff returns a strategy that sleeps for the initial delay before the first retry, and multiplies the
+// delay by the given multiplier before every next retry, like BackOffRetrier.
+func ExponentialBackoff(initialDelay time.Duration, multiplier float64) BackoffStrategy {
+	return BackoffFunc(func(_ int, prev time.Duration) time.Duration {
+		return exponentialDelay(initialDelay, multiplier, prev)
+	})
+}
+
+// exponentialDelay returns the delay after the given previous delay, multiplied by the given multiplier, or the
+// initial delay if there was no previous delay. The delay saturates at the maximum duration instead of overflowing,
+// so that it plateaus at the max delay (if any) however many retries are made.
+func exponentialDelay(initialDelay time.Duration, multiplier float64, prev time.Duration) time.Duration {
+	if prev == 0 {
+		return initialDelay
+	}
+	delay := math.Round(multiplier * float64(prev))
+	if delay >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(delay)
+}
+
+// Phase is a phase of a composite backoff.
+type Phase struct {
+	// Retries is the number of retries the phase lasts. 0 means the phase lasts forever, which only makes sense for
+	// the last phase.
+	Retries int
+	// Strategy is the strategy of the phase. It receives the retry number and the previous delay relative to the
+	// start of the phase, so it starts from scratch, e.g. at its initial delay.
+	Strategy BackoffStrategy
+}
+
+// CompositeBackoff returns a strategy that chains the strategies of the given phases, e.g. 3 immediate retries, then 5
+// exponential retries, then retries every 30 seconds. Once all phases are over, the strategy of the last phase keeps
+// being used.
+func CompositeBackoff(phases ...Phase) BackoffStrategy {
+	return BackoffFunc(func(retry int, prev time.Duration) time.Duration {
+		if len(phases) == 0 {
+			return 0
+		}
+		start := 0 // The number of retries before the phase.
+		for i, phase := range phases {
+			if i == len(phases)-1 || phase.Retries <= 0 || retry <= start+phase.Retries {
+				if retry == start+1 {
+					prev = 0
+				}
+				return phase.Strategy.Delay(retry-start, prev)
+			}
+			start += phase.Retries
+		}
+		panic("unreachable")
+	})
+}
+
+// PolynomialBackoff returns a strategy that sleeps for initialDelay * retry^exponent before every retry, counting
+// retries from 1. An exponent of 1 grows the delays linearly, 2 quadratically, etc., which fills the gap between
+// linear and exponential growth. The delay saturates at the maximum duration instead of overflowing.
+func PolynomialBackoff(initialDelay time.Duration, exponent float64) BackoffStrategy {
+	return BackoffFunc(func(retry int, _ time.Duration) time.Duration {
+		delay := float64(initialDelay) * math.Pow(float64(retry), exponent)
+		if delay >= math.MaxInt64 {
+			return math.MaxInt64
+		}
+		return time.Duration(delay)
+	})
+}
+
+// DecorrelatedJitter returns a strategy that sleeps for a random delay between the initial delay and 3 times the
+// previous delay, capped at the given maximum delay (0 means no maximum), as described in
+// https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/. Under contention, it spreads retries
+// better than exponential backoff with jitter, while still backing off.
+func DecorrelatedJitter(initialDelay, maxDelay time.Duration) BackoffStrategy {
+	return BackoffFunc(func(_ int, prev time.Duration) time.Duration {
+		upper := time.Duration(math.MaxInt64)
+		if prev < math.MaxInt64/3 {
+			upper = 3 * prev
+		}
+		delay := initialDelay
+		if upper > initialDelay {
+			delay += rand.N(upper - initialDelay + 1)
+		}
+		if maxDelay > 0 && delay > maxDelay {
+			return maxDelay
+		}
+		return delay
+	})
+}
+
+// StrategyRetrier retries a given callback, sleeping for the delays returned by a backoff strategy.
+type StrategyRetrier struct {
+	strategy BackoffStrategy
+	cfg      config
+}
+
+// NewStrategyRetrier returns a new retrier that uses the given backoff strategy.
+func NewStrategyRetrier(strategy BackoffStrategy, opts ...Option) *StrategyRetrier {
+	return &StrategyRetrier{strategy: strategy, cfg: newConfig(opts)}
+}
+
+// NewNoDelayRetrier returns a new retrier that retries without delay.
+func NewNoDelayRetrier(opts ...Option) *StrategyRetrier {
+	return NewStrategyRetrier(NoBackoff, opts...)
+}
+
+// Retry retries the given callback at max the given number of times.
+// It stops as soon as a `nil` error is returned.
+func (r *StrategyRetrier) Retry(numTimes int, cb func() error) error {
+	return r.RetryCtx(context.Background(), numTimes, cb)
+}
+
+// RetryCtx retries the given callback at max the given number of times.
+// It stops as soon as a `nil` error is returned.
+func (r *StrategyRetrier) RetryCtx(ctx context.Context, numTimes int, cb func() error) error {
+	return retryLoop(ctx, &r.cfg, numTimes, false, r.strategy, func(func()) error {
+		return cb()
+	})
+}
+
+// RetryForever retries the given callback until a `nil` error is returned or the given context is done.
+func (r *StrategyRetrier) RetryForever(ctx context.Context, cb func() error) error {
+	return r.RetryCtx(ctx, Forever, cb)
+}
+
+// RetryWithStop retries the given callback at max the given number of times.
+// It stops only when `stop` is called.
+func (r *StrategyRetrier) RetryWithStop(numTimes int, cb func(stop func()) error) error {
+	return r.RetryWithStopCtx(context.Background(), numTimes, cb)
+}
+
+// RetryWithStopCtx retries the given callback at max the given number of times.
+// It stops only when `stop` is called.
+func (r *StrategyRetrier) RetryWithStopCtx(ctx context.Context, numTimes int, cb func(stop func()) error) error {
+	return retryLoop(ctx, &r.cfg, numTimes, true, r.strategy, cb)
+}
+
+// RetryWithAbort retries the given callback at max the given number of times.
+// It stops as soon as a `nil` error is returned, or when `abort` is called, in which case the error passed to `abort`
+// is returned.
+func (r *StrategyRetrier) RetryWithAbort(numTimes int, cb func(abort func(err error)) error) error {
+	return r.RetryWithAbortCtx(context.Background(), numTimes, cb)
+}
+
+// RetryWithAbortCtx retries the given callback at max the given number of times.
+// It stops as soon as a `nil` error is returned, or when `abort` is called, in which case the error passed to `abort`
+// is returned.
+func (r *StrategyRetrier) RetryWithAbortCtx(ctx context.Context, numTimes int, cb func(abort func(err error)) error) error {
+	return retryLoop(ctx, &r.cfg, numTimes, false, r.strategy, withAbort(cb))
+}
+
+// Stats returns the cumulative statistics of the retrier since it was created or its statistics were last reset.
+// It is safe for concurrent use.
+func (r *StrategyRetrier) Stats() RetrierStats {
+	return r.cfg.stats.get()
+}
+
+// ResetStats resets the statistics of the retrier to zero, except for the number of retry loops that are currently
+// retrying.
+func (r *StrategyRetrier) ResetStats() {
+	r.cfg.stats.reset()
+}
@@ -0,0 +1,225 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestConstantBackoff(t *testing.T) {
+	Convey("ConstantBackoff()", t, func() {
+		Convey("Always returns the given delay", func() {
+			strategy := ConstantBackoff(time.Second)
+			So(strategy.Delay(1, 0), ShouldEqual, time.Second)
+			So(strategy.Delay(5, time.Second), ShouldEqual, time.Second)
+		})
+
+		Convey("NoBackoff always returns 0", func() {
+			So(NoBackoff.Delay(1, 0), ShouldEqual, 0)
+			So(NoBackoff.Delay(5, 0), ShouldEqual, 0)
+		})
+	})
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	Convey("ExponentialBackoff()", t, func() {
+		strategy := ExponentialBackoff(time.Second, 2)
+		So(strategy.Delay(1, 0), ShouldEqual, time.Second)
+		So(strategy.Delay(2, time.Second), ShouldEqual, 2*time.Second)
+		So(strategy.Delay(3, 2*time.Second), ShouldEqual, 4*time.Second)
+		So(strategy.Delay(4, math.MaxInt64), ShouldEqual, time.Duration(math.MaxInt64))
+	})
+}
+
+func TestCompositeBackoff(t *testing.T) {
+	Convey("CompositeBackoff()", t, func() {
+		delays := func(strategy BackoffStrategy, n int) []time.Duration {
+			var delays []time.Duration
+			var prev time.Duration
+			for retry := 1; retry <= n; retry++ {
+				prev = strategy.Delay(retry, prev)
+				delays = append(delays, prev)
+			}
+			return delays
+		}
+
+		Convey("Chains the strategies of the phases", func() {
+			strategy := CompositeBackoff(
+				Phase{Retries: 2, Strategy: NoBackoff},
+				Phase{Retries: 3, Strategy: ExponentialBackoff(time.Second, 2)},
+				Phase{Strategy: ConstantBackoff(30 * time.Second)},
+			)
+			So(delays(strategy, 8), ShouldResemble, []time.Duration{
+				0, 0,
+				time.Second, 2 * time.Second, 4 * time.Second,
+				30 * time.Second, 30 * time.Second, 30 * time.Second,
+			})
+		})
+
+		Convey("Passes the retry number relative to the start of the phase", func() {
+			var retries []int
+			recording := BackoffFunc(func(retry int, _ time.Duration) time.Duration {
+				retries = append(retries, retry)
+				return 0
+			})
+			delays(CompositeBackoff(Phase{Retries: 2, Strategy: recording}, Phase{Retries: 2, Strategy: recording}), 6)
+			So(retries, ShouldResemble, []int{1, 2, 1, 2, 3, 4})
+		})
+
+		Convey("Keeps using the last phase once all phases are over", func() {
+			strategy := CompositeBackoff(Phase{Retries: 1, Strategy: NoBackoff}, Phase{Retries: 1, Strategy: ExponentialBackoff(time.Second, 2)})
+			So(delays(strategy, 4), ShouldResemble, []time.Duration{0, time.Second, 2 * time.Second, 4 * time.Second})
+		})
+
+		Convey("Without phases, does not sleep", func() {
+			So(CompositeBackoff().Delay(1, 0), ShouldEqual, 0)
+		})
+	})
+}
+
+func TestPolynomialBackoff(t *testing.T) {
+	Convey("PolynomialBackoff()", t, func() {
+		Convey("Returns initialDelay * retry^exponent", func() {
+			strategy := PolynomialBackoff(time.Second, 2)
+			So(strategy.Delay(1, 0), ShouldEqual, time.Second)
+			So(strategy.Delay(2, time.Second), ShouldEqual, 4*time.Second)
+			So(strategy.Delay(3, 4*time.Second), ShouldEqual, 9*time.Second)
+		})
+
+		Convey("With an exponent of 1, grows linearly", func() {
+			strategy := PolynomialBackoff(time.Second, 1)
+			So(strategy.Delay(5, 0), ShouldEqual, 5*time.Second)
+		})
+
+		Convey("Saturates instead of overflowing", func() {
+			So(PolynomialBackoff(time.Second, 10).Delay(1000, 0), ShouldEqual, time.Duration(math.MaxInt64))
+		})
+	})
+}
+
+func TestDecorrelatedJitter(t *testing.T) {
+	Convey("DecorrelatedJitter()", t, func() {
+		strategy := DecorrelatedJitter(time.Second, time.Minute)
+
+		Convey("Starts at the initial delay", func() {
+			So(strategy.Delay(1, 0), ShouldEqual, time.Second)
+		})
+
+		Convey("Returns a random delay between the initial delay and 3 times the previous delay", func() {
+			seen := map[bool]bool{}
+			for i := 0; i < 1000; i++ {
+				delay := strategy.Delay(2, 10*time.Second)
+				So(delay, ShouldBeBetweenOrEqual, time.Second, 30*time.Second)
+				seen[delay < 15*time.Second] = true
+			}
+			So(seen, ShouldHaveLength, 2)
+		})
+
+		Convey("Caps the delay at the max delay", func() {
+			var delay time.Duration
+			for i := 1; i <= 100; i++ {
+				delay = strategy.Delay(i, delay)
+				So(delay, ShouldBeBetweenOrEqual, time.Second, time.Minute)
+			}
+		})
+
+		Convey("Does not overflow without a max delay", func() {
+			strategy := DecorrelatedJitter(time.Second, 0)
+			So(strategy.Delay(2, math.MaxInt64/2), ShouldBeGreaterThanOrEqualTo, time.Second)
+		})
+	})
+}
+
+func Test_StrategyRetrier(t *testing.T) {
+	Convey("*StrategyRetrier", t, func() {
+		type call struct {
+			retry int
+			prev  time.Duration
+		}
+		var calls []call
+		retrier := NewStrategyRetrier(BackoffFunc(func(retry int, prev time.Duration) time.Duration {
+			calls = append(calls, call{retry: retry, prev: prev})
+			return time.Duration(retry) * time.Millisecond
+		}))
+		var numCalled int
+
+		Convey("Retry() sleeps for the delays returned by the strategy", func() {
+			startTime := time.Now()
+			err := retrier.Retry(10, func() error {
+				numCalled++
+				if numCalled == 4 {
+					return nil
+				}
+				return errors.New("foo")
+			})
+			So(err, ShouldBeNil)
+			So(numCalled, ShouldEqual, 4)
+			So(calls, ShouldResemble, []call{{1, 0}, {2, time.Millisecond}, {3, 2 * time.Millisecond}})
+			So(time.Since(startTime), ShouldBeGreaterThanOrEqualTo, 6*time.Millisecond)
+		})
+
+		Convey("RetryCtx() returns the context error", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			err := retrier.RetryCtx(ctx, 10, func() error {
+				numCalled++
+				return nil
+			})
+			So(err, ShouldEqual, context.Canceled)
+			So(numCalled, ShouldEqual, 0)
+		})
+
+		Convey("RetryWithStop() stops only when stop is called", func() {
+			err := retrier.RetryWithStop(10, func(stop func()) error {
+				numCalled++
+				if numCalled == 3 {
+					stop()
+				}
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(numCalled, ShouldEqual, 3)
+			So(calls, ShouldBeEmpty) // Returning nil does not trigger sleep.
+		})
+
+		Convey("RetryWithAbort() stops when abort is called and returns the given error", func() {
+			expectedErr := errors.New("foo")
+			err := retrier.RetryWithAbort(10, func(abort func(err error)) error {
+				numCalled++
+				if numCalled == 2 {
+					abort(expectedErr)
+				}
+				return errors.New("bar")
+			})
+			So(err, ShouldEqual, expectedErr)
+			So(numCalled, ShouldEqual, 2)
+			So(calls, ShouldResemble, []call{{1, 0}})
+		})
+
+		Convey("If the maximum number of tries is reached, returns err", func() {
+			expectedErr := errors.New("foo")
+			err := retrier.RetryWithStopCtx(context.Background(), 1, func(stop func()) error {
+				numCalled++
+				return expectedErr
+			})
+			So(err, ShouldWrap, expectedErr)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(numCalled, ShouldEqual, 2)
+		})
+
+		Convey("NewNoDelayRetrier() retries without delay", func() {
+			startTime := time.Now()
+			err := NewNoDelayRetrier().Retry(100, func() error {
+				numCalled++
+				return errors.New("foo")
+			})
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(numCalled, ShouldEqual, 101)
+			So(time.Since(startTime), ShouldBeLessThan, 50*time.Millisecond)
+		})
+	})
+}
@@ -0,0 +1,51 @@
+package retry
+
+import "time"
+
+// SuspendEvent describes a retry loop waking up from a sleep much later than planned, e.g. because the machine was
+// suspended, the VM was paused or the process was starved of CPU.
+type SuspendEvent struct {
+	// Operation is the name of the operation, as set using WithName.
+	Operation string
+	// NumAttempts is the number of attempts that were made before the sleep.
+	NumAttempts int
+	// Planned is the duration the loop planned to sleep for.
+	Planned time.Duration
+	// Slept is the duration the loop actually slept for.
+	Slept time.Duration
+	// Forgiven reports whether the gap between the two is not counted against the maximum elapsed time.
+	Forgiven bool
+}
+
+// Gap returns the time the loop slept for longer than planned.
+func (e SuspendEvent) Gap() time.Duration {
+	return e.Slept - e.Planned
+}
+
+// WithSuspendDetection makes the retrier detect sleeps that take more than the given threshold longer than planned,
+// which happens when the machine is suspended, the VM is paused or the process is starved of CPU, and call the given
+// hook for each of them. The hook may be nil. If forgive is true, the gaps are not counted against the maximum elapsed
+// time set using WithMaxElapsedTime, so that a laptop waking up from sleep does not instantly declare the budget
+// exhausted. Deadlines of the context are never forgiven.
+func WithSuspendDetection(threshold time.Duration, forgive bool, hook func(SuspendEvent)) Option {
+	return func(cfg *config) {
+		cfg.suspendThreshold = threshold
+		cfg.forgiveSuspend = forgive
+		cfg.onSuspend = hook
+	}
+}
+
+// detectSuspend checks whether a sleep that was planned to take the given duration, but actually took the given slept
+// duration, was interrupted by a suspension, and returns the time to forgive for it.
+func (cfg *config) detectSuspend(numAttempts int, planned, slept time.Duration) time.Duration {
+	if cfg.suspendThreshold <= 0 || slept-planned <= cfg.suspendThreshold {
+		return 0
+	}
+	if cfg.onSuspend != nil {
+		cfg.onSuspend(SuspendEvent{Operation: cfg.name, NumAttempts: numAttempts, Planned: planned, Slept: slept, Forgiven: cfg.forgiveSuspend})
+	}
+	if !cfg.forgiveSuspend {
+		return 0
+	}
+	return slept - planned
+}
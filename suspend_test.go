@@ -0,0 +1,68 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// suspendingClock is a fake clock that oversleeps by the given gaps, one per sleep.
+type suspendingClock struct {
+	fakeClock
+	gaps []time.Duration
+}
+
+func (c *suspendingClock) Sleep(ctx context.Context, d time.Duration) error {
+	if len(c.gaps) > 0 {
+		c.now = c.now.Add(c.gaps[0])
+		c.gaps = c.gaps[1:]
+	}
+	return c.fakeClock.Sleep(ctx, d)
+}
+
+func TestWithSuspendDetection(t *testing.T) {
+	Convey("WithSuspendDetection()", t, func() {
+		clock := &suspendingClock{fakeClock: fakeClock{now: time.Unix(0, 0)}, gaps: []time.Duration{time.Millisecond, time.Hour}}
+		expectedErr := errors.New("foo")
+		var events []SuspendEvent
+		hook := func(event SuspendEvent) {
+			events = append(events, event)
+		}
+		var numCalled int
+		cb := func() error {
+			numCalled++
+			return expectedErr
+		}
+
+		Convey("Reports sleeps that take longer than planned by more than the threshold", func() {
+			retrier := NewConstantDelayRetrier(time.Second, WithClock(clock), WithName("op"), WithSuspendDetection(time.Second, false, hook))
+			_ = retrier.Retry(2, cb)
+			So(events, ShouldResemble, []SuspendEvent{{Operation: "op", NumAttempts: 2, Planned: time.Second, Slept: time.Hour + time.Second}})
+			So(events[0].Gap(), ShouldEqual, time.Hour)
+		})
+
+		Convey("Without forgiving, counts the gaps against the maximum elapsed time", func() {
+			retrier := NewConstantDelayRetrier(time.Second, WithClock(clock), WithMaxElapsedTime(time.Minute), WithSuspendDetection(time.Second, false, hook))
+			err := retrier.Retry(5, cb)
+			So(err, ShouldWrap, ErrMaxElapsedTimeExceeded)
+			So(numCalled, ShouldEqual, 3)
+		})
+
+		Convey("With forgiving, does not count the gaps against the maximum elapsed time", func() {
+			retrier := NewConstantDelayRetrier(time.Second, WithClock(clock), WithMaxElapsedTime(time.Minute), WithSuspendDetection(time.Second, true, hook))
+			err := retrier.Retry(5, cb)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(numCalled, ShouldEqual, 6)
+			So(events, ShouldHaveLength, 1)
+			So(events[0].Forgiven, ShouldBeTrue)
+		})
+
+		Convey("Accepts a nil hook", func() {
+			retrier := NewConstantDelayRetrier(time.Second, WithClock(clock), WithSuspendDetection(time.Second, true, nil))
+			So(retrier.Retry(2, cb), ShouldWrap, expectedErr)
+		})
+	})
+}
@@ -0,0 +1,39 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrMaxTargetsExceeded is returned by RetryTargets when the maximum number of targets was tried without success. It
+// wraps the errors of all targets that were tried.
+var ErrMaxTargetsExceeded = errors.New("max targets exceeded")
+
+// RetryTargets tries the given targets, like the replicas or endpoints of a service, in order, until the callback
+// succeeds for one of them, and returns that target. It tries at max the given number of distinct targets, and retries
+// every target at max the given number of times using the given retryer, so that limits can be set in terms of the
+// number of machines bothered, next to the number of attempts per machine. If maxTargets is less than 1, all targets
+// are tried.
+//
+// When a target fails, the next one is tried right away, whatever way the retryer gave up. If the context is done, its
+// cause is returned. If no target succeeds, the zero value is returned with an error matching ErrMaxTargetsExceeded,
+// which wraps the errors of the retryer for every target that was tried.
+func RetryTargets[T any](ctx context.Context, r Retryer, targets []T, maxTargets, numTimesPerTarget int, cb func(ctx context.Context, target T) error) (T, error) {
+	var zero T
+	if maxTargets < 1 || maxTargets > len(targets) {
+		maxTargets = len(targets)
+	}
+	i, errs, err := tryInOrder(ctx, maxTargets, func(i int) error {
+		return r.RetryCtx(ctx, numTimesPerTarget, func() error {
+			return cb(ctx, targets[i])
+		})
+	}, nil)
+	if err != nil {
+		return zero, err
+	}
+	if i >= 0 {
+		return targets[i], nil
+	}
+	return zero, fmt.Errorf("%w: %w", ErrMaxTargetsExceeded, errors.Join(errs...))
+}
@@ -0,0 +1,63 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRetryTargets(t *testing.T) {
+	Convey("RetryTargets()", t, func() {
+		ctx := context.Background()
+		retrier := NewNoDelayRetrier()
+		replicas := []string{"a", "b", "c", "d"}
+		expectedErr := errors.New("foo")
+		var tried []string
+
+		Convey("Returns the first target that succeeds, retrying every target", func() {
+			target, err := RetryTargets(ctx, retrier, replicas, 3, 1, func(_ context.Context, replica string) error {
+				tried = append(tried, replica)
+				if replica == "c" {
+					return nil
+				}
+				return expectedErr
+			})
+			So(err, ShouldBeNil)
+			So(target, ShouldEqual, "c")
+			So(tried, ShouldResemble, []string{"a", "a", "b", "b", "c"})
+		})
+
+		Convey("Tries at max the given number of targets", func() {
+			target, err := RetryTargets(ctx, retrier, replicas, 2, 1, func(_ context.Context, replica string) error {
+				tried = append(tried, replica)
+				return expectedErr
+			})
+			So(target, ShouldBeEmpty)
+			So(err, ShouldWrap, ErrMaxTargetsExceeded)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(err, ShouldWrap, expectedErr)
+			So(tried, ShouldResemble, []string{"a", "a", "b", "b"})
+		})
+
+		Convey("Tries all targets if the maximum is less than 1", func() {
+			_, _ = RetryTargets(ctx, retrier, replicas, 0, 0, func(_ context.Context, replica string) error {
+				tried = append(tried, replica)
+				return expectedErr
+			})
+			So(tried, ShouldResemble, replicas)
+		})
+
+		Convey("Returns the cause of the context if it is done", func() {
+			ctx, cancel := context.WithCancel(ctx)
+			_, err := RetryTargets(ctx, retrier, replicas, 0, 5, func(_ context.Context, replica string) error {
+				tried = append(tried, replica)
+				cancel()
+				return expectedErr
+			})
+			So(err, ShouldEqual, context.Canceled)
+			So(tried, ShouldResemble, []string{"a"})
+		})
+	})
+}
@@ -0,0 +1,85 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrNotRewindable is returned by RetryUpload when the source of the payload can't be rewound for a retry.
+var ErrNotRewindable = errors.New("source can't be rewound")
+
+// Rewinder is the source of a payload that can be rewound to its start, so that it can be uploaded again.
+type Rewinder interface {
+	// Rewind rewinds the source to its start, and returns a reader reading the payload from the start.
+	Rewind() (io.Reader, error)
+}
+
+// RewinderFunc is a function that implements Rewinder.
+type RewinderFunc func() (io.Reader, error)
+
+// Rewind implements Rewinder.
+func (f RewinderFunc) Rewind() (io.Reader, error) {
+	return f()
+}
+
+// SeekRewinder returns a rewinder that rewinds the given source by seeking back to the offset it is at when
+// SeekRewinder is called, like that of a file or a bytes.Reader.
+func SeekRewinder(src io.ReadSeeker) (Rewinder, error) {
+	start, err := src.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNotRewindable, err)
+	}
+	return RewinderFunc(func() (io.Reader, error) {
+		if _, err := src.Seek(start, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return src, nil
+	}), nil
+}
+
+// ReopenRewinder returns a rewinder that rewinds by closing the reader that was last opened, and opening the source
+// again using the given function, like os.Open or a function that requests a blob. Call the returned close function
+// to close the reader that was last opened once the upload is done.
+func ReopenRewinder(open func() (io.ReadCloser, error)) (rewinder Rewinder, closeLast func() error) {
+	var last io.ReadCloser
+	closeLast = func() error {
+		if last == nil {
+			return nil
+		}
+		err := last.Close()
+		last = nil
+		return err
+	}
+	return RewinderFunc(func() (io.Reader, error) {
+		if err := closeLast(); err != nil {
+			return nil, err
+		}
+		r, err := open()
+		if err != nil {
+			return nil, err
+		}
+		last = r
+		return r, nil
+	}), closeLast
+}
+
+// RetryUpload retries the given upload at max the given number of times using the given retryer, rewinding the given
+// source before every attempt, including the first, and passing the rewound payload to the upload. It stops as soon as
+// a `nil` error is returned. If the source can't be rewound, it stops retrying and returns an error matching both
+// ErrNotRewindable and the error of the rewinder, instead of uploading a partially consumed payload.
+func RetryUpload(ctx context.Context, r Retryer, numTimes int, src Rewinder, upload func(ctx context.Context, body io.Reader) error) error {
+	return r.RetryWithStopCtx(ctx, numTimes, func(stop func()) error {
+		body, err := src.Rewind()
+		if err != nil {
+			stop()
+			return fmt.Errorf("%w: %w", ErrNotRewindable, err)
+		}
+		if err := upload(ctx, body); err != nil {
+			return err
+		}
+		stop()
+		return nil
+	})
+}
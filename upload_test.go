@@ -0,0 +1,115 @@
+package retry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// closeRecorder is a reader that records whether it was closed.
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+// failingSeeker is a reader that can't seek.
+type failingSeeker struct {
+	io.Reader
+}
+
+func (failingSeeker) Seek(int64, int) (int64, error) {
+	return 0, errors.New("illegal seek")
+}
+
+func TestRetryUpload(t *testing.T) {
+	Convey("RetryUpload()", t, func() {
+		ctx := context.Background()
+		retrier := NewNoDelayRetrier()
+		expectedErr := errors.New("foo")
+		var bodies []string
+		var numCalled int
+		upload := func(_ context.Context, body io.Reader) error {
+			numCalled++
+			// Consume part of the payload, as an upload failing halfway through does.
+			b := make([]byte, 3)
+			n, _ := io.ReadFull(body, b)
+			rest, _ := io.ReadAll(body)
+			bodies = append(bodies, string(b[:n])+string(rest))
+			if numCalled < 3 {
+				return expectedErr
+			}
+			return nil
+		}
+
+		Convey("Rewinds a seekable source before every attempt", func() {
+			src := bytes.NewReader([]byte("xxpayload"))
+			_, _ = src.Seek(2, io.SeekStart)
+			rewinder, err := SeekRewinder(src)
+			So(err, ShouldBeNil)
+
+			err = RetryUpload(ctx, retrier, 5, rewinder, upload)
+			So(err, ShouldBeNil)
+			So(bodies, ShouldResemble, []string{"payload", "payload", "payload"})
+		})
+
+		Convey("Reopens a source before every attempt, closing the previous one", func() {
+			var opened []*closeRecorder
+			rewinder, closeLast := ReopenRewinder(func() (io.ReadCloser, error) {
+				r := &closeRecorder{Reader: strings.NewReader("payload")}
+				opened = append(opened, r)
+				return r, nil
+			})
+
+			err := RetryUpload(ctx, retrier, 5, rewinder, upload)
+			So(err, ShouldBeNil)
+			So(bodies, ShouldResemble, []string{"payload", "payload", "payload"})
+			So(opened, ShouldHaveLength, 3)
+			So(opened[0].closed, ShouldBeTrue)
+			So(opened[1].closed, ShouldBeTrue)
+			So(opened[2].closed, ShouldBeFalse)
+
+			So(closeLast(), ShouldBeNil)
+			So(opened[2].closed, ShouldBeTrue)
+		})
+
+		Convey("Returns the error of the retryer if all attempts fail", func() {
+			rewinder, _ := SeekRewinder(strings.NewReader("payload"))
+			err := RetryUpload(ctx, retrier, 1, rewinder, upload)
+			So(err, ShouldWrap, expectedErr)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(numCalled, ShouldEqual, 2)
+		})
+
+		Convey("Stops retrying if the source can't be rewound", func() {
+			rewindErr := errors.New("gone")
+			var numRewound int
+			rewinder := RewinderFunc(func() (io.Reader, error) {
+				numRewound++
+				if numRewound > 1 {
+					return nil, rewindErr
+				}
+				return strings.NewReader("payload"), nil
+			})
+
+			err := RetryUpload(ctx, retrier, 5, rewinder, upload)
+			So(err, ShouldWrap, ErrNotRewindable)
+			So(err, ShouldWrap, rewindErr)
+			So(numCalled, ShouldEqual, 1)
+		})
+
+		Convey("SeekRewinder() returns ErrNotRewindable if the source can't seek", func() {
+			_, err := SeekRewinder(failingSeeker{strings.NewReader("payload")})
+			So(err, ShouldWrap, ErrNotRewindable)
+		})
+	})
+}
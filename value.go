@@ -0,0 +1,45 @@
+package retry
+
+import (
+	"context"
+)
+
+// ValueOption configures RetryValue.
+type ValueOption[T any] func(*valueConfig[T])
+
+// valueConfig holds the settings of RetryValue that can be changed using value options.
+type valueConfig[T any] struct {
+	reduce func(acc, result T) T
+}
+
+// WithReducer makes RetryValue fold the result of every attempt, including failed ones, into an accumulated result
+// using the given reducer, starting from the zero value, and return the accumulated result instead of the result of
+// the successful attempt. It is returned even if all attempts fail, so that partially successful attempts, like ones
+// that fetched a few pages before failing, aren't discarded.
+func WithReducer[T any](reduce func(acc, result T) T) ValueOption[T] {
+	return func(cfg *valueConfig[T]) {
+		cfg.reduce = reduce
+	}
+}
+
+// RetryValue retries the given callback at max the given number of times using the given retryer, and returns the
+// result of the first successful attempt. It stops as soon as a `nil` error is returned. If no attempt succeeds, the
+// zero value is returned with the error of the retryer.
+func RetryValue[T any](ctx context.Context, r Retryer, numTimes int, cb func() (T, error), opts ...ValueOption[T]) (T, error) {
+	var cfg valueConfig[T]
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	var acc T
+	err := r.RetryCtx(ctx, numTimes, func() error {
+		result, err := cb()
+		switch {
+		case cfg.reduce != nil:
+			acc = cfg.reduce(acc, result)
+		case err == nil:
+			acc = result
+		}
+		return err
+	})
+	return acc, err
+}
@@ -0,0 +1,70 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRetryValue(t *testing.T) {
+	Convey("RetryValue()", t, func() {
+		ctx := context.Background()
+		retrier := NewNoDelayRetrier()
+		expectedErr := errors.New("foo")
+		var numCalled int
+
+		Convey("Returns the result of the first successful attempt", func() {
+			result, err := RetryValue(ctx, retrier, 5, func() (int, error) {
+				numCalled++
+				if numCalled < 3 {
+					return numCalled, expectedErr
+				}
+				return 42, nil
+			})
+			So(err, ShouldBeNil)
+			So(result, ShouldEqual, 42)
+			So(numCalled, ShouldEqual, 3)
+		})
+
+		Convey("If no attempt succeeds, returns the zero value and the error", func() {
+			result, err := RetryValue(ctx, retrier, 2, func() (int, error) {
+				return 1, expectedErr
+			})
+			So(err, ShouldWrap, expectedErr)
+			So(err, ShouldWrap, ErrMaxRetriesExceeded)
+			So(result, ShouldEqual, 0)
+		})
+
+		Convey("WithReducer()", func() {
+			appendPages := WithReducer(func(acc, pages []string) []string {
+				return append(acc, pages...)
+			})
+
+			Convey("Folds the results of all attempts into the returned result", func() {
+				pages, err := RetryValue(ctx, retrier, 5, func() ([]string, error) {
+					numCalled++
+					if numCalled == 1 {
+						return []string{"1", "2"}, expectedErr
+					}
+					return []string{"3"}, nil
+				}, appendPages)
+				So(err, ShouldBeNil)
+				So(pages, ShouldResemble, []string{"1", "2", "3"})
+			})
+
+			Convey("Returns the accumulated result if no attempt succeeds", func() {
+				pages, err := RetryValue(ctx, retrier, 1, func() ([]string, error) {
+					numCalled++
+					if numCalled == 1 {
+						return []string{"1"}, expectedErr
+					}
+					return nil, expectedErr
+				}, appendPages)
+				So(err, ShouldWrap, ErrMaxRetriesExceeded)
+				So(pages, ShouldResemble, []string{"1"})
+			})
+		})
+	})
+}
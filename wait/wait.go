@@ -0,0 +1,87 @@
+// Package wait polls conditions until they are met, e.g. to wait until a resource is ready, using the backoff
+// strategies of the retry package to space the checks.
+package wait
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/minitauros/go-retry"
+)
+
+// ErrTimeout is returned when a condition was not met before the timeout.
+var ErrTimeout = errors.New("timed out waiting for the condition")
+
+// ConditionFunc checks a condition. It returns true once the condition is met, and an error to stop polling right
+// away.
+type ConditionFunc func(ctx context.Context) (done bool, err error)
+
+// Poll checks the given condition every interval, starting after the first interval, until it is met. It returns nil
+// once the condition is met, the error of the condition if it returns one, ErrTimeout if the condition was not met
+// within the given timeout, or the cause of the context if it is done first. A timeout of 0 or less means no timeout.
+// The context passed to the condition is done once the timeout expires.
+func Poll(ctx context.Context, interval, timeout time.Duration, cond ConditionFunc) error {
+	return poll(ctx, retry.ConstantBackoff(interval), timeout, false, cond)
+}
+
+// PollImmediate is like Poll, but checks the condition right away, before waiting for the first interval.
+func PollImmediate(ctx context.Context, interval, timeout time.Duration, cond ConditionFunc) error {
+	return poll(ctx, retry.ConstantBackoff(interval), timeout, true, cond)
+}
+
+// PollBackoff is like PollImmediate, but waits between checks for the delays returned by the given strategy, e.g. to
+// check less and less often using retry.ExponentialBackoff.
+func PollBackoff(ctx context.Context, strategy retry.BackoffStrategy, timeout time.Duration, cond ConditionFunc) error {
+	return poll(ctx, strategy, timeout, true, cond)
+}
+
+// poll checks the given condition until it is met, waiting for the delays returned by the given strategy between
+// checks, and before the first check unless immediate is true.
+func poll(ctx context.Context, strategy retry.BackoffStrategy, timeout time.Duration, immediate bool, cond ConditionFunc) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, ErrTimeout)
+		defer cancel()
+	}
+	var numWaits int
+	var delay time.Duration
+	wait := func() error {
+		numWaits++
+		delay = strategy.Delay(numWaits, delay)
+		return sleep(ctx, delay)
+	}
+	if !immediate {
+		if err := wait(); err != nil {
+			return err
+		}
+	}
+	for {
+		done, err := cond(ctx)
+		switch {
+		case err == nil && done:
+			return nil
+		case ctx.Err() != nil:
+			// The error of the condition, if any, is most likely caused by the context.
+			return context.Cause(ctx)
+		case err != nil:
+			return err
+		}
+		if err := wait(); err != nil {
+			return err
+		}
+	}
+}
+
+// sleep sleeps for the given duration, or until the given context is done, in which case it returns the cause of the
+// context.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
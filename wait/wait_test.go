@@ -0,0 +1,75 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/minitauros/go-retry"
+)
+
+func TestPoll(t *testing.T) {
+	Convey("Poll()", t, func() {
+		ctx := context.Background()
+		var checks []time.Time
+		doneAfter := func(numChecks int) ConditionFunc {
+			return func(ctx context.Context) (bool, error) {
+				checks = append(checks, time.Now())
+				return len(checks) >= numChecks, nil
+			}
+		}
+
+		Convey("Checks the condition every interval until it is met", func() {
+			start := time.Now()
+			So(Poll(ctx, 5*time.Millisecond, time.Second, doneAfter(3)), ShouldBeNil)
+			So(checks, ShouldHaveLength, 3)
+			So(checks[0].Sub(start), ShouldBeGreaterThanOrEqualTo, 5*time.Millisecond)
+			So(checks[2].Sub(checks[1]), ShouldBeGreaterThanOrEqualTo, 5*time.Millisecond)
+		})
+
+		Convey("Returns ErrTimeout if the condition is not met in time", func() {
+			err := Poll(ctx, 5*time.Millisecond, 22*time.Millisecond, doneAfter(100))
+			So(err, ShouldEqual, ErrTimeout)
+			So(len(checks), ShouldBeBetweenOrEqual, 1, 4)
+		})
+
+		Convey("Returns the error of the condition right away", func() {
+			expectedErr := errors.New("foo")
+			err := Poll(ctx, time.Millisecond, 0, func(ctx context.Context) (bool, error) {
+				checks = append(checks, time.Now())
+				return false, expectedErr
+			})
+			So(err, ShouldEqual, expectedErr)
+			So(checks, ShouldHaveLength, 1)
+		})
+
+		Convey("Returns the cause of the context if it is done first", func() {
+			cause := errors.New("cause")
+			ctx, cancel := context.WithCancelCause(ctx)
+			cancel(cause)
+			So(Poll(ctx, time.Millisecond, time.Second, doneAfter(1)), ShouldEqual, cause)
+			So(checks, ShouldBeEmpty)
+		})
+
+		Convey("PollImmediate() checks the condition right away", func() {
+			start := time.Now()
+			So(PollImmediate(ctx, time.Hour, 0, doneAfter(1)), ShouldBeNil)
+			So(time.Since(start), ShouldBeLessThan, time.Second)
+			So(checks, ShouldHaveLength, 1)
+		})
+
+		Convey("PollBackoff() waits between checks according to the strategy", func() {
+			var retries []int
+			strategy := retry.BackoffFunc(func(retry int, prev time.Duration) time.Duration {
+				retries = append(retries, retry)
+				return time.Millisecond
+			})
+			So(PollBackoff(ctx, strategy, time.Second, doneAfter(3)), ShouldBeNil)
+			So(checks, ShouldHaveLength, 3)
+			So(retries, ShouldResemble, []int{1, 2})
+		})
+	})
+}